@@ -0,0 +1,71 @@
+// Команда import загружает брони из CSV (event_id,email,seats) в обход HTTP API.
+// Отчёт в JSON печатается в stdout; код выхода 1, если хотя бы одна строка не импортирована.
+//
+//	go run ./cmd/import -file bookings.csv -mode best_effort -actor 1
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"laschool.ru/event-booking-service/internal/bulkimport"
+	di "laschool.ru/event-booking-service/pkg/container"
+)
+
+func main() {
+	file := flag.String("file", "-", "CSV-файл с бронями, - для stdin")
+	mode := flag.String("mode", string(bulkimport.ModeAllOrNothing), "all_or_nothing или best_effort")
+	actor := flag.Int64("actor", 0, "id администратора для истории статусов броней")
+	complimentary := flag.Bool("complimentary", false, "бесплатные брони: разрешить платные события и неподтверждённые email")
+	flag.Parse()
+
+	if err := run(*file, *mode, *actor, *complimentary); err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		os.Exit(1)
+	}
+}
+
+func run(file, modeFlag string, actorID int64, complimentary bool) error {
+	mode, err := bulkimport.ParseMode(modeFlag)
+	if err != nil {
+		return err
+	}
+	in := io.Reader(os.Stdin)
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ctn, err := di.Instance(nil, nil)
+	if err != nil {
+		return fmt.Errorf("di init: %w", err)
+	}
+	defer ctn.Delete()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := ctn.Get(bulkimport.DIImporter).(*bulkimport.Importer).Import(ctx, in, mode, actorID, complimentary)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+	}
+	return nil
+}
@@ -2,16 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	_ "laschool.ru/event-booking-service/docs"
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/bulkimport"
+	"laschool.ru/event-booking-service/internal/cache"
 	"laschool.ru/event-booking-service/internal/config"
 	"laschool.ru/event-booking-service/internal/db"
+	"laschool.ru/event-booking-service/internal/event"
 	httprouter "laschool.ru/event-booking-service/internal/http"
+	"laschool.ru/event-booking-service/internal/http/handlers"
 	"laschool.ru/event-booking-service/internal/http/middleware"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/payment"
+	"laschool.ru/event-booking-service/internal/promo"
+	"laschool.ru/event-booking-service/internal/ratelimit"
+	"laschool.ru/event-booking-service/internal/session"
+	"laschool.ru/event-booking-service/internal/tracing"
+	"laschool.ru/event-booking-service/internal/user"
 	di "laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -25,31 +46,55 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	fmt.Println("Booking Service started...")
-
 	// загружаем конфиг
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		fatal(slog.Default(), "failed to load config", err)
+	}
+
+	ctn, err := di.Instance(nil, nil)
+	if err != nil {
+		fatal(slog.Default(), "di init failed", err)
 	}
+	log := ctn.Get(logger.DILogger).(*slog.Logger)
+	log.Info("booking service starting")
+	tr := ctn.Get(tracing.DITracing).(*tracing.Tracing)
 
+	database := ctn.Get(db.DIDatabase).(*sqlx.DB)
 	// при необходимости прогоняем миграции (если флаг в конфиге)
 	if cfg.Database.AutoMigrate {
-		ctn, err := di.Instance(nil, nil)
-		if err != nil {
-			log.Fatalf("di init failed: %v", err)
-		}
-		database := ctn.Get(db.DIDatabase).(*sqlx.DB)
-		if err := db.RunMigrations(context.Background(), database, "deploy/migrations"); err != nil {
-			log.Fatalf("migrations failed: %v", err)
+		if err := db.RunMigrations(context.Background(), database, cfg.Database.MigrationsDir); err != nil {
+			fatal(log, "migrations failed", err)
 		}
 	}
+	health := handlers.NewHealthHandler(readinessChecks(log, cfg, database, ctn.Get(cache.DIRedis).(*redis.Client))...)
+
+	// сервисы достаём из контейнера один раз и передаём в хендлеры
+	bookingService := ctn.Get(booking.DIBookingService).(booking.Service)
+	eventService := ctn.Get(event.DIEventService).(event.Service)
+	userService := ctn.Get(user.DIUserService).(user.Service)
+	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
+	tasks := ctn.Get(background.DIBackground).(*background.Group)
+	payments := handlers.NewPaymentHandler(bookingService, ctn.Get(payment.DIProvider).(payment.Provider), cacheService, tasks)
 
 	// маршруты
-	mux := httprouter.NewRouter()
-	// логирование сервера
-	loggingMux := middleware.LoggingMiddleware(mux)
-	muxWithLogAndPanic := middleware.PanicMiddleware(loggingMux)
+	mux := httprouter.NewRouter(httprouter.Handlers{
+		Events:      handlers.NewEventHandler(eventService, cacheService, tasks),
+		Bookings:    handlers.NewBookingHandler(bookingService, eventService, cacheService, tasks),
+		Users:       user.NewHandler(userService),
+		Import:      bulkimport.NewHandler(ctn.Get(bulkimport.DIImporter).(*bulkimport.Importer)),
+		Promos:      handlers.NewPromoHandler(ctn.Get(promo.DIPromoService).(promo.Service)),
+		Payments:    payments,
+		Health:      health,
+		Metrics:     ctn.Get(metrics.DIMetrics).(*metrics.Metrics),
+		RateLimiter: ctn.Get(ratelimit.DIRateLimiter).(*ratelimit.Limiter),
+		Sessions:    ctn.Get(session.DISessions).(*session.Store),
+		JWTSecret:   cfg.JWT.Secret,
+	})
+	// логирование и трассировка сервера
+	loggingMux := middleware.NewLoggingMiddleware(log)(mux)
+	tracedMux := otelhttp.NewHandler(loggingMux, "http.server", otelhttp.WithTracerProvider(tr.TracerProvider()))
+	muxWithLogAndPanic := middleware.PanicMiddleware(middleware.RequestIDMiddleware(middleware.NewClientIPMiddleware(cfg.Server.ProxyHops)(tracedMux)))
 
 	// старт сервера
 	srv := &http.Server{
@@ -59,8 +104,105 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
-	log.Printf("server listening on %d...", cfg.Server.Port)
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatalf("server failed: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// неоплаченные брони освобождают места, а брошенные возвраты повторяются по расписанию;
+	// остановка ждёт текущего прохода
+	tasks.Every(ctx, cfg.Payment.ExpireInterval, payments.ExpireUnpaid)
+	tasks.Every(ctx, cfg.Payment.ExpireInterval, payments.RetryRefunds)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info("server listening", "port", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			fatal(log, "server failed", err)
+		}
+	case <-ctx.Done():
+		log.Info("shutdown signal received")
 	}
+
+	health.SetShuttingDown()
+	shutdown(log, srv, tasks, ctn, cfg.Server.ShutdownTimeout, cfg.Server.ReadinessDrain)
+}
+
+// readinessChecks собирает проверки зависимостей для /readyz.
+func readinessChecks(log *slog.Logger, cfg *config.Config, database *sqlx.DB, rdb *redis.Client) []handlers.HealthCheck {
+	expected, err := db.LatestMigrationVersion(cfg.Database.MigrationsDir)
+	if err != nil {
+		log.Warn("cannot read migrations dir, readiness will only require some migrations applied",
+			"dir", cfg.Database.MigrationsDir, "error", err)
+	}
+	return []handlers.HealthCheck{
+		{
+			Name:    "postgres",
+			Timeout: cfg.Health.PostgresTimeout,
+			Check:   database.PingContext,
+		},
+		{
+			Name:    "redis",
+			Timeout: cfg.Health.RedisTimeout,
+			Check: func(ctx context.Context) error {
+				return rdb.Ping(ctx).Err()
+			},
+		},
+		{
+			Name:    "migrations",
+			Timeout: cfg.Health.MigrationsTimeout,
+			Check: func(ctx context.Context) error {
+				version, err := db.MigrationVersion(ctx, database)
+				if err != nil {
+					return err
+				}
+				if version == 0 || version < expected {
+					return fmt.Errorf("database schema at version %d, expected %d", version, expected)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// shutdown останавливает сервис по шагам: выжидает drain, пока балансировщик видит 503 на /readyz
+// и перестаёт слать запросы, дожидается текущих запросов, затем фоновых задач (инвалидация кэша),
+// и только после этого закрывает БД, Redis и трассировку. drain входит в общий timeout.
+func shutdown(log *slog.Logger, srv *http.Server, tasks *background.Group, ctn di.Container, timeout, drain time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if drain > 0 {
+		log.Info("draining before shutdown", "delay", drain)
+		select {
+		case <-time.After(drain):
+		case <-ctx.Done():
+		}
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("http server shutdown failed", "error", err)
+	}
+	if err := tasks.Wait(ctx); err != nil {
+		log.Error("background tasks did not finish in time", "error", err)
+	}
+	if err := ctn.Delete(); err != nil {
+		log.Error("failed to close dependencies", "error", err)
+	}
+	log.Info("server stopped")
+}
+
+const defaultShutdownTimeout = 15 * time.Second
+
+func fatal(log *slog.Logger, msg string, err error) {
+	log.Error(msg, "error", err)
+	os.Exit(1)
 }
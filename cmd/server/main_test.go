@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 
@@ -15,10 +16,24 @@ import (
 
 	"github.com/pressly/goose/v3"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"gopkg.in/yaml.v3"
 
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/bulkimport"
+	"laschool.ru/event-booking-service/internal/cache"
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/event"
 	httprouter "laschool.ru/event-booking-service/internal/http"
+	"laschool.ru/event-booking-service/internal/http/handlers"
 	"laschool.ru/event-booking-service/internal/http/middleware"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/payment"
+	"laschool.ru/event-booking-service/internal/promo"
+	"laschool.ru/event-booking-service/internal/session"
+	"laschool.ru/event-booking-service/internal/user"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -86,8 +101,8 @@ func TestMain(m *testing.M) {
 
 	//добавляем фиктивного пользователя для тестов
 	_, err = dbConn.Exec(`
-		INSERT INTO users (id, name, email, password_hash)
-		VALUES (1, 'Test User', 'test@example.com', 'asdasfdssd2#$$@#sdsfsdf')
+		INSERT INTO users (id, name, email, password_hash, email_verified)
+		VALUES (1, 'Test User', 'test@example.com', 'asdasfdssd2#$$@#sdsfsdf', TRUE)
 		ON CONFLICT (id) DO NOTHING
 	`)
 	if err != nil {
@@ -101,9 +116,28 @@ func TestMain(m *testing.M) {
 	}
 
 	// собираем маршруты
-	mux := httprouter.NewRouter()
-	loggingMux := middleware.LoggingMiddleware(mux)
-	server = middleware.PanicMiddleware(loggingMux)
+	c := *ctn
+	appCfg := c.Get(config.DIConfig).(*config.Config)
+	bookingService := c.Get(booking.DIBookingService).(booking.Service)
+	eventService := c.Get(event.DIEventService).(event.Service)
+	userService := c.Get(user.DIUserService).(user.Service)
+	cacheService := c.Get(cache.DICacheService).(cache.Service)
+	tasks := c.Get(background.DIBackground).(*background.Group)
+	mux := httprouter.NewRouter(httprouter.Handlers{
+		Events:    handlers.NewEventHandler(eventService, cacheService, tasks),
+		Bookings:  handlers.NewBookingHandler(bookingService, eventService, cacheService, tasks),
+		Users:     user.NewHandler(userService),
+		Import:    bulkimport.NewHandler(c.Get(bulkimport.DIImporter).(*bulkimport.Importer)),
+		Payments:  handlers.NewPaymentHandler(bookingService, c.Get(payment.DIProvider).(payment.Provider), cacheService, tasks),
+		Promos:    handlers.NewPromoHandler(c.Get(promo.DIPromoService).(promo.Service)),
+		Health:    handlers.NewHealthHandler(),
+		Metrics:   c.Get(metrics.DIMetrics).(*metrics.Metrics),
+		Sessions:  c.Get(session.DISessions).(*session.Store),
+		JWTSecret: appCfg.JWT.Secret,
+	})
+	loggingMux := middleware.NewLoggingMiddleware(c.Get(logger.DILogger).(*slog.Logger))(mux)
+	tracedMux := otelhttp.NewHandler(loggingMux, "http.server")
+	server = middleware.PanicMiddleware(middleware.RequestIDMiddleware(middleware.NewClientIPMiddleware(appCfg.Server.ProxyHops)(tracedMux)))
 
 	// запуск тестов
 	code := m.Run()
@@ -141,8 +175,9 @@ func createEvent(t *testing.T, capacity int) int64 {
 		"description": "some desc",
 		"location":    "online",
 		"capacity":    capacity,
-		"starts_at":   "2025-10-01T10:00:00Z",
-		"ends_at":     "2025-10-01T12:00:00Z",
+		// событие в будущем, чтобы действовало окно бесплатной отмены
+		"starts_at": time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339),
+		"ends_at":   time.Now().Add(30*24*time.Hour + 2*time.Hour).UTC().Format(time.RFC3339),
 	})
 	require.Equal(t, http.StatusCreated, resp.Code)
 
@@ -222,7 +257,12 @@ func TestBookingCRUD(t *testing.T) {
 
 	// Отмена брони
 	resp = doRequest(t, "DELETE", fmt.Sprintf("/bookings/%d", bookingID), nil)
-	require.Equal(t, http.StatusNoContent, resp.Code)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var refund struct {
+		RefundPercent int `json:"refund_percent"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&refund))
+	require.Equal(t, 100, refund.RefundPercent)
 
 	// Проверяем, что бронь осталась, но статус "cancelled"
 	resp = doRequest(t, "GET", fmt.Sprintf("/bookings/%d", bookingID), nil)
@@ -233,6 +273,12 @@ func TestBookingCRUD(t *testing.T) {
 	}
 	require.NoError(t, json.NewDecoder(resp.Body).Decode(&cancelled))
 	require.Equal(t, "cancelled", cancelled.Status)
+
+	// Повторная отмена и отмена несуществующей брони
+	resp = doRequest(t, "DELETE", fmt.Sprintf("/bookings/%d", bookingID), nil)
+	require.Equal(t, http.StatusConflict, resp.Code)
+	resp = doRequest(t, "DELETE", "/bookings/999999", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code)
 }
 
 func TestBookingCapacity(t *testing.T) {
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Журнал блокировок входа (аудит перебора паролей)
+CREATE TABLE IF NOT EXISTS auth_lockouts (
+  id BIGSERIAL PRIMARY KEY,
+  scope TEXT NOT NULL CHECK (scope IN ('account', 'ip')),
+  email TEXT NOT NULL DEFAULT '',
+  user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  ip TEXT NOT NULL DEFAULT '',
+  failures INT NOT NULL,
+  locked_until TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_lockouts_email ON auth_lockouts(email);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_auth_lockouts_email;
+DROP TABLE IF EXISTS auth_lockouts;
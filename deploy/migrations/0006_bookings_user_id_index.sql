@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bookings_user_id;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- История брони: смены статуса и количества мест
+CREATE TABLE IF NOT EXISTS booking_status_history (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  from_status TEXT,
+  to_status TEXT NOT NULL,
+  seats_from INT,
+  seats_to INT NOT NULL,
+  actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_status_history_booking_id ON booking_status_history(booking_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_booking_status_history_booking_id;
+DROP TABLE IF EXISTS booking_status_history;
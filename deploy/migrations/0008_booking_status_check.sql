@@ -0,0 +1,15 @@
+-- +goose Up
+-- Допустимые статусы брони; переходы между ними проверяет booking.Service
+ALTER TABLE bookings
+  ADD CONSTRAINT bookings_status_check
+  CHECK (status IN ('pending', 'confirmed', 'cancelled', 'checked_in', 'no_show', 'refunded'));
+
+-- Брони, созданные до истории статусов, получают стартовую запись
+INSERT INTO booking_status_history (booking_id, from_status, to_status, seats_to, actor_id, reason, created_at)
+SELECT b.id, NULL, b.status, b.seats, b.user_id, 'backfill', b.created_at
+FROM bookings b
+WHERE NOT EXISTS (SELECT 1 FROM booking_status_history h WHERE h.booking_id = b.id);
+
+-- +goose Down
+DELETE FROM booking_status_history WHERE reason = 'backfill';
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
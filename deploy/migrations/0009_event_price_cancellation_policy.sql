@@ -0,0 +1,10 @@
+-- +goose Up
+-- Цена места в копейках и правила отмены брони
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS price BIGINT NOT NULL DEFAULT 0 CHECK (price >= 0),
+  ADD COLUMN IF NOT EXISTS cancellation_policy JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE events
+  DROP COLUMN IF EXISTS cancellation_policy,
+  DROP COLUMN IF EXISTS price;
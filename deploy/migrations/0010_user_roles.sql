@@ -0,0 +1,8 @@
+-- +goose Up
+-- Роли пользователей: staff отмечает посетителей на входе, admin управляет сервисом
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user'
+  CHECK (role IN ('user', 'staff', 'admin'));
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Отметка посетителей на входе: бронь на несколько мест можно отмечать частями
+ALTER TABLE bookings
+  ADD COLUMN IF NOT EXISTS checked_in_seats INT NOT NULL DEFAULT 0,
+  ADD CONSTRAINT bookings_checked_in_seats_check CHECK (checked_in_seats >= 0 AND checked_in_seats <= seats);
+
+CREATE TABLE IF NOT EXISTS booking_checkins (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  seats INT NOT NULL CHECK (seats > 0),
+  staff_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_checkins_booking_id ON booking_checkins(booking_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_booking_checkins_booking_id;
+DROP TABLE IF EXISTS booking_checkins;
+ALTER TABLE bookings
+  DROP CONSTRAINT IF EXISTS bookings_checked_in_seats_check,
+  DROP COLUMN IF EXISTS checked_in_seats;
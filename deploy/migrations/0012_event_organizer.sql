@@ -0,0 +1,10 @@
+-- +goose Up
+-- Организатор — пользователь, создавший событие; у старых событий не задан
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS organizer_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_organizer_id ON events(organizer_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_organizer_id;
+ALTER TABLE events DROP COLUMN IF EXISTS organizer_id;
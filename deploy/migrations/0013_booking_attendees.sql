@@ -0,0 +1,26 @@
+-- +goose Up
+-- Поимённые посетители брони: не больше одного на место. По email посетитель закрепляет место за своим аккаунтом
+CREATE TABLE IF NOT EXISTS booking_attendees (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  email TEXT NOT NULL,
+  user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  checked_in_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booking_attendees_booking_email ON booking_attendees(booking_id, lower(email));
+CREATE INDEX IF NOT EXISTS idx_booking_attendees_email ON booking_attendees(lower(email));
+CREATE INDEX IF NOT EXISTS idx_booking_attendees_user_id ON booking_attendees(user_id);
+
+-- отметка по билету посетителя
+ALTER TABLE booking_checkins
+  ADD COLUMN IF NOT EXISTS attendee_id BIGINT REFERENCES booking_attendees(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE booking_checkins DROP COLUMN IF EXISTS attendee_id;
+DROP INDEX IF EXISTS idx_booking_attendees_user_id;
+DROP INDEX IF EXISTS idx_booking_attendees_email;
+DROP INDEX IF EXISTS idx_booking_attendees_booking_email;
+DROP TABLE IF EXISTS booking_attendees;
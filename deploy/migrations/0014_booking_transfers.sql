@@ -0,0 +1,30 @@
+-- +goose Up
+-- Передача брони другому пользователю: получатель подтверждает её токеном из письма
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS transfer_cutoff_hours INT NOT NULL DEFAULT 0 CHECK (transfer_cutoff_hours >= 0);
+
+-- ticket_version входит в подпись билета: после передачи старые QR-коды перестают действовать
+ALTER TABLE bookings
+  ADD COLUMN IF NOT EXISTS ticket_version INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS booking_transfers (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  from_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  to_email TEXT NOT NULL,
+  to_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'cancelled')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  accepted_at TIMESTAMPTZ
+);
+
+-- у брони не больше одной ожидающей передачи
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booking_transfers_pending ON booking_transfers(booking_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_booking_transfers_pending;
+DROP TABLE IF EXISTS booking_transfers;
+ALTER TABLE bookings DROP COLUMN IF EXISTS ticket_version;
+ALTER TABLE events DROP COLUMN IF EXISTS transfer_cutoff_hours;
@@ -0,0 +1,63 @@
+-- +goose Up
+-- Ограничения на бронирование события одним пользователем; 0 — без ограничения
+ALTER TABLE events
+  ADD COLUMN IF NOT EXISTS max_seats_per_booking INT NOT NULL DEFAULT 0 CHECK (max_seats_per_booking >= 0),
+  ADD COLUMN IF NOT EXISTS max_seats_per_user INT NOT NULL DEFAULT 0 CHECK (max_seats_per_user >= 0),
+  ADD COLUMN IF NOT EXISTS one_booking_per_user BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_event_user ON bookings(event_id, user_id);
+
+-- Страховка на уровне базы для записей в обход сервиса. Сервис проверяет то же самое под блокировкой
+-- события, поэтому параллельные брони одного пользователя не проскакивают между проверками.
+-- Проверяются только новые брони, увеличение мест и смена владельца: брони, созданные до появления
+-- ограничений, остаются как есть.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION bookings_check_limits() RETURNS trigger AS $$
+DECLARE
+  e RECORD;
+  other_seats INT;
+  other_bookings INT;
+  owner_changed BOOLEAN := TG_OP = 'INSERT' OR NEW.user_id IS DISTINCT FROM OLD.user_id;
+BEGIN
+  IF NEW.user_id IS NULL OR NEW.status NOT IN ('pending', 'confirmed', 'checked_in', 'no_show') THEN
+    RETURN NEW;
+  END IF;
+  IF NOT owner_changed AND NEW.seats <= OLD.seats THEN
+    RETURN NEW;
+  END IF;
+
+  SELECT max_seats_per_booking, max_seats_per_user, one_booking_per_user INTO e FROM events WHERE id = NEW.event_id;
+  IF e.max_seats_per_booking > 0 AND NEW.seats > e.max_seats_per_booking THEN
+    RAISE EXCEPTION 'booking has % seats, event allows %', NEW.seats, e.max_seats_per_booking
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'bookings_max_seats_per_booking';
+  END IF;
+
+  SELECT COALESCE(SUM(seats), 0), COUNT(*) INTO other_seats, other_bookings
+  FROM bookings
+  WHERE event_id = NEW.event_id AND user_id = NEW.user_id AND id <> NEW.id
+    AND status IN ('pending', 'confirmed', 'checked_in', 'no_show');
+  IF e.one_booking_per_user AND owner_changed AND other_bookings > 0 THEN
+    RAISE EXCEPTION 'user % already has a booking for event %', NEW.user_id, NEW.event_id
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'bookings_one_per_user';
+  END IF;
+  IF e.max_seats_per_user > 0 AND other_seats + NEW.seats > e.max_seats_per_user THEN
+    RAISE EXCEPTION 'user % would hold % seats, event allows %', NEW.user_id, other_seats + NEW.seats, e.max_seats_per_user
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'bookings_max_seats_per_user';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER bookings_check_limits
+  BEFORE INSERT OR UPDATE OF seats, user_id ON bookings
+  FOR EACH ROW EXECUTE FUNCTION bookings_check_limits();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS bookings_check_limits ON bookings;
+DROP FUNCTION IF EXISTS bookings_check_limits();
+DROP INDEX IF EXISTS idx_bookings_event_user;
+ALTER TABLE events
+  DROP COLUMN IF EXISTS one_booking_per_user,
+  DROP COLUMN IF EXISTS max_seats_per_user,
+  DROP COLUMN IF EXISTS max_seats_per_booking;
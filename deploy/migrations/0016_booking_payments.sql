@@ -0,0 +1,27 @@
+-- +goose Up
+-- Оплата броней платных событий: бронь ждёт оплаты в статусе pending до expires_at
+ALTER TABLE bookings
+  ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_pending_expires ON bookings(expires_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS booking_payments (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL,
+  intent_id TEXT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'authorized', 'captured', 'failed', 'cancelled')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (provider, intent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_payments_booking ON booking_payments(booking_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS booking_payments;
+DROP INDEX IF EXISTS idx_bookings_pending_expires;
+ALTER TABLE bookings DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Возвраты оплаченных броней при отмене. Запись создаётся вместе с отменой, а провайдер вызывается после коммита
+CREATE TABLE IF NOT EXISTS booking_refunds (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id BIGINT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+  payment_id BIGINT NOT NULL REFERENCES booking_payments(id) ON DELETE CASCADE,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  status TEXT NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'succeeded', 'failed')),
+  provider_refund_id TEXT,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- незавершённые возвраты подбирает фоновый повтор
+CREATE INDEX IF NOT EXISTS idx_booking_refunds_requested ON booking_refunds(updated_at) WHERE status = 'requested';
+
+-- +goose Down
+DROP TABLE IF EXISTS booking_refunds;
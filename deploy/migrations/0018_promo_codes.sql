@@ -0,0 +1,43 @@
+-- +goose Up
+-- Промокоды на скидку. uses считает брони, оформленные по коду; неоплаченные отменённые брони код возвращают
+CREATE TABLE IF NOT EXISTS promo_codes (
+  id BIGSERIAL PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+  value BIGINT NOT NULL CHECK (value > 0 AND (kind <> 'percent' OR value <= 100)),
+  max_uses INT NOT NULL DEFAULT 0 CHECK (max_uses >= 0),
+  uses INT NOT NULL DEFAULT 0 CHECK (uses >= 0),
+  valid_from TIMESTAMPTZ,
+  valid_until TIMESTAMPTZ CHECK (valid_until > valid_from),
+  -- пустой список — код действует на все события; без внешнего ключа, чтобы удаление события не снимало ограничение
+  event_ids BIGINT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings
+  ADD COLUMN IF NOT EXISTS promo_code_id BIGINT REFERENCES promo_codes(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+-- Бронь, отменённая до оплаты (истёк срок, платёж не создан или отменён), не расходует код.
+-- Триггер ловит все пути отмены, включая фоновую.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION bookings_release_promo() RETURNS trigger AS $$
+BEGIN
+  UPDATE promo_codes SET uses = uses - 1, updated_at = NOW() WHERE id = NEW.promo_code_id AND uses > 0;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER bookings_release_promo
+  AFTER UPDATE OF status ON bookings
+  FOR EACH ROW
+  WHEN (OLD.status = 'pending' AND NEW.status = 'cancelled' AND NEW.promo_code_id IS NOT NULL)
+  EXECUTE FUNCTION bookings_release_promo();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS bookings_release_promo ON bookings;
+DROP FUNCTION IF EXISTS bookings_release_promo();
+ALTER TABLE bookings DROP COLUMN IF EXISTS discount, DROP COLUMN IF EXISTS promo_code_id;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Вход, сброс пароля и проверка занятости email ищут пользователя без учёта регистра
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_email_lower;
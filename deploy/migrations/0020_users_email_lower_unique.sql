@@ -0,0 +1,13 @@
+-- +goose Up
+-- Email уникален без учёта регистра: проверка перед вставкой не спасает от параллельных регистраций.
+-- Из дублей, оставшихся с тех пор, как регистр учитывался, адрес сохраняет самый старый аккаунт (его и находил
+-- вход); у остальных email переименовывается и снимается подтверждение, брони остаются за ними
+UPDATE users u SET email = 'duplicate-' || u.id || '-' || u.email, email_verified = FALSE
+WHERE EXISTS (SELECT 1 FROM users o WHERE lower(o.email) = lower(u.email) AND o.id < u.id);
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email));
+
+-- +goose Down
+DROP INDEX IF EXISTS users_email_lower_key;
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
@@ -15,44 +15,47 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/bookings": {
+        "/admin/bookings/import": {
             "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Создает новое бронирование для события",
+                "description": "Создаёт брони по строкам event_id,email,seats (заголовок необязателен). Пользователи без аккаунта создаются автоматически. Строки платных событий и пользователей без подтверждённого email отклоняются, если не передан complimentary=true — тогда брони выдаются бесплатно. В режиме all_or_nothing при ошибке в любой строке ничего не сохраняется и возвращается 422; в режиме best_effort сохраняются корректные строки. Только для администраторов.",
                 "consumes": [
-                    "application/json"
+                    "text/csv"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "admin"
                 ],
-                "summary": "Создать бронирование",
+                "summary": "Импорт броней из CSV",
                 "parameters": [
                     {
-                        "description": "Данные бронирования",
-                        "name": "booking",
+                        "type": "string",
+                        "description": "Режим: all_or_nothing (по умолчанию) или best_effort",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Бесплатные брони: разрешить платные события и неподтверждённые email",
+                        "name": "complimentary",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV с колонками event_id,email,seats",
+                        "name": "file",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/booking.CreateBookingRequest"
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "id of created booking",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer",
-                                "format": "int64"
-                            }
+                            "$ref": "#/definitions/bulkimport.Report"
                         }
                     },
                     "400": {
@@ -60,115 +63,53 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/bookings/{id}": {
-            "get": {
-                "description": "Возвращает информацию о бронировании по ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "bookings"
-                ],
-                "summary": "Получить бронирование",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID бронирования",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Пример успешного ответа",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/booking.Booking"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Некорректный ID",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Бронирование не найдено",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/bulkimport.Report"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Отменяет бронирование по ID",
-                "tags": [
-                    "bookings"
-                ],
-                "summary": "Отменить бронирование",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID бронирования",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "Бронирование отменено"
-                    },
-                    "400": {
-                        "description": "Некорректный ID",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Бронирование не найдено",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
-                        }
-                    }
-                }
+                ]
             }
         },
-        "/events": {
+        "/admin/promo-codes": {
             "get": {
-                "description": "Возвращает список событий",
+                "description": "Промокоды с числом использований, новые первыми. Только для администраторов",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "promo-codes"
                 ],
-                "summary": "Список событий",
+                "summary": "Список промокодов",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Лимит записей",
+                        "description": "Размер страницы (по умолчанию 20, максимум 100)",
                         "name": "limit",
                         "in": "query"
                     },
@@ -181,29 +122,35 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Пример успешного ответа",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/event.Event"
+                                "$ref": "#/definitions/promo.PromoCode"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не администратор",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Создает новое событие",
+                ]
+            },
+            "post": {
+                "description": "Скидка в процентах (kind=percent, value 1–100) или фиксированной суммой в копейках (kind=fixed).\nmax_uses — сколько броней можно оформить по коду (0 — без ограничения), valid_from и valid_until —\nокно действия, event_ids — события, на которые действует код (пусто — на все). Код хранится\nв верхнем регистре. Только для администраторов",
                 "consumes": [
                     "application/json"
                 ],
@@ -211,23 +158,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "promo-codes"
                 ],
-                "summary": "Создать событие",
+                "summary": "Создать промокод",
                 "parameters": [
                     {
-                        "description": "Данные события",
-                        "name": "event",
+                        "description": "Промокод",
+                        "name": "promo",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/event.CreateEventRequest"
+                            "$ref": "#/definitions/promo.PromoCodeRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "id of created event",
+                        "description": "id созданного промокода",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -237,37 +184,51 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Код уже существует",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/events/{id}": {
+        "/admin/promo-codes/{id}": {
             "get": {
-                "description": "Возвращает информацию о событии по ID\nВозвращает информацию о событии по ID",
+                "description": "Только для администраторов",
                 "produces": [
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "events",
-                    "events"
+                    "promo-codes"
                 ],
-                "summary": "Получить событие",
+                "summary": "Получить промокод",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID события",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID события",
+                        "description": "ID промокода",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -275,9 +236,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Пример успешного ответа",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/event.Event"
+                            "$ref": "#/definitions/promo.PromoCode"
                         }
                     },
                     "400": {
@@ -286,55 +247,61 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Событие не найдено",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Промокод не найден",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Обновляет данные события по ID",
+                ]
+            },
+            "put": {
+                "description": "Заменяет условия промокода. Число использований сохраняется; уже оформленные брони не меняются.\nТолько для администраторов",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "promo-codes"
                 ],
-                "summary": "Обновить событие",
+                "summary": "Изменить промокод",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID события",
+                        "description": "ID промокода",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные события",
-                        "name": "event",
+                        "description": "Промокод",
+                        "name": "promo",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/event.CreateEventRequest"
+                            "$ref": "#/definitions/promo.PromoCodeRequest"
                         }
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Событие обновлено"
+                        "description": "Промокод обновлён"
                     },
                     "400": {
                         "description": "Некорректные данные",
@@ -342,35 +309,47 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Событие не найдено",
+                        "description": "Промокод не найден",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "409": {
+                        "description": "Код уже существует",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "Bearer": []
                     }
-                ],
-                "description": "Удаляет событие по ID",
+                ]
+            },
+            "delete": {
+                "description": "Код перестаёт применяться; скидка в уже оформленных бронях сохраняется. Только для администраторов",
                 "tags": [
-                    "events"
+                    "promo-codes"
                 ],
-                "summary": "Удалить событие",
+                "summary": "Удалить промокод",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID события",
+                        "description": "ID промокода",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -378,7 +357,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Событие удалено"
+                        "description": "Промокод удалён"
                     },
                     "400": {
                         "description": "Некорректный ID",
@@ -386,156 +365,255 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Событие не найдено",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Промокод не найден",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/events/{id}/bookings": {
-            "get": {
-                "description": "Возвращает список бронирований для события",
+        "/bookings": {
+            "post": {
+                "description": "Создает новое бронирование для события. Можно сразу указать посетителей — не больше одного на место.\nБронь платного события создаётся в статусе pending вместе с платежом: в ответе client_secret для\nплатёжной формы и срок, до которого нужно оплатить. Подтверждает бронь уведомление провайдера.\npromo_code даёт скидку на платное событие; если она покрывает всю сумму, бронь подтверждается сразу.\nНеподходящий код — 422 с причиной: не найден, ещё не действует, истёк, исчерпан, не для этого события",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "bookings"
                 ],
-                "summary": "Список бронирований по событию",
+                "summary": "Создать бронирование",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID события",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Лимит записей",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Смещение",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Данные бронирования",
+                        "name": "booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.CreateBookingRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Пример успешного ответа",
+                    "201": {
+                        "description": "id созданной брони; для платных событий — ещё и данные оплаты",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/booking.Booking"
-                            }
+                            "$ref": "#/definitions/booking.Checkout"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID события",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Событие не найдено",
+                    "403": {
+                        "description": "Email не подтверждён",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "409": {
+                        "description": "Превышены ограничения события на одного пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Промокод не применим",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Провайдер платежей не создал платёж",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Оплата не настроена",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/health": {
-            "get": {
-                "description": "Проверяет состояние конфигурации и базы данных",
+        "/bookings/transfers/accept": {
+            "post": {
+                "description": "Переоформляет бронь на текущего пользователя по токену из письма. Email аккаунта должен совпадать\nс адресом получателя и быть подтверждён. Билеты прежнего владельца перестают действовать — новый билет\nвыдаётся через GET /bookings/{id}/ticket.png",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "bookings"
+                ],
+                "summary": "Принять переданную бронь",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.AcceptTransferRequest"
+                        }
+                    }
                 ],
-                "summary": "Проверка состояния сервиса",
                 "responses": {
                     "200": {
-                        "description": "ok"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/booking.Booking"
+                        }
                     },
-                    "503": {
-                        "description": "config load failed | db open failed"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Передача адресована другому email",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Передача не найдена или уже принята",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Окно передачи закрыто, бронь больше нельзя передать или у получателя превышены ограничения события",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Приглашение истекло",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
-        "/ping": {
+        "/bookings/{id}": {
             "get": {
-                "description": "Возвращает pong, если сервис работает\nВозвращает pong, если сервис работает",
+                "description": "Возвращает информацию о бронировании по ID",
                 "produces": [
-                    "text/plain",
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "health",
-                    "health"
+                    "bookings"
+                ],
+                "summary": "Получить бронирование",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Проверка доступности",
                 "responses": {
                     "200": {
-                        "description": "pong"
+                        "description": "Пример успешного ответа",
+                        "schema": {
+                            "$ref": "#/definitions/booking.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/users/login": {
-            "post": {
-                "description": "Выполняет аутентификацию и возвращает JWT",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Отменяет свою бронь по ID с учётом политики отмены события и возвращает сумму возврата.\nПереход фиксируется в истории статусов. По оплаченной брони возврат проводится через\nпровайдера в фоне: refund_id и refund_status показывают его состояние, после успешного\nвозврата бронь переходит в refunded",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Вход в систему",
+                "summary": "Отменить бронирование",
                 "parameters": [
                     {
-                        "description": "Email и пароль",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "id и token",
+                        "description": "Бронирование отменено",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/booking.Cancellation"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Некорректный ID",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
@@ -545,13 +623,40 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Бронь уже отменена, по ней уже проходили, её статус не допускает отмены или окно отмены закрыто",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/users/register": {
-            "post": {
-                "description": "Регистрирует нового пользователя",
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Атомарно меняет количество мест в своей брони. Увеличение проверяется по вместимости события",
                 "consumes": [
                     "application/json"
                 ],
@@ -559,26 +664,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Изменить количество мест",
                 "parameters": [
                     {
-                        "description": "Данные пользователя",
-                        "name": "user",
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое количество мест",
+                        "name": "booking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.RegisterRequest"
+                            "$ref": "#/definitions/booking.UpdateBookingRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "id",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/booking.Booking"
                         }
                     },
                     "400": {
@@ -587,60 +698,2274 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "409": {
-                        "description": "Conflict",
+                        "description": "Не хватает мест, превышены ограничения события, бронь нельзя изменить, бронь платная или с промокодом, или посетителей больше новых мест",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/bookings/{id}/attendees": {
+            "get": {
+                "description": "Возвращает поимённых посетителей своей брони",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Посетители брони",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingAttendee"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Заменяет список посетителей своей брони (не больше одного на место). Посетители с прежним email\nсохраняют билет и закрепление за аккаунтом, у новых email — новые билеты, убранные билеты перестают действовать.\nМенять список можно до отсечки перед началом события (booking.attendee_edit_cutoff); прошедших на вход убрать нельзя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Изменить посетителей брони",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый список посетителей",
+                        "name": "attendees",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.UpdateAttendeesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingAttendee"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Нет имени, некорректный или повторяющийся email",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Редактирование закрыто, посетителей больше мест или бронь нельзя изменить",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/bookings/{id}/attendees/{attendee_id}/ticket.png": {
+            "get": {
+                "description": "Возвращает PNG с QR-кодом билета на одно место. Доступен владельцу брони и аккаунту, закрепившему место",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Билет посетителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID посетителя",
+                        "name": "attendee_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR-код билета",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь и чужое место",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование или посетитель не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Бронь не подтверждена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/bookings/{id}/ticket.png": {
+            "get": {
+                "description": "Возвращает PNG с QR-кодом подписанного билета своей подтверждённой брони. Код сканируют на входе",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Билет с QR-кодом",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR-код билета",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Бронь не подтверждена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/bookings/{id}/transfer": {
+            "post": {
+                "description": "Предлагает свою подтверждённую бронь другому пользователю: на указанный email уходит письмо со ссылкой.\nБронь переходит к получателю, когда он принимает передачу. Новая передача заменяет ожидающую.\nНельзя после прохода на вход и позже transfer_cutoff_hours до начала события",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Передать бронь",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID бронирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email получателя",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/booking.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный email или передача самому себе",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Чужая бронь",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Окно передачи закрыто или бронь нельзя передать",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Возвращает список событий",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Список событий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пример успешного ответа",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/event.Event"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новое событие",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Создать событие",
+                "parameters": [
+                    {
+                        "description": "Данные события",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/event.CreateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "id of created event",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events/{id}": {
+            "get": {
+                "description": "Возвращает информацию о событии по ID\nВозвращает информацию о событии по ID",
+                "produces": [
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "events",
+                    "events"
+                ],
+                "summary": "Получить событие",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пример успешного ответа",
+                        "schema": {
+                            "$ref": "#/definitions/event.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные события по ID. Доступно организатору события и администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Обновить событие",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные события",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/event.CreateEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Событие обновлено"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не организатор и не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Удаляет событие по ID. Доступно организатору события и администраторам",
+                "tags": [
+                    "events"
+                ],
+                "summary": "Удалить событие",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Событие удалено"
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не организатор и не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events/{id}/attendees.csv": {
+            "get": {
+                "description": "Выгружает все подтверждённые брони события с именем и email пользователя, числом мест,\nвременем брони и отметкой на входе. Строки отдаются потоком прямо из базы.\nДоступно организатору события и администраторам",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Список посетителей (CSV)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не организатор и не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events/{id}/attendees.xlsx": {
+            "get": {
+                "description": "То же, что attendees.csv, в формате Excel. Строки пишутся потоковым писателем листа",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Список посетителей (XLSX)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XLSX",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не организатор и не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events/{id}/bookings": {
+            "get": {
+                "description": "Возвращает список бронирований для события",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Список бронирований по событию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пример успешного ответа",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.Booking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID события",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/checkin": {
+            "post": {
+                "description": "Проверяет отсканированный код билета и пропускает указанное число мест (по умолчанию все оставшиеся).\nБронь на несколько мест можно отмечать частями; повторный проход по отмеченным местам отклоняется.\nДоступно сотрудникам (staff) и администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Отметить посетителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Код билета",
+                        "name": "checkin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/booking.CheckInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/booking.CheckIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Недействительный билет или билет на другое событие",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет роли staff или admin",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бронирование не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Уже отмечены все места или бронь не подтверждена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/events/{id}/refunds": {
+            "get": {
+                "description": "Возвраты оплаты по отменённым броням события, новые первыми. requested — возврат проводится,\nsucceeded — деньги вернулись, failed — провайдер отказал или закончились попытки,\nвернуть нужно вручную. Доступно организатору события и администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Возвраты по событию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID события",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.Refund"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Не организатор и не администратор",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Событие не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Процесс жив и обрабатывает запросы. Зависимости не проверяются.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness-проба",
+                "responses": {
+                    "200": {
+                        "description": "ok"
+                    }
+                }
+            }
+        },
+        "/ping": {
+            "get": {
+                "description": "Возвращает pong, если сервис работает",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка доступности",
+                "responses": {
+                    "200": {
+                        "description": "pong"
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет PostgreSQL, Redis и версию миграций. Во время остановки сервиса возвращает 503.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness-проба",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Выполняет аутентификацию и возвращает JWT",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Вход в систему",
+                "parameters": [
+                    {
+                        "description": "Email и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "id и token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Профиль текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Удаляет аккаунт после подтверждения паролем. Бронирования сохраняются обезличенными",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить аккаунт",
+                "parameters": [
+                    {
+                        "description": "Пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Меняет имя и/или email. После смены email его нужно подтвердить заново",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Обновить профиль",
+                "parameters": [
+                    {
+                        "description": "Новые значения",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/users/me/attendees": {
+            "get": {
+                "description": "Возвращает места действующих броней, закреплённые за текущим пользователем",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Мои места в чужих бронях",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingAttendee"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/users/me/attendees/claim": {
+            "post": {
+                "description": "Закрепляет за текущим пользователем места в чужих бронях, где он указан посетителем по email.\nEmail аккаунта должен быть подтверждён. После этого билет места можно получить самому",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Закрепить места за собой",
+                "responses": {
+                    "200": {
+                        "description": "Места, закреплённые этим запросом",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.BookingAttendee"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/users/me/bookings": {
+            "get": {
+                "description": "Возвращает бронирования текущего пользователя с кратким описанием событий",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Мои бронирования",
+                "parameters": [
+                    {
+                        "enum": [
+                            "confirmed",
+                            "cancelled",
+                            "upcoming",
+                            "past"
+                        ],
+                        "type": "string",
+                        "description": "Фильтр",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/booking.UserBooking"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный фильтр",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/users/me/password": {
+            "post": {
+                "description": "Проверяет текущий пароль, завершает все сессии и возвращает новый JWT",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сменить пароль",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ]
+            }
+        },
+        "/users/password/forgot": {
+            "post": {
+                "description": "Отправляет письмо со ссылкой для сброса пароля. Всегда отвечает 202, чтобы не выдавать, зарегистрирован ли email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password/reset": {
+            "post": {
+                "description": "Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Регистрирует нового пользователя и отправляет письмо для подтверждения email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/verify": {
+            "get": {
+                "description": "Подтверждает email по одноразовой ссылке из письма",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен из письма",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/payments": {
+            "post": {
+                "description": "Принимает уведомление провайдера платежей, подписанное webhook_secret. Оплата подтверждает\nожидающую бронь и списывает заблокированные деньги; отмена платежа освобождает места.\nПовторные уведомления безопасны. При 5xx провайдер присылает уведомление снова",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Уведомление об оплате",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверная подпись или тело",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось обработать, провайдер повторит",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "booking.AcceptTransferRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "c2VjcmV0.c2lnbmF0dXJl"
+                }
+            }
+        },
+        "booking.AttendeeInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Мария Иванова"
+                }
+            }
+        },
+        "booking.Booking": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "description": "Attendees — поимённые посетители, передаются только при создании брони",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/booking.BookingAttendee"
+                    }
+                },
+                "checked_in_seats": {
+                    "description": "CheckedInSeats — сколько мест уже прошло на входе",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt — до какого момента неоплаченная бронь платного события держит места",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "promo_code_id": {
+                    "description": "PromoCodeID и Discount — применённый промокод и скидка в копейках",
+                    "type": "integer"
+                },
+                "seats": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/booking.Status"
+                },
+                "user_id": {
+                    "description": "UserID == 0 — аккаунт владельца удалён, бронь обезличена",
+                    "type": "integer"
+                }
+            }
+        },
+        "booking.BookingAttendee": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "maria@example.com"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Мария Иванова"
+                },
+                "user_id": {
+                    "description": "UserID — аккаунт, закрепивший место за собой по email; 0 — место не закреплено",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "booking.Cancellation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 300000
+                },
+                "booking_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "fee": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "refund": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "refund_id": {
+                    "description": "RefundID и RefundStatus — возврат через провайдера платежей; пусто, если возвращать нечего\nили бронь оплачивалась не через сервис",
+                    "type": "integer",
+                    "example": 1
+                },
+                "refund_percent": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "refund_status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/booking.RefundStatus"
+                        }
+                    ],
+                    "example": "requested"
+                }
+            }
+        },
+        "booking.CheckIn": {
+            "type": "object",
+            "properties": {
+                "admitted": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "attendee_id": {
+                    "description": "AttendeeID — посетитель, отмеченный по своему билету",
+                    "type": "integer",
+                    "example": 0
+                },
+                "booking_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "checked_in_seats": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "seats": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/booking.Status"
+                        }
+                    ],
+                    "example": "confirmed"
+                }
+            }
+        },
+        "booking.CheckInRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code — содержимое QR-кода билета",
+                    "type": "string",
+                    "example": "eyJiIjoxLCJlIjoxLCJzIjoyfQ.c2lnbmF0dXJl"
+                },
+                "seats": {
+                    "description": "Seats — сколько мест пропустить; 0 — все оставшиеся",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "booking.Checkout": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount — сумма к оплате со скидкой; 0 — промокод покрыл всё, бронь подтверждена без оплаты",
+                    "type": "integer",
+                    "example": 225000
+                },
+                "client_secret": {
+                    "description": "ClientSecret передаётся платёжной форме провайдера на клиенте",
+                    "type": "string",
+                    "example": "pi_123_secret_456"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "rub"
+                },
+                "discount": {
+                    "description": "Discount — скидка по промокоду",
+                    "type": "integer",
+                    "example": 75000
+                },
+                "expires_at": {
+                    "description": "ExpiresAt — до какого момента бронь держит места без оплаты",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "stripe"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/booking.Status"
+                        }
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "booking.CreateBookingRequest": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "description": "Attendees — необязательный список посетителей, не больше одного на место",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/booking.AttendeeInput"
+                    }
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "promo_code": {
+                    "description": "PromoCode — необязательный промокод на скидку, только для платных событий",
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "seats": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "booking.EventSummary": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "booking.Refund": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "booking_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "rub"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "LastError — ошибка последней неудачной попытки",
+                    "type": "string"
+                },
+                "payment_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "provider_refund_id": {
+                    "description": "ProviderRefundID — идентификатор возврата у провайдера",
+                    "type": "string",
+                    "example": "re_123"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/booking.RefundStatus"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "UserID — владелец брони; 0 — аккаунт удалён",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "booking.RefundStatus": {
+            "type": "string",
+            "enum": [
+                "requested",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "RefundRequested",
+                "RefundSucceeded",
+                "RefundFailed"
+            ]
+        },
+        "booking.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed",
+                "cancelled",
+                "checked_in",
+                "no_show",
+                "refunded"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusConfirmed",
+                "StatusCancelled",
+                "StatusCheckedIn",
+                "StatusNoShow",
+                "StatusRefunded"
+            ]
+        },
+        "booking.Transfer": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "from_user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "to_email": {
+                    "type": "string",
+                    "example": "colleague@example.com"
+                },
+                "to_user_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "booking.TransferRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "colleague@example.com"
+                }
+            }
+        },
+        "booking.UpdateAttendeesRequest": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/booking.AttendeeInput"
+                    }
                 }
             }
-        }
-    },
-    "definitions": {
-        "booking.Booking": {
+        },
+        "booking.UpdateBookingRequest": {
+            "type": "object",
+            "properties": {
+                "seats": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "booking.UserBooking": {
             "type": "object",
             "properties": {
+                "attendees": {
+                    "description": "Attendees — поимённые посетители, передаются только при создании брони",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/booking.BookingAttendee"
+                    }
+                },
+                "checked_in_seats": {
+                    "description": "CheckedInSeats — сколько мест уже прошло на входе",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "discount": {
+                    "type": "integer"
+                },
+                "event": {
+                    "$ref": "#/definitions/booking.EventSummary"
+                },
                 "event_id": {
                     "type": "integer"
                 },
+                "expires_at": {
+                    "description": "ExpiresAt — до какого момента неоплаченная бронь платного события держит места",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "promo_code_id": {
+                    "description": "PromoCodeID и Discount — применённый промокод и скидка в копейках",
+                    "type": "integer"
+                },
                 "seats": {
                     "type": "integer"
                 },
                 "status": {
-                    "type": "string"
+                    "$ref": "#/definitions/booking.Status"
                 },
                 "user_id": {
+                    "description": "UserID == 0 — аккаунт владельца удалён, бронь обезличена",
                     "type": "integer"
                 }
             }
         },
-        "booking.CreateBookingRequest": {
+        "bulkimport.Mode": {
+            "type": "string",
+            "enum": [
+                "all_or_nothing",
+                "best_effort"
+            ],
+            "x-enum-varnames": [
+                "ModeAllOrNothing",
+                "ModeBestEffort"
+            ]
+        },
+        "bulkimport.Report": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "description": "Committed — брони сохранены: в all_or_nothing все, в best_effort хотя бы одна",
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "mode": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/bulkimport.Mode"
+                        }
+                    ],
+                    "example": "all_or_nothing"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bulkimport.RowResult"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "bulkimport.RowResult": {
             "type": "object",
             "properties": {
+                "booking_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "error": {
+                    "type": "string"
+                },
                 "event_id": {
                     "type": "integer",
                     "example": 1
                 },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "seats": {
                     "type": "integer",
                     "example": 2
                 },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "user_created": {
+                    "type": "boolean"
+                },
                 "user_id": {
                     "type": "integer",
-                    "example": 1
+                    "example": 7
+                }
+            }
+        },
+        "event.CancellationRule": {
+            "type": "object",
+            "properties": {
+                "hours_before": {
+                    "type": "integer",
+                    "example": 48
+                },
+                "refund_percent": {
+                    "type": "integer",
+                    "example": 100
                 }
             }
         },
         "event.CreateEventRequest": {
             "type": "object",
             "properties": {
+                "cancellation_policy": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/event.CancellationRule"
+                    }
+                },
                 "capacity": {
                     "type": "integer",
                     "example": 100
@@ -657,6 +2982,26 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Central Park"
                 },
+                "max_seats_per_booking": {
+                    "description": "MaxSeatsPerBooking — мест в одной брони, 0 — без ограничения",
+                    "type": "integer",
+                    "example": 4
+                },
+                "max_seats_per_user": {
+                    "description": "MaxSeatsPerUser — мест на пользователя во всех его бронях события, 0 — без ограничения",
+                    "type": "integer",
+                    "example": 6
+                },
+                "one_booking_per_user": {
+                    "description": "OneBookingPerUser — не больше одной действующей брони на пользователя",
+                    "type": "boolean",
+                    "example": false
+                },
+                "price": {
+                    "description": "Price — цена места в копейках",
+                    "type": "integer",
+                    "example": 150000
+                },
                 "starts_at": {
                     "type": "string",
                     "example": "2026-01-15T18:00:00Z"
@@ -664,12 +3009,23 @@ const docTemplate = `{
                 "title": {
                     "type": "string",
                     "example": "Concert: The Rusty Cats"
+                },
+                "transfer_cutoff_hours": {
+                    "description": "TransferCutoffHours — за сколько часов до начала закрывается передача броней",
+                    "type": "integer",
+                    "example": 24
                 }
             }
         },
         "event.Event": {
             "type": "object",
             "properties": {
+                "cancellation_policy": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/event.CancellationRule"
+                    }
+                },
                 "capacity": {
                     "type": "integer"
                 },
@@ -688,26 +3044,238 @@ const docTemplate = `{
                 "location": {
                     "type": "string"
                 },
+                "max_seats_per_booking": {
+                    "description": "Ограничения для одного пользователя; 0 — без ограничения",
+                    "type": "integer"
+                },
+                "max_seats_per_user": {
+                    "type": "integer"
+                },
+                "one_booking_per_user": {
+                    "type": "boolean"
+                },
+                "organizer_id": {
+                    "description": "OrganizerID — создатель события; 0 — не задан (события, созданные до появления организаторов)",
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "Price — цена места в копейках, 0 — бесплатное событие",
+                    "type": "integer"
+                },
                 "starts_at": {
                     "type": "string"
                 },
                 "title": {
                     "type": "string"
                 },
+                "transfer_cutoff_hours": {
+                    "description": "TransferCutoffHours — за сколько часов до начала закрывается передача броней; 0 — до начала",
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "handlers.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "handlers.ErrorResponse": {
             "type": "object",
             "properties": {
                 "message": {},
+                "request_id": {
+                    "type": "string"
+                },
                 "status": {
                     "type": "integer"
                 }
             }
         },
+        "handlers.ReadinessReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "handlers.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Status — processed, ignored (уведомление не о наших платежах) или expired (оплата после срока брони)",
+                    "type": "string",
+                    "example": "processed"
+                }
+            }
+        },
+        "promo.Kind": {
+            "type": "string",
+            "enum": [
+                "percent",
+                "fixed"
+            ],
+            "x-enum-varnames": [
+                "KindPercent",
+                "KindFixed"
+            ]
+        },
+        "promo.PromoCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_ids": {
+                    "description": "EventIDs — события, на которые действует код; пусто — на все",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/promo.Kind"
+                        }
+                    ],
+                    "example": "percent"
+                },
+                "max_uses": {
+                    "description": "MaxUses — сколько броней можно оформить по коду; 0 — без ограничения",
+                    "type": "integer",
+                    "example": 100
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uses": {
+                    "description": "Uses — сколько броней оформлено; брони, отменённые до оплаты, не считаются",
+                    "type": "integer",
+                    "example": 12
+                },
+                "valid_from": {
+                    "description": "ValidFrom и ValidUntil — окно действия; пусто — без ограничения с этой стороны",
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value — процент или сумма в копейках, в зависимости от Kind",
+                    "type": "integer",
+                    "example": 25
+                }
+            }
+        },
+        "promo.PromoCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code — латиница, цифры, - и _; регистр не важен",
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "event_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/promo.Kind"
+                        }
+                    ],
+                    "example": "percent"
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "valid_from": {
+                    "type": "string",
+                    "example": "2026-03-01T00:00:00Z"
+                },
+                "valid_until": {
+                    "type": "string",
+                    "example": "2026-04-01T00:00:00Z"
+                },
+                "value": {
+                    "type": "integer",
+                    "example": 25
+                }
+            }
+        },
+        "user.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "new_password": {
+                    "type": "string",
+                    "example": "newpassword123"
+                }
+            }
+        },
+        "user.DeleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "user.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                }
+            }
+        },
         "user.LoginRequest": {
             "type": "object",
             "properties": {
@@ -719,6 +3287,33 @@ const docTemplate = `{
                 }
             }
         },
+        "user.Profile": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Alice"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
         "user.RegisterRequest": {
             "type": "object",
             "properties": {
@@ -735,6 +3330,31 @@ const docTemplate = `{
                     "example": "password123"
                 }
             }
+        },
+        "user.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newpassword123"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Alice"
+                }
+            }
         }
     },
     "securityDefinitions": {
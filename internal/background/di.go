@@ -0,0 +1,18 @@
+package background
+
+import (
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIBackground = "background-group"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIBackground,
+			Build: func(ctn container.Container) (interface{}, error) {
+				return NewGroup(), nil
+			},
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package background
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+// Group запускает фоновые задачи (инвалидация кэша и т.п.) и позволяет
+// дождаться их завершения при остановке сервиса.
+type Group struct {
+	wg sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Go запускает fn в отдельной горутине с таймаутом timeout.
+// Контекст задачи не отменяется вместе с parent (запрос уже может быть завершён),
+// но сохраняет его значения: request_id, логгер, трассировку.
+func (g *Group) Go(parent context.Context, timeout time.Duration, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+		defer cancel()
+		defer func() {
+			if err := recover(); err != nil {
+				logger.FromContext(ctx).Error("background task panicked", "panic", err)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// Every запускает fn каждые interval, пока не отменён ctx. Отмена ctx останавливает только расписание:
+// текущий запуск не прерывается (его контекст ограничен лишь таймаутом interval), и Wait при остановке
+// дожидается его завершения.
+func (g *Group) Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.run(ctx, interval, fn)
+			}
+		}
+	}()
+}
+
+// run выполняет один запуск Every; паника не останавливает расписание.
+func (g *Group) run(parent context.Context, timeout time.Duration, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+	defer cancel()
+	defer func() {
+		if err := recover(); err != nil {
+			logger.FromContext(ctx).Error("periodic task panicked", "panic", err)
+		}
+	}()
+	fn(ctx)
+}
+
+// Wait ждёт завершения всех запущенных задач или отмены ctx.
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
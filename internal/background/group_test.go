@@ -0,0 +1,95 @@
+package background
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_WaitDrainsTasks(t *testing.T) {
+	g := NewGroup()
+	var done atomic.Int32
+
+	parent, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		g.Go(parent, time.Second, func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			if ctx.Err() == nil {
+				done.Add(1)
+			}
+		})
+	}
+	// отмена родителя (конец запроса) не должна прерывать задачи
+	cancel()
+
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if done.Load() != 3 {
+		t.Fatalf("expected 3 finished tasks, got %d", done.Load())
+	}
+}
+
+func TestGroup_WaitTimeout(t *testing.T) {
+	g := NewGroup()
+	release := make(chan struct{})
+	defer close(release)
+	g.Go(context.Background(), time.Second, func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Wait(ctx); err == nil {
+		t.Fatal("expected drain timeout error")
+	}
+}
+
+func TestGroup_Every(t *testing.T) {
+	g := NewGroup()
+	var runs atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.Every(ctx, 5*time.Millisecond, func(ctx context.Context) {
+		if runs.Add(1) == 1 {
+			panic("first run fails")
+		}
+	})
+	time.Sleep(40 * time.Millisecond)
+	cancel()
+
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runs.Load() < 2 {
+		t.Fatalf("expected the schedule to survive a panic, got %d runs", runs.Load())
+	}
+}
+
+func TestGroup_EveryFinishesRunOnStop(t *testing.T) {
+	g := NewGroup()
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.Every(ctx, 40*time.Millisecond, func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(ctx.Err() == nil)
+	})
+	<-started
+	// сигнал остановки приходит посреди запуска: запуск доделывается, новых нет
+	cancel()
+
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("the run in progress must finish with a live context")
+	}
+}
@@ -1,8 +1,16 @@
 package booking
 
 import (
+	"strings"
+
 	"github.com/jmoiron/sqlx"
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/config"
 	"laschool.ru/event-booking-service/internal/db"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/payment"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -26,7 +34,26 @@ func init() {
 			Name: DIBookingService,
 			Build: func(ctn container.Container) (interface{}, error) {
 				repo := ctn.Get(DIBookingRepo).(Repository)
-				return NewService(repo), nil
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				transferURL := cfg.Booking.TransferURL
+				if transferURL == "" {
+					transferURL = strings.TrimRight(cfg.Account.BaseURL, "/") + "/bookings/transfers/accept"
+				}
+				return NewService(repo, Options{
+					Events:             ctn.Get(event.DIEventService).(event.Service),
+					Metrics:            ctn.Get(metrics.DIMetrics).(*metrics.Metrics),
+					TicketSecret:       cfg.JWT.Secret,
+					AttendeeEditCutoff: cfg.Booking.AttendeeEditCutoff,
+					Mailer:             ctn.Get(mailer.DIMailer).(mailer.Mailer),
+					Tasks:              ctn.Get(background.DIBackground).(*background.Group),
+					TransferTTL:        cfg.Booking.TransferTTL,
+					TransferURL:        transferURL,
+					Payments:           ctn.Get(payment.DIProvider).(payment.Provider),
+					PaymentTTL:         cfg.Payment.PendingTTL,
+					Currency:           cfg.Payment.Currency,
+					RefundAttempts:     cfg.Payment.RefundAttempts,
+					RefundBackoff:      cfg.Payment.RefundBackoff,
+				}), nil
 			},
 		})
 	})
@@ -0,0 +1,100 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrBookingSeatsLimit — в одной брони больше мест, чем разрешает событие.
+	ErrBookingSeatsLimit = errors.New("too many seats in one booking")
+	// ErrUserSeatsLimit — у пользователя стало бы больше мест на событие, чем оно разрешает.
+	ErrUserSeatsLimit = errors.New("too many seats for one user")
+	// ErrOneBookingPerUser — у пользователя уже есть действующая бронь на событие.
+	ErrOneBookingPerUser = errors.New("user already has a booking for this event")
+)
+
+// IsLimitError сообщает, что бронь отклонена ограничениями события на одного пользователя.
+func IsLimitError(err error) bool {
+	return errors.Is(err, ErrBookingSeatsLimit) || errors.Is(err, ErrUserSeatsLimit) || errors.Is(err, ErrOneBookingPerUser)
+}
+
+// eventSeats — вместимость, занятые места и ограничения события, прочитанные под его блокировкой.
+type eventSeats struct {
+	Capacity           int   `db:"capacity"`
+	Price              int64 `db:"price"`
+	MaxSeatsPerBooking int   `db:"max_seats_per_booking"`
+	MaxSeatsPerUser    int   `db:"max_seats_per_user"`
+	OneBookingPerUser  bool  `db:"one_booking_per_user"`
+	Used               int   `db:"-"`
+}
+
+// checkLimits проверяет бронь на seats мест пользователя, у которого на событие уже есть
+// bookings других действующих броней на userSeats мест.
+func (e eventSeats) checkLimits(seats, userSeats, bookings int) error {
+	if e.MaxSeatsPerBooking > 0 && seats > e.MaxSeatsPerBooking {
+		return fmt.Errorf("%w: at most %d", ErrBookingSeatsLimit, e.MaxSeatsPerBooking)
+	}
+	if e.OneBookingPerUser && bookings > 0 {
+		return ErrOneBookingPerUser
+	}
+	if e.MaxSeatsPerUser > 0 && userSeats+seats > e.MaxSeatsPerUser {
+		return fmt.Errorf("%w: at most %d, already booked %d", ErrUserSeatsLimit, e.MaxSeatsPerUser, userSeats)
+	}
+	return nil
+}
+
+// lockEventSeats блокирует строку события до конца транзакции и возвращает его вместимость,
+// ограничения и занятые места. Через неё проходят все операции, занимающие места, поэтому проверки
+// вместимости и ограничений не гоняются друг с другом.
+func lockEventSeats(ctx context.Context, tx *sqlx.Tx, eventID int64) (eventSeats, error) {
+	var e eventSeats
+	const lock = `SELECT capacity, price, max_seats_per_booking, max_seats_per_user, one_booking_per_user
+		FROM events WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &e, lock, eventID); err != nil {
+		return e, fmt.Errorf("lock event %d: %w", eventID, err)
+	}
+	const q = `SELECT COALESCE(SUM(seats),0) FROM bookings WHERE event_id = $1 AND status IN ` + occupiedStatusesSQL
+	if err := tx.GetContext(ctx, &e.Used, q, eventID); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// userUsage считает места и действующие брони пользователя на событие, не считая брони excludeID.
+// Вызывается под блокировкой события.
+func userUsage(ctx context.Context, tx *sqlx.Tx, eventID, userID, excludeID int64) (seats, bookings int, err error) {
+	const q = `SELECT COALESCE(SUM(seats),0) AS seats, COUNT(*) AS bookings FROM bookings
+		WHERE event_id = $1 AND user_id = $2 AND id <> $3 AND status IN ` + occupiedStatusesSQL
+	var u struct {
+		Seats    int `db:"seats"`
+		Bookings int `db:"bookings"`
+	}
+	if err := tx.GetContext(ctx, &u, q, eventID, userID, excludeID); err != nil {
+		return 0, 0, err
+	}
+	return u.Seats, u.Bookings, nil
+}
+
+// limitConstraints — ограничения триггера bookings_check_limits и соответствующие им ошибки
+var limitConstraints = map[string]error{
+	"bookings_max_seats_per_booking": ErrBookingSeatsLimit,
+	"bookings_max_seats_per_user":    ErrUserSeatsLimit,
+	"bookings_one_per_user":          ErrOneBookingPerUser,
+}
+
+// limitError переводит срабатывание триггера ограничений в ошибку сервиса. Сюда попадают только
+// записи, проскочившие мимо проверки в коде, поэтому остальные ошибки возвращаются как есть.
+func limitError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+		if limitErr, ok := limitConstraints[pgErr.ConstraintName]; ok {
+			return limitErr
+		}
+	}
+	return err
+}
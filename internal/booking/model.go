@@ -3,12 +3,29 @@ package booking
 import "time"
 
 type Booking struct {
-	ID        int64     `db:"id" json:"id"`
-	EventID   int64     `db:"event_id" json:"event_id"`
+	ID      int64 `db:"id" json:"id"`
+	EventID int64 `db:"event_id" json:"event_id"`
+	// UserID == 0 — аккаунт владельца удалён, бронь обезличена
 	UserID    int64     `db:"user_id" json:"user_id"`
 	Seats     int       `db:"seats" json:"seats"`
-	Status    string    `db:"status" json:"status"`
+	Status    Status    `db:"status" json:"status"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// CheckedInSeats — сколько мест уже прошло на входе
+	CheckedInSeats int `db:"checked_in_seats" json:"checked_in_seats"`
+	// TicketVersion входит в подпись билета и растёт при передаче брони, чтобы старые QR-коды не действовали
+	TicketVersion int `db:"ticket_version" json:"-"`
+	// ExpiresAt — до какого момента неоплаченная бронь платного события держит места
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	// PromoCodeID и Discount — применённый промокод и скидка в копейках
+	PromoCodeID *int64 `db:"promo_code_id" json:"promo_code_id,omitempty"`
+	Discount    int64  `db:"discount" json:"discount,omitempty"`
+	// Attendees — поимённые посетители, передаются только при создании брони
+	Attendees []BookingAttendee `db:"-" json:"attendees,omitempty"`
+	// PromoCode — промокод, передаётся только при создании брони
+	PromoCode string `db:"-" json:"-"`
+	// LegacyPaid — бронь без платежа оформлена до появления оплаты и оплачивалась вне сервиса по цене события.
+	// Заполняется только в Repository.Cancel
+	LegacyPaid bool `db:"-" json:"-"`
 }
 
 // CreateBookingRequest модель запроса на создание бронирования
@@ -16,4 +33,124 @@ type CreateBookingRequest struct {
 	EventID int64 `json:"event_id" example:"1"`
 	UserID  int64 `json:"user_id" example:"1"`
 	Seats   int   `json:"seats" example:"2"`
+	// Attendees — необязательный список посетителей, не больше одного на место
+	Attendees []AttendeeInput `json:"attendees,omitempty"`
+	// PromoCode — необязательный промокод на скидку, только для платных событий
+	PromoCode string `json:"promo_code,omitempty" example:"SPRING25"`
+}
+
+// AttendeeInput — имя и email посетителя на одно место.
+type AttendeeInput struct {
+	Name  string `json:"name" example:"Мария Иванова"`
+	Email string `json:"email" example:"maria@example.com"`
+}
+
+// UpdateAttendeesRequest модель запроса на замену списка посетителей брони
+type UpdateAttendeesRequest struct {
+	Attendees []AttendeeInput `json:"attendees"`
+}
+
+// BookingAttendee — поимённый посетитель на одном из мест брони.
+type BookingAttendee struct {
+	ID        int64  `db:"id" json:"id" example:"1"`
+	BookingID int64  `db:"booking_id" json:"booking_id" example:"1"`
+	EventID   int64  `db:"event_id" json:"event_id" example:"1"`
+	Name      string `db:"name" json:"name" example:"Мария Иванова"`
+	Email     string `db:"email" json:"email" example:"maria@example.com"`
+	// UserID — аккаунт, закрепивший место за собой по email; 0 — место не закреплено
+	UserID      int64      `db:"user_id" json:"user_id" example:"0"`
+	CheckedInAt *time.Time `db:"checked_in_at" json:"checked_in_at,omitempty"`
+}
+
+// Attendee — строка списка посетителей события для организатора
+type Attendee struct {
+	BookingID      int64     `db:"booking_id"`
+	UserID         int64     `db:"user_id"`
+	Name           string    `db:"name"`
+	Email          string    `db:"email"`
+	Seats          int       `db:"seats"`
+	CheckedInSeats int       `db:"checked_in_seats"`
+	Status         Status    `db:"status"`
+	BookedAt       time.Time `db:"booked_at"`
+}
+
+// CheckInRequest модель запроса на отметку посетителя по билету
+type CheckInRequest struct {
+	// Code — содержимое QR-кода билета
+	Code string `json:"code" example:"eyJiIjoxLCJlIjoxLCJzIjoyfQ.c2lnbmF0dXJl"`
+	// Seats — сколько мест пропустить; 0 — все оставшиеся
+	Seats int `json:"seats" example:"1"`
+}
+
+// CheckIn — результат отметки на входе
+type CheckIn struct {
+	BookingID int64 `json:"booking_id" example:"1"`
+	EventID   int64 `json:"event_id" example:"1"`
+	// AttendeeID — посетитель, отмеченный по своему билету
+	AttendeeID     int64  `json:"attendee_id,omitempty" example:"0"`
+	Seats          int    `json:"seats" example:"3"`
+	Admitted       int    `json:"admitted" example:"1"`
+	CheckedInSeats int    `json:"checked_in_seats" example:"2"`
+	Remaining      int    `json:"remaining" example:"1"`
+	Status         Status `json:"status" example:"confirmed"`
+}
+
+// UpdateBookingRequest модель запроса на изменение брони
+type UpdateBookingRequest struct {
+	Seats int `json:"seats" example:"3"`
+}
+
+// Фильтры списка бронирований пользователя
+const (
+	FilterConfirmed = "confirmed"
+	FilterCancelled = "cancelled"
+	// FilterUpcoming — действующие брони на ещё не начавшиеся события
+	FilterUpcoming = "upcoming"
+	// FilterPast — брони на уже закончившиеся события
+	FilterPast = "past"
+)
+
+// ListFilter — параметры списка бронирований пользователя; пустой Status — все брони.
+type ListFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// EventSummary — краткие данные события для списков бронирований.
+type EventSummary struct {
+	ID       int64     `db:"id" json:"id"`
+	Title    string    `db:"title" json:"title"`
+	StartsAt time.Time `db:"starts_at" json:"starts_at"`
+	Location string    `db:"location" json:"location"`
+}
+
+// UserBooking — бронь пользователя вместе с кратким описанием события.
+type UserBooking struct {
+	Booking
+	Event EventSummary `db:"event" json:"event"`
+}
+
+// Cancellation — итог отмены брони по политике события. Суммы в копейках.
+type Cancellation struct {
+	BookingID     int64 `json:"booking_id" example:"1"`
+	Amount        int64 `json:"amount" example:"300000"`
+	RefundPercent int   `json:"refund_percent" example:"50"`
+	Refund        int64 `json:"refund" example:"150000"`
+	Fee           int64 `json:"fee" example:"150000"`
+	// RefundID и RefundStatus — возврат через провайдера платежей; пусто, если возвращать нечего
+	// или бронь оплачивалась не через сервис
+	RefundID     int64        `json:"refund_id,omitempty" example:"1"`
+	RefundStatus RefundStatus `json:"refund_status,omitempty" example:"requested"`
+}
+
+func newCancellation(bookingID, amount int64, refundPercent int) *Cancellation {
+	refund := amount * int64(refundPercent) / 100
+	return &Cancellation{
+		BookingID:     bookingID,
+		Amount:        amount,
+		RefundPercent: refundPercent,
+		Refund:        refund,
+		Fee:           amount - refund,
+	}
 }
@@ -0,0 +1,111 @@
+package booking
+
+import (
+	"errors"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/payment"
+)
+
+var (
+	// ErrPaymentNotFound — уведомление о платеже, которого сервис не создавал.
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrPaymentExpired — бронь не оплатили вовремя, места уже освобождены.
+	ErrPaymentExpired = errors.New("booking payment window has expired")
+	// ErrPaymentsDisabled — провайдер платежей не настроен.
+	ErrPaymentsDisabled = errors.New("payments are not configured")
+	// ErrFreeEvent — оплата нужна только платным событиям.
+	ErrFreeEvent = errors.New("event is free")
+	// ErrPaymentRequired — бронь платного события создаётся только через оплату.
+	ErrPaymentRequired = errors.New("event is paid, booking requires payment")
+	// ErrPaidSeatsChange — сумма платной брони и скидка промокода посчитаны на исходные места:
+	// чтобы изменить их, бронь отменяют и оформляют заново.
+	ErrPaidSeatsChange = errors.New("seats of a paid booking cannot be changed")
+	// ErrPaymentUnavailable — провайдер не создал платёж; бронь отменена.
+	ErrPaymentUnavailable = errors.New("payment provider is unavailable")
+)
+
+// PaymentStatus — состояние оплаты брони у провайдера.
+type PaymentStatus string
+
+const (
+	// PaymentPending — платёж создан, клиент ещё не оплатил
+	PaymentPending PaymentStatus = "pending"
+	// PaymentAuthorized — деньги заблокированы, но ещё не списаны
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentFailed     PaymentStatus = "failed"
+	// PaymentCancelled — бронь истекла или отменена до оплаты
+	PaymentCancelled PaymentStatus = "cancelled"
+)
+
+// Payment — платёж за бронь у провайдера.
+type Payment struct {
+	ID        int64         `db:"id" json:"id"`
+	BookingID int64         `db:"booking_id" json:"booking_id"`
+	Provider  string        `db:"provider" json:"provider"`
+	IntentID  string        `db:"intent_id" json:"intent_id"`
+	Amount    int64         `db:"amount" json:"amount"`
+	Currency  string        `db:"currency" json:"currency"`
+	Status    PaymentStatus `db:"status" json:"status"`
+	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// paymentColumns — поля Payment
+const paymentColumns = `id, booking_id, provider, intent_id, amount, currency, status, created_at, updated_at`
+
+// Checkout — бронь платного события, ожидающая оплаты
+type Checkout struct {
+	BookingID int64  `json:"id" example:"1"`
+	Status    Status `json:"status" example:"pending"`
+	// ExpiresAt — до какого момента бронь держит места без оплаты
+	ExpiresAt time.Time `json:"expires_at"`
+	// Amount — сумма к оплате со скидкой; 0 — промокод покрыл всё, бронь подтверждена без оплаты
+	Amount int64 `json:"amount" example:"225000"`
+	// Discount — скидка по промокоду
+	Discount int64  `json:"discount,omitempty" example:"75000"`
+	Currency string `json:"currency" example:"rub"`
+	Provider string `json:"provider,omitempty" example:"stripe"`
+	// ClientSecret передаётся платёжной форме провайдера на клиенте
+	ClientSecret string `json:"client_secret,omitempty" example:"pi_123_secret_456"`
+}
+
+// paymentOutcome решает по уведомлению типа eventType, что станет с бронью b и её платежом p.
+// Пустой статус брони — бронь не меняется. Повторные и запоздавшие уведомления ничего не портят.
+func paymentOutcome(b *Booking, p *Payment, eventType string, now time.Time) (Status, PaymentStatus) {
+	switch eventType {
+	case payment.EventAuthorized, payment.EventSucceeded:
+		next := PaymentAuthorized
+		if eventType == payment.EventSucceeded {
+			next = PaymentCaptured
+		}
+		switch {
+		case p.Status == PaymentCaptured || p.Status == next:
+			return "", p.Status
+		case b.Status == StatusPending && (b.ExpiresAt == nil || now.Before(*b.ExpiresAt)):
+			return StatusConfirmed, next
+		case b.Status == StatusPending:
+			// оплата пришла после срока, но раньше фоновой отмены брони
+			return StatusCancelled, PaymentCancelled
+		case (b.Status == StatusCancelled || b.Status == StatusRefunded) && p.Status != PaymentAuthorized:
+			// запоздавшее списание по отменённой брони уже ушло в возврат
+			return "", PaymentCancelled
+		default:
+			return "", next
+		}
+	case payment.EventFailed:
+		// бронь ждёт повторной попытки до своего срока
+		if p.Status == PaymentPending {
+			return "", PaymentFailed
+		}
+	case payment.EventCancelled:
+		if b.Status == StatusPending {
+			return StatusCancelled, PaymentCancelled
+		}
+		if p.Status == PaymentPending || p.Status == PaymentFailed {
+			return "", PaymentCancelled
+		}
+	}
+	return "", p.Status
+}
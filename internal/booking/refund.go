@@ -0,0 +1,63 @@
+package booking
+
+import (
+	"time"
+)
+
+// RefundStatus — состояние возврата оплаты брони.
+type RefundStatus string
+
+const (
+	// RefundRequested — возврат записан и ещё не подтверждён провайдером
+	RefundRequested RefundStatus = "requested"
+	RefundSucceeded RefundStatus = "succeeded"
+	// RefundFailed — провайдер отказал или закончились попытки; возвращать нужно вручную
+	RefundFailed RefundStatus = "failed"
+)
+
+// Refund — возврат оплаты отменённой брони.
+type Refund struct {
+	ID        int64 `db:"id" json:"id" example:"1"`
+	BookingID int64 `db:"booking_id" json:"booking_id" example:"1"`
+	EventID   int64 `db:"event_id" json:"event_id" example:"1"`
+	// UserID — владелец брони; 0 — аккаунт удалён
+	UserID    int64        `db:"user_id" json:"user_id" example:"1"`
+	PaymentID int64        `db:"payment_id" json:"payment_id" example:"1"`
+	IntentID  string       `db:"intent_id" json:"-"`
+	Amount    int64        `db:"amount" json:"amount" example:"150000"`
+	Currency  string       `db:"currency" json:"currency" example:"rub"`
+	Status    RefundStatus `db:"status" json:"status" example:"succeeded"`
+	// ProviderRefundID — идентификатор возврата у провайдера
+	ProviderRefundID string `db:"provider_refund_id" json:"provider_refund_id,omitempty" example:"re_123"`
+	Attempts         int    `db:"attempts" json:"attempts" example:"1"`
+	// LastError — ошибка последней неудачной попытки
+	LastError string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// refundColumns — поля Refund для выборок из booking_refunds r JOIN bookings b JOIN booking_payments p
+const refundColumns = `r.id, r.booking_id, b.event_id, COALESCE(b.user_id, 0) AS user_id, r.payment_id, p.intent_id,
+	r.amount, p.currency, r.status, COALESCE(r.provider_refund_id, '') AS provider_refund_id, r.attempts,
+	COALESCE(r.last_error, '') AS last_error, r.created_at, r.updated_at`
+
+// refundRetryAfter — через сколько без изменений незавершённый возврат подбирает фоновый повтор
+// (процесс, который его вёл, мог остановиться)
+const refundRetryAfter = 5 * time.Minute
+
+// paidAmount — сколько клиент заплатил за бронь b. Без платежа (бесплатные события, бесплатные брони
+// из импорта, полностью покрытые промокодом) клиент ничего не заплатил; исключение — брони, оформленные
+// до появления оплаты: их сумма считается по цене события за вычетом скидки. По несписанному платежу
+// клиент тоже ничего не заплатил.
+func paidAmount(b *Booking, p *Payment, price int64) int64 {
+	if p == nil {
+		if !b.LegacyPaid {
+			return 0
+		}
+		return max(price*int64(b.Seats)-b.Discount, 0)
+	}
+	if p.Status == PaymentCaptured {
+		return p.Amount
+	}
+	return 0
+}
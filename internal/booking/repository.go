@@ -2,18 +2,97 @@ package booking
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"laschool.ru/event-booking-service/internal/promo"
 )
 
+var tracer = otel.Tracer("laschool.ru/event-booking-service/internal/booking")
+
 type Repository interface {
+	// Create проверяет вместимость под блокировкой события и создаёт бронь в статусе b.Status
+	// (по умолчанию confirmed). Промокод b.PromoCode засчитывается той же транзакцией, скидка пишется в b.Discount
 	Create(ctx context.Context, b *Booking) (int64, error)
+	// CreateBatch создаёт брони одной транзакцией под блокировками их событий. Каждая бронь
+	// вставляется в своей точке сохранения, и её ошибка попадает в errs по тому же индексу.
+	// opts.Atomic — при ошибке любой брони откатывается вся транзакция. Без opts.Complimentary
+	// брони платных событий и пользователей без подтверждённого email отклоняются
+	CreateBatch(ctx context.Context, bookings []*Booking, actorID int64, opts BatchOptions) (errs []error, err error)
+	// UpdateSeats меняет количество мест под той же блокировкой и пишет изменение в историю.
+	// Места платной брони и брони с промокодом не меняются (ErrPaidSeatsChange)
+	UpdateSeats(ctx context.Context, id int64, seats int, actorID int64) (*Booking, error)
 	GetByID(ctx context.Context, id int64) (*Booking, error)
 	ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]Booking, error)
-	Cancel(ctx context.Context, id int64) error
-	CountConfirmedSeats(ctx context.Context, eventID int64) (int, error)
+	ListByUser(ctx context.Context, userID int64, f ListFilter) ([]UserBooking, error)
+	// Transition блокирует бронь, проверяет переход через check, меняет статус и пишет его в историю
+	Transition(ctx context.Context, id int64, to Status, actorID int64, reason string, check func(b *Booking) error) (*Booking, error)
+	// CheckIn блокирует бронь, через check узнаёт, сколько мест пропустить, записывает отметку
+	// и переводит бронь в checked_in, когда прошли все места. attendeeID != 0 — отметка по билету
+	// посетителя: он блокируется вместе с бронью и помечается прошедшим
+	CheckIn(ctx context.Context, id, attendeeID, staffID int64, check func(b *Booking, a *BookingAttendee) (int, error)) (*Booking, error)
+	// ListAttendees возвращает посетителей брони в порядке добавления
+	ListAttendees(ctx context.Context, bookingID int64) ([]BookingAttendee, error)
+	// ReplaceAttendees блокирует бронь, проверяет замену через check и приводит посетителей к list.
+	// Посетители с тем же email сохраняют ID (а значит, и билет) и закрепление за аккаунтом
+	ReplaceAttendees(ctx context.Context, bookingID int64, list []BookingAttendee, check func(b *Booking, current []BookingAttendee) error) ([]BookingAttendee, error)
+	// ClaimAttendees закрепляет за пользователем незакреплённые места действующих броней с его подтверждённым email
+	ClaimAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error)
+	// ListAttendeesByUser возвращает места действующих броней, закреплённые за пользователем
+	ListAttendeesByUser(ctx context.Context, userID int64) ([]BookingAttendee, error)
+	// CreateTransfer блокирует бронь, проверяет её через check, заменяет ожидающую передачу новой t
+	// и проставляет ей ID. Передача владельцу самому себе — ErrTransferToSelf
+	CreateTransfer(ctx context.Context, t *Transfer, check func(b *Booking) error) error
+	// GetTransferByHash ищет передачу по хэшу токена
+	GetTransferByHash(ctx context.Context, hash string) (*Transfer, error)
+	// AcceptTransfer блокирует бронь и передачу, проверяет их через check и переоформляет бронь на userID:
+	// меняет владельца, увеличивает версию билета и пишет передачу в историю
+	AcceptTransfer(ctx context.Context, transferID, userID int64, check func(b *Booking, t *Transfer) error) (*Booking, error)
+	// EachAttendee построчно читает действующие брони события с данными пользователей и передаёт их в fn
+	EachAttendee(ctx context.Context, eventID int64, fn func(a *Attendee) error) error
+	// CountOccupiedSeats считает места, занятые бронями события
+	CountOccupiedSeats(ctx context.Context, eventID int64) (int, error)
+	// CreatePayment сохраняет платёж за бронь и проставляет ему ID
+	CreatePayment(ctx context.Context, p *Payment) error
+	// UpdatePayment блокирует бронь и её платёж провайдера provider, через check узнаёт новые статусы
+	// брони (пусто — без изменений) и платежа и записывает их. Смена статуса брони пишется в историю
+	UpdatePayment(ctx context.Context, provider, intentID string, check func(b *Booking, p *Payment) (Status, PaymentStatus, error)) (*Booking, *Payment, error)
+	// ExpirePending отменяет неоплаченные брони со сроком до now вместе с их платежами.
+	// Возвращает отменённые брони и платежи, которые нужно отменить и у провайдера
+	ExpirePending(ctx context.Context, now time.Time) ([]Booking, []Payment, error)
+	// Cancel блокирует бронь и её платёж (nil — платежа нет, тогда заполняется Booking.LegacyPaid),
+	// через check узнаёт сумму возврата, отменяет
+	// бронь и пишет отмену в историю. Возврат по списанному платежу записывается в статусе requested
+	// той же транзакцией; несписанный платёж отменяется
+	Cancel(ctx context.Context, id, actorID int64, reason string, check func(b *Booking, p *Payment) (int64, error)) (*Booking, *Refund, error)
+	// SaveRefundAttempt записывает попытку незавершённого возврата. Успешный возврат переводит бронь в refunded.
+	// Завершённый возврат не меняется и возвращается как есть
+	SaveRefundAttempt(ctx context.Context, id int64, status RefundStatus, providerRefundID, lastError string) (*Refund, error)
+	// ListRefundsByEvent возвращает возвраты броней события, новые первыми
+	ListRefundsByEvent(ctx context.Context, eventID int64) ([]Refund, error)
+	// ListStaleRefunds возвращает незавершённые возвраты, которые не менялись с before
+	ListStaleRefunds(ctx context.Context, before time.Time) ([]Refund, error)
+	// RequestRefund записывает возврат amount по платежу paymentID брони bookingID в статусе requested.
+	// Если у брони уже есть возврат, возвращает его
+	RequestRefund(ctx context.Context, bookingID, paymentID, amount int64) (*Refund, error)
 }
 
+// bookingColumns — поля Booking; user_id пустой у броней удалённых аккаунтов
+const bookingColumns = `id, event_id, COALESCE(user_id, 0) AS user_id, seats, checked_in_seats, status, ticket_version, expires_at, promo_code_id, discount, created_at`
+
+// transferColumns — поля Transfer; пользователи могли удалить аккаунты
+const transferColumns = `id, booking_id, COALESCE(from_user_id, 0) AS from_user_id, to_email,
+	COALESCE(to_user_id, 0) AS to_user_id, status, expires_at, created_at, accepted_at, token_hash`
+
+// attendeeColumns — поля BookingAttendee для выборок из booking_attendees a JOIN bookings b
+const attendeeColumns = `a.id, a.booking_id, b.event_id, a.name, a.email, COALESCE(a.user_id, 0) AS user_id, a.checked_in_at`
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -23,25 +102,266 @@ func NewRepository(db *sqlx.DB) Repository {
 }
 
 func (r *repository) Create(ctx context.Context, b *Booking) (int64, error) {
-	const q = `INSERT INTO bookings (event_id, user_id, seats, status) VALUES ($1,$2,$3,'confirmed') RETURNING id`
+	ctx, span := tracer.Start(ctx, "booking.repository.Create")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	e, err := lockEventSeats(ctx, tx, b.EventID)
+	if err != nil {
+		return 0, err
+	}
+	if e.Used+b.Seats > e.Capacity {
+		return 0, ErrNotEnoughSeats
+	}
+	userSeats, userBookings, err := userUsage(ctx, tx, b.EventID, b.UserID, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.checkLimits(b.Seats, userSeats, userBookings); err != nil {
+		return 0, err
+	}
+	// код засчитывается в той же транзакции: если бронь не создастся, использование откатится
+	if b.PromoCode != "" {
+		code, discount, err := promo.Redeem(ctx, tx, b.PromoCode, b.EventID, e.Price*int64(b.Seats), time.Now())
+		if err != nil {
+			return 0, err
+		}
+		b.PromoCodeID, b.Discount = &code.ID, discount
+	}
+
+	status := b.Status
+	if status == "" {
+		status = StatusConfirmed
+	}
+	// вставка проходит только для пользователя с подтверждённым email
+	const q = `INSERT INTO bookings (event_id, user_id, seats, status, expires_at, promo_code_id, discount)
+		SELECT $1, id, $3, $4, $5, $6, $7 FROM users WHERE id = $2 AND email_verified
+		RETURNING id`
 	var id int64
-	if err := r.db.QueryRowxContext(ctx, q, b.EventID, b.UserID, b.Seats).Scan(&id); err != nil {
+	if err := tx.QueryRowxContext(ctx, q, b.EventID, b.UserID, b.Seats, status, b.ExpiresAt, b.PromoCodeID, b.Discount).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrEmailNotVerified
+		}
+		return 0, limitError(err)
+	}
+	if err := insertHistory(ctx, tx, history{BookingID: id, To: status, SeatsTo: b.Seats, ActorID: b.UserID, Reason: "created"}); err != nil {
+		return 0, err
+	}
+	for i := range b.Attendees {
+		if err := insertAttendee(ctx, tx, id, &b.Attendees[i]); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+func (r *repository) CreateBatch(ctx context.Context, bookings []*Booking, actorID int64, opts BatchOptions) ([]error, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.CreateBatch")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// события блокируются по возрастанию ID, чтобы параллельные пакеты не ловили взаимоблокировку
+	var eventIDs []int64
+	for _, b := range bookings {
+		eventIDs = append(eventIDs, b.EventID)
+	}
+	slices.Sort(eventIDs)
+	events := make(map[int64]*eventSeats)
+	for _, id := range slices.Compact(eventIDs) {
+		e, err := lockEventSeats(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		events[id] = &e
+	}
+
+	errs := make([]error, len(bookings))
+	failed := false
+	// без подтверждённого email вставка проходит только для бесплатных броней от администратора
+	const ins = `INSERT INTO bookings (event_id, user_id, seats, status)
+		SELECT $1, id, $3, 'confirmed' FROM users WHERE id = $2 AND ($4 OR email_verified)
+		RETURNING id`
+	reason := "imported"
+	if opts.Complimentary {
+		reason = "imported complimentary"
+	}
+	for i, b := range bookings {
+		e := events[b.EventID]
+		if e.Price > 0 && !opts.Complimentary {
+			errs[i], failed = ErrPaymentRequired, true
+			continue
+		}
+		if e.Used+b.Seats > e.Capacity {
+			errs[i], failed = ErrNotEnoughSeats, true
+			continue
+		}
+		// брони, созданные раньше в этом пакете, уже видны запросу в той же транзакции
+		userSeats, userBookings, err := userUsage(ctx, tx, b.EventID, b.UserID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.checkLimits(b.Seats, userSeats, userBookings); err != nil {
+			errs[i], failed = err, true
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_row`); err != nil {
+			return nil, err
+		}
+		var id int64
+		err = tx.QueryRowxContext(ctx, ins, b.EventID, b.UserID, b.Seats, opts.Complimentary).Scan(&id)
+		if err == nil {
+			err = insertHistory(ctx, tx, history{BookingID: id, To: StatusConfirmed, SeatsTo: b.Seats, ActorID: actorID, Reason: reason})
+		}
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_row`); rbErr != nil {
+				return nil, rbErr
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				errs[i], failed = ErrEmailNotVerified, true
+			} else {
+				errs[i], failed = limitError(err), true
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_row`); err != nil {
+			return nil, err
+		}
+		e.Used += b.Seats
+		b.ID, b.Status = id, StatusConfirmed
+	}
+
+	if opts.Atomic && failed {
+		for _, b := range bookings {
+			b.ID = 0
+		}
+		return errs, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+func (r *repository) UpdateSeats(ctx context.Context, id int64, seats int, actorID int64) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.UpdateSeats")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var eventID int64
+	if err := tx.GetContext(ctx, &eventID, `SELECT event_id FROM bookings WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	// порядок блокировок как в Create: сначала событие, потом бронь
+	e, err := lockEventSeats(ctx, tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, id); err != nil {
+		return nil, err
+	}
+	// нельзя оставить меньше мест, чем уже прошло на входе
+	if b.Status != StatusConfirmed || seats < b.CheckedInSeats {
+		return nil, ErrNotModifiable
+	}
+	// цену и промокод проверяем ещё раз под блокировкой: сервис видел их до транзакции
+	if e.Price > 0 || b.PromoCodeID != nil {
+		return nil, ErrPaidSeatsChange
+	}
+	var attendees int
+	if err := tx.GetContext(ctx, &attendees, `SELECT COUNT(*) FROM booking_attendees WHERE booking_id = $1`, id); err != nil {
+		return nil, err
+	}
+	if seats < attendees {
+		return nil, ErrTooManyAttendees
+	}
+	// уменьшение освобождает места сразу: они перестают учитываться в used для следующих броней
+	if seats > b.Seats && e.Used-b.Seats+seats > e.Capacity {
+		return nil, ErrNotEnoughSeats
+	}
+	// ограничения проверяются только при увеличении: брони, оформленные до их появления, можно уменьшать.
+	// Сама бронь уже есть у пользователя, поэтому правило одной брони её не касается
+	if seats > b.Seats {
+		userSeats, _, err := userUsage(ctx, tx, b.EventID, b.UserID, b.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.checkLimits(seats, userSeats, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE bookings SET seats = $2 WHERE id = $1`, id, seats); err != nil {
+		return nil, limitError(err)
+	}
+	h := history{BookingID: id, From: b.Status, To: b.Status, SeatsFrom: b.Seats, SeatsTo: seats, ActorID: actorID, Reason: "seats changed"}
+	if err := insertHistory(ctx, tx, h); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	b.Seats = seats
+	return &b, nil
+}
+
+// history — запись booking_status_history. Пустой From — бронь только что создана.
+type history struct {
+	BookingID int64
+	From      Status
+	To        Status
+	SeatsFrom int
+	SeatsTo   int
+	ActorID   int64
+	Reason    string
+}
+
+func insertHistory(ctx context.Context, tx *sqlx.Tx, h history) error {
+	const q = `INSERT INTO booking_status_history
+		(booking_id, from_status, to_status, seats_from, seats_to, actor_id, reason)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, 0), $5, NULLIF($6, 0), $7)`
+	if _, err := tx.ExecContext(ctx, q, h.BookingID, h.From, h.To, h.SeatsFrom, h.SeatsTo, h.ActorID, h.Reason); err != nil {
+		return fmt.Errorf("insert booking history: %w", err)
+	}
+	return nil
+}
+
 func (r *repository) GetByID(ctx context.Context, id int64) (*Booking, error) {
-	const q = `SELECT id, event_id, user_id, seats, status, created_at FROM bookings WHERE id=$1`
+	const q = `SELECT ` + bookingColumns + ` FROM bookings WHERE id=$1`
 	var b Booking
 	if err := r.db.GetContext(ctx, &b, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 	return &b, nil
 }
 
 func (r *repository) ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]Booking, error) {
-	const q = `SELECT id, event_id, user_id, seats, status, created_at FROM bookings WHERE event_id=$1 ORDER BY id DESC LIMIT $2 OFFSET $3`
+	const q = `SELECT ` + bookingColumns + ` FROM bookings WHERE event_id=$1 ORDER BY id DESC LIMIT $2 OFFSET $3`
 	var list []Booking
 	if err := r.db.SelectContext(ctx, &list, q, eventID, limit, offset); err != nil {
 		return nil, err
@@ -49,17 +369,717 @@ func (r *repository) ListByEvent(ctx context.Context, eventID int64, limit, offs
 	return list, nil
 }
 
-func (r *repository) Cancel(ctx context.Context, id int64) error {
-	const q = `UPDATE bookings SET status='cancelled' WHERE id=$1`
-	_, err := r.db.ExecContext(ctx, q, id)
-	return err
+// listByUserFilters — условие и порядок сортировки для каждого фильтра ListByUser
+var listByUserFilters = map[string]struct{ where, order string }{
+	"":              {"TRUE", "b.id DESC"},
+	FilterConfirmed: {"b.status = 'confirmed'", "b.id DESC"},
+	FilterCancelled: {"b.status = 'cancelled'", "b.id DESC"},
+	FilterUpcoming:  {"b.status IN ('pending','confirmed') AND e.starts_at > NOW()", "e.starts_at ASC, b.id ASC"},
+	FilterPast:      {"e.ends_at <= NOW()", "e.starts_at DESC, b.id DESC"},
 }
 
-func (r *repository) CountConfirmedSeats(ctx context.Context, eventID int64) (int, error) {
-	const q = `SELECT COALESCE(SUM(seats),0) FROM bookings WHERE event_id=$1 AND status='confirmed'`
+func (r *repository) ListByUser(ctx context.Context, userID int64, f ListFilter) ([]UserBooking, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.ListByUser")
+	defer span.End()
+	filter, ok := listByUserFilters[f.Status]
+	if !ok {
+		return nil, ErrInvalidFilter
+	}
+	// событие подтягивается join-ом, чтобы не ходить за каждым отдельно
+	q := `SELECT b.id, b.event_id, b.user_id, b.seats, b.checked_in_seats, b.status, b.created_at,
+			e.id AS "event.id", e.title AS "event.title", e.starts_at AS "event.starts_at", e.location AS "event.location"
+		FROM bookings b
+		JOIN events e ON e.id = b.event_id
+		WHERE b.user_id = $1 AND ` + filter.where + `
+		ORDER BY ` + filter.order + `
+		LIMIT $2 OFFSET $3`
+	list := []UserBooking{}
+	if err := r.db.SelectContext(ctx, &list, q, userID, f.Limit, f.Offset); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) Transition(ctx context.Context, id int64, to Status, actorID int64, reason string, check func(b *Booking) error) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.Transition")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := check(&b); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE bookings SET status = $2 WHERE id = $1`, id, to); err != nil {
+		return nil, err
+	}
+	h := history{BookingID: id, From: b.Status, To: to, SeatsFrom: b.Seats, SeatsTo: b.Seats, ActorID: actorID, Reason: reason}
+	if err := insertHistory(ctx, tx, h); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	b.Status = to
+	return &b, nil
+}
+
+func (r *repository) CheckIn(ctx context.Context, id, attendeeID, staffID int64, check func(b *Booking, a *BookingAttendee) (int, error)) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.CheckIn")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// блокировка брони не даёт двум сканерам пропустить одни и те же места
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var a *BookingAttendee
+	if attendeeID != 0 {
+		a = &BookingAttendee{}
+		const selA = `SELECT ` + attendeeColumns + ` FROM booking_attendees a JOIN bookings b ON b.id = a.booking_id
+			WHERE a.id = $1 AND a.booking_id = $2 FOR UPDATE OF a`
+		if err := tx.GetContext(ctx, a, selA, attendeeID, id); err != nil {
+			// посетителя убрали из брони — его билет больше не действует
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrInvalidTicket
+			}
+			return nil, err
+		}
+	}
+	seats, err := check(&b, a)
+	if err != nil {
+		return nil, err
+	}
+
+	from := b.Status
+	b.CheckedInSeats += seats
+	if b.CheckedInSeats == b.Seats {
+		b.Status = StatusCheckedIn
+	}
+	const upd = `UPDATE bookings SET checked_in_seats = $2, status = $3 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, upd, id, b.CheckedInSeats, b.Status); err != nil {
+		return nil, err
+	}
+	if a != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE booking_attendees SET checked_in_at = NOW() WHERE id = $1`, a.ID); err != nil {
+			return nil, err
+		}
+	}
+	const ins = `INSERT INTO booking_checkins (booking_id, seats, staff_id, attendee_id) VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0))`
+	if _, err := tx.ExecContext(ctx, ins, id, seats, staffID, attendeeID); err != nil {
+		return nil, err
+	}
+	if b.Status != from {
+		h := history{BookingID: id, From: from, To: b.Status, SeatsFrom: b.Seats, SeatsTo: b.Seats, ActorID: staffID, Reason: "checked in"}
+		if err := insertHistory(ctx, tx, h); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *repository) ListAttendees(ctx context.Context, bookingID int64) ([]BookingAttendee, error) {
+	const q = `SELECT ` + attendeeColumns + ` FROM booking_attendees a JOIN bookings b ON b.id = a.booking_id
+		WHERE a.booking_id = $1 ORDER BY a.id`
+	list := []BookingAttendee{}
+	if err := r.db.SelectContext(ctx, &list, q, bookingID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) ReplaceAttendees(ctx context.Context, bookingID int64, list []BookingAttendee, check func(b *Booking, current []BookingAttendee) error) ([]BookingAttendee, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.ReplaceAttendees")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, bookingID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	const selA = `SELECT ` + attendeeColumns + ` FROM booking_attendees a JOIN bookings b ON b.id = a.booking_id
+		WHERE a.booking_id = $1 ORDER BY a.id`
+	var current []BookingAttendee
+	if err := tx.SelectContext(ctx, &current, selA, bookingID); err != nil {
+		return nil, err
+	}
+	if err := check(&b, current); err != nil {
+		return nil, err
+	}
+
+	// email в списке уже нормализованы сервисом
+	wanted := make(map[string]*BookingAttendee, len(list))
+	for i := range list {
+		wanted[list[i].Email] = &list[i]
+	}
+	for _, cur := range current {
+		next, ok := wanted[strings.ToLower(cur.Email)]
+		switch {
+		case !ok:
+			if _, err := tx.ExecContext(ctx, `DELETE FROM booking_attendees WHERE id = $1`, cur.ID); err != nil {
+				return nil, err
+			}
+		case next.Name != cur.Name:
+			if _, err := tx.ExecContext(ctx, `UPDATE booking_attendees SET name = $2 WHERE id = $1`, cur.ID, next.Name); err != nil {
+				return nil, err
+			}
+			fallthrough
+		default:
+			next.ID = cur.ID
+		}
+	}
+	for i := range list {
+		if list[i].ID != 0 {
+			continue
+		}
+		if err := insertAttendee(ctx, tx, bookingID, &list[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := []BookingAttendee{}
+	if err := tx.SelectContext(ctx, &updated, selA, bookingID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func insertAttendee(ctx context.Context, tx *sqlx.Tx, bookingID int64, a *BookingAttendee) error {
+	const q = `INSERT INTO booking_attendees (booking_id, name, email) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRowxContext(ctx, q, bookingID, a.Name, a.Email).Scan(&a.ID); err != nil {
+		return fmt.Errorf("insert booking attendee: %w", err)
+	}
+	a.BookingID = bookingID
+	return nil
+}
+
+func (r *repository) ClaimAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.ClaimAttendees")
+	defer span.End()
+
+	// закрепить место можно только подтверждённым email — иначе его мог бы указать кто угодно
+	const q = `WITH claimed AS (
+			UPDATE booking_attendees a SET user_id = u.id
+			FROM users u, bookings b
+			WHERE u.id = $1 AND u.email_verified AND lower(a.email) = lower(u.email)
+				AND a.user_id IS NULL AND b.id = a.booking_id AND b.status IN ('pending', 'confirmed')
+			RETURNING a.*
+		)
+		SELECT ` + attendeeColumns + ` FROM claimed a JOIN bookings b ON b.id = a.booking_id ORDER BY a.id`
+	list := []BookingAttendee{}
+	if err := r.db.SelectContext(ctx, &list, q, userID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) ListAttendeesByUser(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	const q = `SELECT ` + attendeeColumns + ` FROM booking_attendees a JOIN bookings b ON b.id = a.booking_id
+		WHERE a.user_id = $1 AND b.status IN ('pending', 'confirmed', 'checked_in') ORDER BY a.id DESC`
+	list := []BookingAttendee{}
+	if err := r.db.SelectContext(ctx, &list, q, userID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) CreateTransfer(ctx context.Context, t *Transfer, check func(b *Booking) error) error {
+	ctx, span := tracer.Start(ctx, "booking.repository.CreateTransfer")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// порядок блокировок как в AcceptTransfer: сначала бронь, потом передачи
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, t.BookingID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := check(&b); err != nil {
+		return err
+	}
+	var toSelf bool
+	const self = `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND lower(email) = lower($2))`
+	if err := tx.GetContext(ctx, &toSelf, self, b.UserID, t.ToEmail); err != nil {
+		return err
+	}
+	if toSelf {
+		return ErrTransferToSelf
+	}
+
+	const cancel = `UPDATE booking_transfers SET status = 'cancelled' WHERE booking_id = $1 AND status = 'pending'`
+	if _, err := tx.ExecContext(ctx, cancel, t.BookingID); err != nil {
+		return err
+	}
+	const ins = `INSERT INTO booking_transfers (booking_id, from_user_id, to_email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, status, created_at`
+	if err := tx.QueryRowxContext(ctx, ins, t.BookingID, b.UserID, t.ToEmail, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.Status, &t.CreatedAt); err != nil {
+		return err
+	}
+	t.FromUserID = b.UserID
+	return tx.Commit()
+}
+
+func (r *repository) GetTransferByHash(ctx context.Context, hash string) (*Transfer, error) {
+	const q = `SELECT ` + transferColumns + ` FROM booking_transfers WHERE token_hash = $1`
+	var t Transfer
+	if err := r.db.GetContext(ctx, &t, q, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repository) AcceptTransfer(ctx context.Context, transferID, userID int64, check func(b *Booking, t *Transfer) error) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.AcceptTransfer")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var ref struct {
+		BookingID int64 `db:"booking_id"`
+		EventID   int64 `db:"event_id"`
+	}
+	const selRef = `SELECT t.booking_id, b.event_id FROM booking_transfers t JOIN bookings b ON b.id = t.booking_id WHERE t.id = $1`
+	if err := tx.GetContext(ctx, &ref, selRef, transferID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	// событие блокируется первым, как в UpdateSeats: получатель не должен успеть превысить ограничения параллельной бронью
+	e, err := lockEventSeats(ctx, tx, ref.EventID)
+	if err != nil {
+		return nil, err
+	}
+	bookingID := ref.BookingID
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, bookingID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var t Transfer
+	const selT = `SELECT ` + transferColumns + ` FROM booking_transfers WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &t, selT, transferID); err != nil {
+		return nil, err
+	}
+	// принять передачу может только подтверждённый владелец email получателя
+	var recipient bool
+	const rcpt = `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND lower(email) = lower($2) AND email_verified)`
+	if err := tx.GetContext(ctx, &recipient, rcpt, userID, t.ToEmail); err != nil {
+		return nil, err
+	}
+	if !recipient {
+		return nil, ErrTransferRecipient
+	}
+	if err := check(&b, &t); err != nil {
+		return nil, err
+	}
+	userSeats, userBookings, err := userUsage(ctx, tx, b.EventID, userID, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkLimits(b.Seats, userSeats, userBookings); err != nil {
+		return nil, err
+	}
+
+	const upd = `UPDATE bookings SET user_id = $2, ticket_version = ticket_version + 1 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, upd, b.ID, userID); err != nil {
+		return nil, limitError(err)
+	}
+	const acc = `UPDATE booking_transfers SET status = 'accepted', to_user_id = $2, accepted_at = NOW() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, acc, t.ID, userID); err != nil {
+		return nil, err
+	}
+	h := history{BookingID: b.ID, From: b.Status, To: b.Status, SeatsFrom: b.Seats, SeatsTo: b.Seats, ActorID: userID,
+		Reason: fmt.Sprintf("transferred from user %d to user %d", b.UserID, userID)}
+	if err := insertHistory(ctx, tx, h); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	b.UserID = userID
+	b.TicketVersion++
+	return &b, nil
+}
+
+func (r *repository) EachAttendee(ctx context.Context, eventID int64, fn func(a *Attendee) error) error {
+	ctx, span := tracer.Start(ctx, "booking.repository.EachAttendee")
+	defer span.End()
+
+	// строки читаются из соединения по мере обхода, весь список в память не попадает
+	const q = `SELECT b.id AS booking_id, COALESCE(b.user_id, 0) AS user_id,
+			COALESCE(u.name, '') AS name, COALESCE(u.email, '') AS email,
+			b.seats, b.checked_in_seats, b.status, b.created_at AS booked_at
+		FROM bookings b
+		LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.event_id = $1 AND b.status IN ('confirmed', 'checked_in', 'no_show')
+		ORDER BY b.id`
+	rows, err := r.db.QueryxContext(ctx, q, eventID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var a Attendee
+	for rows.Next() {
+		if err := rows.StructScan(&a); err != nil {
+			return err
+		}
+		if err := fn(&a); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *repository) CountOccupiedSeats(ctx context.Context, eventID int64) (int, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.CountOccupiedSeats")
+	defer span.End()
+	const q = `SELECT COALESCE(SUM(seats),0) FROM bookings WHERE event_id=$1 AND status IN ` + occupiedStatusesSQL
 	var total int
 	if err := r.db.GetContext(ctx, &total, q, eventID); err != nil {
 		return 0, err
 	}
 	return total, nil
 }
+
+func (r *repository) CreatePayment(ctx context.Context, p *Payment) error {
+	const q = `INSERT INTO booking_payments (booking_id, provider, intent_id, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`
+	return r.db.QueryRowxContext(ctx, q, p.BookingID, p.Provider, p.IntentID, p.Amount, p.Currency, p.Status).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *repository) UpdatePayment(ctx context.Context, provider, intentID string, check func(b *Booking, p *Payment) (Status, PaymentStatus, error)) (*Booking, *Payment, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.UpdatePayment")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var bookingID int64
+	const ref = `SELECT booking_id FROM booking_payments WHERE provider = $1 AND intent_id = $2`
+	if err := tx.GetContext(ctx, &bookingID, ref, provider, intentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrPaymentNotFound
+		}
+		return nil, nil, err
+	}
+	// порядок блокировок как у остальных операций с бронью: сначала бронь, потом связанные строки
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, bookingID); err != nil {
+		return nil, nil, err
+	}
+	var p Payment
+	const selP = `SELECT ` + paymentColumns + ` FROM booking_payments WHERE provider = $1 AND intent_id = $2 FOR UPDATE`
+	if err := tx.GetContext(ctx, &p, selP, provider, intentID); err != nil {
+		return nil, nil, err
+	}
+	to, next, err := check(&b, &p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if to != "" && to != b.Status {
+		if _, err := tx.ExecContext(ctx, `UPDATE bookings SET status = $2 WHERE id = $1`, b.ID, to); err != nil {
+			return nil, nil, err
+		}
+		h := history{BookingID: b.ID, From: b.Status, To: to, SeatsFrom: b.Seats, SeatsTo: b.Seats, Reason: "payment " + string(next)}
+		if err := insertHistory(ctx, tx, h); err != nil {
+			return nil, nil, err
+		}
+		b.Status = to
+	}
+	if next != p.Status {
+		const upd = `UPDATE booking_payments SET status = $2, updated_at = NOW() WHERE id = $1 RETURNING updated_at`
+		if err := tx.GetContext(ctx, &p.UpdatedAt, upd, p.ID, next); err != nil {
+			return nil, nil, err
+		}
+		p.Status = next
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &b, &p, nil
+}
+
+func (r *repository) ExpirePending(ctx context.Context, now time.Time) ([]Booking, []Payment, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.ExpirePending")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// брони, которые сейчас обрабатывает уведомление об оплате, пропускаются до следующего прохода
+	const q = `UPDATE bookings SET status = 'cancelled'
+		WHERE id IN (
+			SELECT id FROM bookings WHERE status = 'pending' AND expires_at <= $1
+			ORDER BY expires_at LIMIT 500 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + bookingColumns
+	var list []Booking
+	if err := tx.SelectContext(ctx, &list, q, now); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]int64, 0, len(list))
+	for _, b := range list {
+		h := history{BookingID: b.ID, From: StatusPending, To: StatusCancelled, SeatsFrom: b.Seats, SeatsTo: b.Seats, Reason: "payment expired"}
+		if err := insertHistory(ctx, tx, h); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, b.ID)
+	}
+	var payments []Payment
+	if len(ids) > 0 {
+		const upd = `UPDATE booking_payments SET status = 'cancelled', updated_at = NOW()
+			WHERE booking_id = ANY($1) AND status IN ('pending', 'failed')
+			RETURNING ` + paymentColumns
+		if err := tx.SelectContext(ctx, &payments, upd, ids); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return list, payments, nil
+}
+
+func (r *repository) Cancel(ctx context.Context, id, actorID int64, reason string, check func(b *Booking, p *Payment) (int64, error)) (*Booking, *Refund, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.Cancel")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var b Booking
+	const sel = `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &b, sel, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	var p *Payment
+	var payment Payment
+	const selP = `SELECT ` + paymentColumns + ` FROM booking_payments WHERE booking_id = $1 ORDER BY id DESC LIMIT 1 FOR UPDATE`
+	switch err := tx.GetContext(ctx, &payment, selP, id); {
+	case err == nil:
+		p = &payment
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, nil, err
+	}
+	if p == nil {
+		// после появления оплаты брони платных событий создаются в pending, а импорт денег не берёт;
+		// брони, созданные раньше истории статусов, записей о создании не имеют
+		const legacy = `SELECT NOT EXISTS (SELECT 1 FROM booking_status_history
+			WHERE booking_id = $1 AND from_status IS NULL AND (to_status = 'pending' OR reason LIKE 'imported%'))`
+		if err := tx.GetContext(ctx, &b.LegacyPaid, legacy, id); err != nil {
+			return nil, nil, err
+		}
+	}
+	amount, err := check(&b, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE bookings SET status = 'cancelled' WHERE id = $1`, id); err != nil {
+		return nil, nil, err
+	}
+	h := history{BookingID: id, From: b.Status, To: StatusCancelled, SeatsFrom: b.Seats, SeatsTo: b.Seats, ActorID: actorID, Reason: reason}
+	if err := insertHistory(ctx, tx, h); err != nil {
+		return nil, nil, err
+	}
+	b.Status = StatusCancelled
+
+	var refund *Refund
+	switch {
+	case p == nil:
+	case p.Status == PaymentCaptured && amount > 0:
+		var refundID int64
+		const ins = `INSERT INTO booking_refunds (booking_id, payment_id, amount) VALUES ($1, $2, $3) RETURNING id`
+		if err := tx.GetContext(ctx, &refundID, ins, id, p.ID, amount); err != nil {
+			return nil, nil, err
+		}
+		if refund, err = getRefund(ctx, tx, refundID); err != nil {
+			return nil, nil, err
+		}
+	case p.Status != PaymentCaptured && p.Status != PaymentCancelled:
+		// деньги не списаны: поздняя оплата уже не подтвердит отменённую бронь
+		const upd = `UPDATE booking_payments SET status = 'cancelled', updated_at = NOW() WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, upd, p.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &b, refund, nil
+}
+
+func (r *repository) SaveRefundAttempt(ctx context.Context, id int64, status RefundStatus, providerRefundID, lastError string) (*Refund, error) {
+	ctx, span := tracer.Start(ctx, "booking.repository.SaveRefundAttempt")
+	defer span.End()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// завершённый возврат не трогаем: его мог закончить параллельный повтор
+	const upd = `UPDATE booking_refunds
+		SET status = $2, attempts = attempts + 1, provider_refund_id = NULLIF($3, ''), last_error = NULLIF($4, ''), updated_at = NOW()
+		WHERE id = $1 AND status = 'requested'
+		RETURNING booking_id`
+	var bookingID int64
+	switch err := tx.GetContext(ctx, &bookingID, upd, id, status, providerRefundID, lastError); {
+	case errors.Is(err, sql.ErrNoRows):
+		return getRefund(ctx, tx, id)
+	case err != nil:
+		return nil, err
+	}
+	if status == RefundSucceeded {
+		var seats int
+		const refunded = `UPDATE bookings SET status = 'refunded' WHERE id = $1 AND status = 'cancelled' RETURNING seats`
+		switch err := tx.GetContext(ctx, &seats, refunded, bookingID); {
+		case err == nil:
+			h := history{BookingID: bookingID, From: StatusCancelled, To: StatusRefunded, SeatsFrom: seats, SeatsTo: seats, Reason: "refund succeeded"}
+			if err := insertHistory(ctx, tx, h); err != nil {
+				return nil, err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+	}
+	refund, err := getRefund(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (r *repository) ListRefundsByEvent(ctx context.Context, eventID int64) ([]Refund, error) {
+	const q = `SELECT ` + refundColumns + `
+		FROM booking_refunds r
+		JOIN bookings b ON b.id = r.booking_id
+		JOIN booking_payments p ON p.id = r.payment_id
+		WHERE b.event_id = $1
+		ORDER BY r.id DESC`
+	list := []Refund{}
+	if err := r.db.SelectContext(ctx, &list, q, eventID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) ListStaleRefunds(ctx context.Context, before time.Time) ([]Refund, error) {
+	const q = `SELECT ` + refundColumns + `
+		FROM booking_refunds r
+		JOIN bookings b ON b.id = r.booking_id
+		JOIN booking_payments p ON p.id = r.payment_id
+		WHERE r.status = 'requested' AND r.updated_at < $1
+		ORDER BY r.updated_at
+		LIMIT 100`
+	var list []Refund
+	if err := r.db.SelectContext(ctx, &list, q, before); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) RequestRefund(ctx context.Context, bookingID, paymentID, amount int64) (*Refund, error) {
+	// у брони не больше одного возврата: повторное уведомление провайдера получает уже записанный
+	const ins = `INSERT INTO booking_refunds (booking_id, payment_id, amount) VALUES ($1, $2, $3)
+		ON CONFLICT (booking_id) DO NOTHING RETURNING id`
+	var id int64
+	switch err := r.db.GetContext(ctx, &id, ins, bookingID, paymentID, amount); {
+	case errors.Is(err, sql.ErrNoRows):
+		if err := r.db.GetContext(ctx, &id, `SELECT id FROM booking_refunds WHERE booking_id = $1`, bookingID); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	return getRefund(ctx, r.db, id)
+}
+
+func getRefund(ctx context.Context, q sqlx.QueryerContext, id int64) (*Refund, error) {
+	const sel = `SELECT ` + refundColumns + `
+		FROM booking_refunds r
+		JOIN bookings b ON b.id = r.booking_id
+		JOIN booking_payments p ON p.id = r.payment_id
+		WHERE r.id = $1`
+	var refund Refund
+	if err := sqlx.GetContext(ctx, q, &refund, sel, id); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -3,44 +3,298 @@ package booking
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/payment"
+	"laschool.ru/event-booking-service/internal/promo"
+)
+
+var (
+	ErrNotEnoughSeats = errors.New("not enough seats")
+	// ErrEmailNotVerified — бронировать могут только пользователи с подтверждённым email.
+	ErrEmailNotVerified = errors.New("email is not verified")
+	ErrInvalidFilter    = errors.New("invalid status filter")
+	ErrNotFound         = errors.New("booking not found")
+	// ErrNotOwner — менять бронь может только её владелец.
+	ErrNotOwner = errors.New("booking belongs to another user")
+	// ErrNotModifiable — менять можно только подтверждённую бронь.
+	ErrNotModifiable = errors.New("booking cannot be modified in its current status")
+	// ErrCancellationClosed — политика отмены события уже не допускает отмену.
+	ErrCancellationClosed = errors.New("cancellation window is closed")
+	// ErrInvalidAttendees — у посетителя нет имени, некорректный или повторяющийся email.
+	ErrInvalidAttendees = errors.New("invalid attendees")
+	// ErrTooManyAttendees — посетителей больше, чем мест в брони.
+	ErrTooManyAttendees = errors.New("more attendees than seats")
+	// ErrAttendeesLocked — до начала события осталось меньше AttendeeEditCutoff.
+	ErrAttendeesLocked = errors.New("attendees can no longer be changed")
+	// ErrAttendeeNotFound — в брони нет такого посетителя.
+	ErrAttendeeNotFound = errors.New("attendee not found")
 )
 
+// EventSource — источник событий, по которым считаются правила отмены.
+type EventSource interface {
+	Get(ctx context.Context, id int64) (*event.Event, error)
+}
+
+// BatchOptions — режим пакетного создания броней.
+type BatchOptions struct {
+	// Atomic — ни одна бронь не создаётся, если хотя бы одна не проходит
+	Atomic bool
+	// Complimentary — администратор выдаёт бесплатные брони: платные события бронируются без оплаты,
+	// а email пользователей не обязан быть подтверждён. Без этого такие строки отклоняются
+	Complimentary bool
+}
+
 type Service interface {
 	Create(ctx context.Context, b *Booking, eventCapacity int) (int64, error)
+	// CreateBatch создаёт подтверждённые брони от имени actorID (импорт). Ошибки броней возвращаются
+	// по индексам. Созданным броням проставляется ID
+	CreateBatch(ctx context.Context, bookings []*Booking, actorID int64, opts BatchOptions) ([]error, error)
 	Get(ctx context.Context, id int64) (*Booking, error)
+	// ChangeSeats атомарно меняет количество мест в брони пользователя actorID
+	ChangeSeats(ctx context.Context, id, actorID int64, seats int) (*Booking, error)
 	ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]Booking, error)
-	Cancel(ctx context.Context, id int64) error
+	// ListByUser возвращает брони пользователя с кратким описанием событий
+	ListByUser(ctx context.Context, userID int64, f ListFilter) ([]UserBooking, error)
+	// Cancel отменяет бронь пользователя actorID по политике отмены события и возвращает сумму возврата.
+	// Возврат по оплаченной брони проводится через провайдера в фоне
+	Cancel(ctx context.Context, id, actorID int64, reason string) (*Cancellation, error)
+	// Transition переводит бронь в статус to, если переход разрешён, и пишет его в историю
+	Transition(ctx context.Context, id int64, to Status, actorID int64, reason string) (*Booking, error)
+	// Ticket возвращает подписанный код билета для брони пользователя actorID
+	Ticket(ctx context.Context, id, actorID int64) (string, error)
+	// EachAttendee передаёт в fn посетителей события (подтверждённые и отмеченные брони) по одному
+	EachAttendee(ctx context.Context, eventID int64, fn func(a *Attendee) error) error
+	// CheckIn отмечает на входе события eventID seats мест по коду билета (0 — все оставшиеся)
+	CheckIn(ctx context.Context, eventID int64, code string, seats int, staffID int64) (*CheckIn, error)
+	// Attendees возвращает посетителей брони пользователя actorID
+	Attendees(ctx context.Context, id, actorID int64) ([]BookingAttendee, error)
+	// ReplaceAttendees заменяет посетителей брони пользователя actorID, пока не закрылось редактирование
+	ReplaceAttendees(ctx context.Context, id, actorID int64, list []AttendeeInput) ([]BookingAttendee, error)
+	// AttendeeTicket возвращает билет посетителя для владельца брони или аккаунта, закрепившего место
+	AttendeeTicket(ctx context.Context, id, attendeeID, actorID int64) (string, error)
+	// ClaimAttendees закрепляет за пользователем места, где указан его email
+	ClaimAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error)
+	// ListClaimedAttendees возвращает места, закреплённые за пользователем
+	ListClaimedAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error)
+	// RequestTransfer предлагает бронь пользователя actorID получателю с email и отправляет ему письмо с токеном
+	RequestTransfer(ctx context.Context, id, actorID int64, email string) (*Transfer, error)
+	// AcceptTransfer переоформляет бронь на userID по токену из письма; прежние билеты перестают действовать
+	AcceptTransfer(ctx context.Context, token string, userID int64) (*Booking, error)
+	// Checkout создаёт бронь платного события e в статусе pending и платёж у провайдера.
+	// Бронь держит места до ExpiresAt и подтверждается уведомлением об оплате
+	Checkout(ctx context.Context, b *Booking, e *event.Event) (*Checkout, error)
+	// HandlePayment применяет проверенное уведомление провайдера к брони и списывает заблокированные деньги
+	HandlePayment(ctx context.Context, evt *payment.WebhookEvent) (*Booking, error)
+	// ExpirePending отменяет брони, не оплаченные вовремя, и возвращает их
+	ExpirePending(ctx context.Context) ([]Booking, error)
+	// RefundsByEvent возвращает возвраты по броням события
+	RefundsByEvent(ctx context.Context, eventID int64) ([]Refund, error)
+	// RetryRefunds повторяет возвраты, брошенные незавершёнными
+	RetryRefunds(ctx context.Context) error
+}
+
+// Options — зависимости сервиса бронирований.
+type Options struct {
+	Events  EventSource
+	Metrics *metrics.Metrics
+	// TicketSecret — ключ подписи билетов
+	TicketSecret string
+	// AttendeeEditCutoff — за сколько до начала события закрывается редактирование посетителей
+	AttendeeEditCutoff time.Duration
+	// Mailer — отправка писем о передаче брони; nil — письма не отправляются
+	Mailer mailer.Mailer
+	// Tasks — фоновые задачи; nil — письма отправляются синхронно
+	Tasks *background.Group
+	// TransferTTL — сколько действует приглашение принять бронь
+	TransferTTL time.Duration
+	// TransferURL — страница принятия брони для ссылки из письма
+	TransferURL string
+	// Payments — провайдер оплаты платных событий; nil — платные брони недоступны
+	Payments payment.Provider
+	// PaymentTTL — сколько неоплаченная бронь держит места
+	PaymentTTL time.Duration
+	// Currency — валюта цен событий
+	Currency string
+	// RefundAttempts — сколько раз обращаться к провайдеру за возвратом, прежде чем признать его неудачным
+	RefundAttempts int
+	// RefundBackoff — пауза перед второй попыткой возврата; дальше удваивается
+	RefundBackoff time.Duration
 }
 
 type service struct {
 	repo Repository
+	opts Options
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts Options) Service {
+	if opts.TransferTTL <= 0 {
+		opts.TransferTTL = 72 * time.Hour
+	}
+	if opts.PaymentTTL <= 0 {
+		opts.PaymentTTL = 15 * time.Minute
+	}
+	if opts.Currency == "" {
+		opts.Currency = "rub"
+	}
+	if opts.RefundAttempts <= 0 {
+		opts.RefundAttempts = 5
+	}
+	if opts.RefundBackoff <= 0 {
+		opts.RefundBackoff = 2 * time.Second
+	}
+	return &service{repo: repo, opts: opts}
 }
 
 func (s *service) Create(ctx context.Context, b *Booking, eventCapacity int) (int64, error) {
+	ctx, span := tracer.Start(ctx, "booking.Create")
+	defer span.End()
+
 	if b.EventID == 0 || b.UserID == 0 {
 		return 0, errors.New("event_id and user_id are required")
 	}
 	if b.Seats <= 0 {
 		return 0, errors.New("seats must be positive")
 	}
-	used, err := s.repo.CountConfirmedSeats(ctx, b.EventID)
+	attendees, err := normalizeAttendees(b.Attendees)
+	if err != nil {
+		return 0, err
+	}
+	if len(attendees) > b.Seats {
+		return 0, ErrTooManyAttendees
+	}
+	b.Attendees = attendees
+	// быстрая проверка без блокировок; окончательная — в репозитории под блокировкой события
+	used, err := s.repo.CountOccupiedSeats(ctx, b.EventID)
 	if err != nil {
 		return 0, err
 	}
 	if used+b.Seats > eventCapacity {
-		return 0, errors.New("not enough seats")
+		logger.FromContext(ctx).Info("booking rejected: not enough seats",
+			"event_id", b.EventID, "seats", b.Seats, "used", used, "capacity", eventCapacity)
+		s.opts.Metrics.BookingOutcome(metrics.BookingRejectedCapacity)
+		return 0, ErrNotEnoughSeats
+	}
+	// ограничения события на одного пользователя проверяются только под блокировкой события:
+	// без неё параллельные брони одного пользователя проходили бы проверку одновременно
+	id, err := s.repo.Create(ctx, b)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotEnoughSeats):
+			s.opts.Metrics.BookingOutcome(metrics.BookingRejectedCapacity)
+		case IsLimitError(err):
+			logger.FromContext(ctx).Info("booking rejected: user limit",
+				"event_id", b.EventID, "user_id", b.UserID, "seats", b.Seats, "error", err)
+			s.opts.Metrics.BookingOutcome(metrics.BookingRejectedLimit)
+		case promo.IsRedeemError(err):
+			logger.FromContext(ctx).Info("booking rejected: promo code",
+				"event_id", b.EventID, "user_id", b.UserID, "code", b.PromoCode, "error", err)
+			s.opts.Metrics.BookingOutcome(metrics.BookingRejectedPromo)
+		}
+		return 0, err
 	}
-	return s.repo.Create(ctx, b)
+	logger.FromContext(ctx).Info("booking created", "booking_id", id, "event_id", b.EventID, "seats", b.Seats,
+		"discount", b.Discount)
+	s.opts.Metrics.BookingOutcome(metrics.BookingCreated)
+	return id, nil
+}
+
+func (s *service) CreateBatch(ctx context.Context, bookings []*Booking, actorID int64, opts BatchOptions) ([]error, error) {
+	ctx, span := tracer.Start(ctx, "booking.CreateBatch")
+	defer span.End()
+
+	errs := make([]error, len(bookings))
+	var valid []*Booking
+	var index []int
+	for i, b := range bookings {
+		switch {
+		case b.EventID == 0 || b.UserID == 0:
+			errs[i] = errors.New("event_id and user_id are required")
+		case b.Seats <= 0:
+			errs[i] = errors.New("seats must be positive")
+		default:
+			valid = append(valid, b)
+			index = append(index, i)
+		}
+	}
+	if len(valid) == 0 || (opts.Atomic && len(valid) < len(bookings)) {
+		return errs, nil
+	}
+
+	repoErrs, err := s.repo.CreateBatch(ctx, valid, actorID, opts)
+	if err != nil {
+		return nil, err
+	}
+	created := 0
+	for j, i := range index {
+		errs[i] = repoErrs[j]
+		if valid[j].ID != 0 {
+			created++
+			s.opts.Metrics.BookingOutcome(metrics.BookingCreated)
+		} else if errors.Is(repoErrs[j], ErrNotEnoughSeats) {
+			s.opts.Metrics.BookingOutcome(metrics.BookingRejectedCapacity)
+		} else if IsLimitError(repoErrs[j]) {
+			s.opts.Metrics.BookingOutcome(metrics.BookingRejectedLimit)
+		}
+	}
+	logger.FromContext(ctx).Info("booking batch processed", "total", len(bookings), "created", created,
+		"atomic", opts.Atomic, "complimentary", opts.Complimentary, "actor_id", actorID)
+	return errs, nil
 }
 
 func (s *service) Get(ctx context.Context, id int64) (*Booking, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+func (s *service) ChangeSeats(ctx context.Context, id, actorID int64, seats int) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.ChangeSeats")
+	defer span.End()
+
+	if seats <= 0 {
+		return nil, errors.New("seats must be positive")
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != actorID {
+		return nil, ErrNotOwner
+	}
+	if b.Seats == seats {
+		return b, nil
+	}
+	if b.PromoCodeID != nil {
+		return nil, ErrPaidSeatsChange
+	}
+	e, err := s.opts.Events.Get(ctx, b.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event %d: %w", b.EventID, err)
+	}
+	if e.Price > 0 {
+		return nil, ErrPaidSeatsChange
+	}
+
+	updated, err := s.repo.UpdateSeats(ctx, id, seats, actorID)
+	if err != nil {
+		if errors.Is(err, ErrNotEnoughSeats) || errors.Is(err, ErrPaidSeatsChange) || IsLimitError(err) {
+			logger.FromContext(ctx).Info("seat change rejected",
+				"booking_id", id, "seats_from", b.Seats, "seats_to", seats, "error", err)
+		}
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking seats changed", "booking_id", id, "seats_from", b.Seats, "seats_to", seats)
+	return updated, nil
+}
+
 func (s *service) ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]Booking, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 20
@@ -51,9 +305,715 @@ func (s *service) ListByEvent(ctx context.Context, eventID int64, limit, offset
 	return s.repo.ListByEvent(ctx, eventID, limit, offset)
 }
 
-func (s *service) Cancel(ctx context.Context, id int64) error {
+func (s *service) ListByUser(ctx context.Context, userID int64, f ListFilter) ([]UserBooking, error) {
+	switch f.Status {
+	case "", FilterConfirmed, FilterCancelled, FilterUpcoming, FilterPast:
+	default:
+		return nil, ErrInvalidFilter
+	}
+	if f.Limit <= 0 || f.Limit > 100 {
+		f.Limit = 20
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return s.repo.ListByUser(ctx, userID, f)
+}
+
+func (s *service) Cancel(ctx context.Context, id, actorID int64, reason string) (*Cancellation, error) {
+	ctx, span := tracer.Start(ctx, "booking.Cancel")
+	defer span.End()
+
 	if id == 0 {
-		return errors.New("id is required")
+		return nil, errors.New("id is required")
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.opts.Events.Get(ctx, b.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event %d: %w", b.EventID, err)
+	}
+	percent, allowed := e.CancellationPolicy.RefundPercent(e.StartsAt, time.Now())
+	if reason == "" {
+		reason = fmt.Sprintf("cancelled by user, refund %d%%", percent)
+	}
+
+	var c *Cancellation
+	var hold string
+	_, refund, err := s.repo.Cancel(ctx, id, actorID, reason, func(b *Booking, p *Payment) (int64, error) {
+		if b.UserID != actorID {
+			return 0, ErrNotOwner
+		}
+		if err := checkTransition(b.Status, StatusCancelled); err != nil {
+			return 0, err
+		}
+		if b.CheckedInSeats > 0 {
+			return 0, ErrNotModifiable
+		}
+		if !allowed {
+			return 0, ErrCancellationClosed
+		}
+		// места и платёж берём из заблокированной брони: их могли изменить после GetByID
+		c = newCancellation(b.ID, paidAmount(b, p, e.Price), percent)
+		if p == nil {
+			return 0, nil
+		}
+		// несписанный платёж отменяется и у провайдера, чтобы снять блокировку денег на карте
+		if p.Status != PaymentCaptured && p.Status != PaymentCancelled {
+			hold = p.IntentID
+		}
+		return c.Refund, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrCancellationClosed) {
+			logger.FromContext(ctx).Info("cancellation rejected: window closed", "booking_id", id, "event_id", b.EventID)
+		}
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking cancelled", "booking_id", id, "actor_id", actorID,
+		"refund_percent", c.RefundPercent, "refund", c.Refund)
+	s.opts.Metrics.BookingOutcome(metrics.BookingCancelled)
+
+	if refund != nil {
+		c.RefundID, c.RefundStatus = refund.ID, refund.Status
+		s.startRefund(ctx, refund)
+	}
+	if hold != "" {
+		release := func(ctx context.Context) { s.voidPayment(ctx, id, hold) }
+		if s.opts.Tasks == nil {
+			release(ctx)
+		} else {
+			s.opts.Tasks.Go(ctx, refundAttemptTimeout, release)
+		}
+	}
+	return c, nil
+}
+
+func (s *service) Transition(ctx context.Context, id int64, to Status, actorID int64, reason string) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.Transition")
+	defer span.End()
+
+	if !to.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	var from Status
+	b, err := s.repo.Transition(ctx, id, to, actorID, reason, func(b *Booking) error {
+		from = b.Status
+		return checkTransition(b.Status, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking status changed", "booking_id", id, "from", from, "to", to, "actor_id", actorID)
+	if to == StatusCancelled {
+		s.opts.Metrics.BookingOutcome(metrics.BookingCancelled)
+	}
+	return b, nil
+}
+
+func (s *service) Ticket(ctx context.Context, id, actorID int64) (string, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if b.UserID != actorID {
+		return "", ErrNotOwner
+	}
+	if b.Status != StatusConfirmed {
+		return "", ErrTicketUnavailable
+	}
+	return Ticket{BookingID: b.ID, EventID: b.EventID, Seats: b.Seats, Version: b.TicketVersion}.sign(s.opts.TicketSecret)
+}
+
+func (s *service) CheckIn(ctx context.Context, eventID int64, code string, seats int, staffID int64) (*CheckIn, error) {
+	ctx, span := tracer.Start(ctx, "booking.CheckIn")
+	defer span.End()
+
+	if seats < 0 {
+		return nil, errors.New("seats must not be negative")
+	}
+	t, err := parseTicket(s.opts.TicketSecret, code)
+	if err != nil {
+		return nil, err
+	}
+	if t.EventID != eventID {
+		return nil, ErrTicketWrongEvent
+	}
+
+	// билет посетителя пропускает ровно одно место
+	if t.AttendeeID != 0 && seats > 1 {
+		return nil, fmt.Errorf("%w: attendee ticket admits one seat", ErrCheckInSeats)
+	}
+
+	admitted := 0
+	b, err := s.repo.CheckIn(ctx, t.BookingID, t.AttendeeID, staffID, func(b *Booking, a *BookingAttendee) (int, error) {
+		if b.EventID != eventID {
+			return 0, ErrTicketWrongEvent
+		}
+		// места в билете брони должны совпадать с бронью: после их смены старый билет не действует.
+		// Билет посетителя устаревает, когда посетителя убирают из брони
+		if a == nil && b.Seats != t.Seats {
+			return 0, ErrInvalidTicket
+		}
+		// после передачи брони билеты прежнего владельца не действуют
+		if t.Version != b.TicketVersion {
+			return 0, ErrInvalidTicket
+		}
+		switch b.Status {
+		case StatusConfirmed:
+		case StatusCheckedIn:
+			return 0, ErrAlreadyCheckedIn
+		default:
+			return 0, ErrTicketUnavailable
+		}
+		if a != nil && a.CheckedInAt != nil {
+			return 0, ErrAlreadyCheckedIn
+		}
+		remaining := b.Seats - b.CheckedInSeats
+		admitted = seats
+		if admitted == 0 {
+			admitted = remaining
+		}
+		if a != nil {
+			admitted = 1
+		}
+		if admitted > remaining {
+			return 0, fmt.Errorf("%w: %d left", ErrCheckInSeats, remaining)
+		}
+		if admitted == remaining {
+			if err := checkTransition(b.Status, StatusCheckedIn); err != nil {
+				return 0, err
+			}
+		}
+		return admitted, nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Info("check-in rejected", "booking_id", t.BookingID, "event_id", eventID, "error", err)
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking checked in", "booking_id", b.ID, "event_id", eventID,
+		"admitted", admitted, "checked_in_seats", b.CheckedInSeats, "staff_id", staffID)
+	return &CheckIn{
+		BookingID:      b.ID,
+		EventID:        b.EventID,
+		AttendeeID:     t.AttendeeID,
+		Seats:          b.Seats,
+		Admitted:       admitted,
+		CheckedInSeats: b.CheckedInSeats,
+		Remaining:      b.Seats - b.CheckedInSeats,
+		Status:         b.Status,
+	}, nil
+}
+
+func (s *service) EachAttendee(ctx context.Context, eventID int64, fn func(a *Attendee) error) error {
+	ctx, span := tracer.Start(ctx, "booking.EachAttendee")
+	defer span.End()
+
+	if eventID == 0 {
+		return errors.New("event_id is required")
+	}
+	return s.repo.EachAttendee(ctx, eventID, fn)
+}
+
+func (s *service) Attendees(ctx context.Context, id, actorID int64) ([]BookingAttendee, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != actorID {
+		return nil, ErrNotOwner
+	}
+	return s.repo.ListAttendees(ctx, id)
+}
+
+func (s *service) ReplaceAttendees(ctx context.Context, id, actorID int64, list []AttendeeInput) ([]BookingAttendee, error) {
+	ctx, span := tracer.Start(ctx, "booking.ReplaceAttendees")
+	defer span.End()
+
+	attendees := make([]BookingAttendee, len(list))
+	for i, in := range list {
+		attendees[i] = BookingAttendee{Name: in.Name, Email: in.Email}
+	}
+	attendees, err := normalizeAttendees(attendees)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != actorID {
+		return nil, ErrNotOwner
+	}
+	e, err := s.opts.Events.Get(ctx, b.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event %d: %w", b.EventID, err)
+	}
+	if !time.Now().Before(e.StartsAt.Add(-s.opts.AttendeeEditCutoff)) {
+		return nil, ErrAttendeesLocked
+	}
+
+	keep := make(map[string]bool, len(attendees))
+	for _, a := range attendees {
+		keep[a.Email] = true
+	}
+	updated, err := s.repo.ReplaceAttendees(ctx, id, attendees, func(b *Booking, current []BookingAttendee) error {
+		if b.UserID != actorID {
+			return ErrNotOwner
+		}
+		if b.Status != StatusConfirmed && b.Status != StatusPending {
+			return ErrNotModifiable
+		}
+		// места берём из заблокированной брони: их могли изменить после GetByID
+		if len(attendees) > b.Seats {
+			return ErrTooManyAttendees
+		}
+		// прошедшего на вход посетителя убрать или заменить уже нельзя
+		for _, cur := range current {
+			if cur.CheckedInAt != nil && !keep[strings.ToLower(cur.Email)] {
+				return ErrNotModifiable
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking attendees updated", "booking_id", id, "attendees", len(updated), "actor_id", actorID)
+	return updated, nil
+}
+
+func (s *service) AttendeeTicket(ctx context.Context, id, attendeeID, actorID int64) (string, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	attendees, err := s.repo.ListAttendees(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	i := slices.IndexFunc(attendees, func(a BookingAttendee) bool { return a.ID == attendeeID })
+	if i < 0 {
+		return "", ErrAttendeeNotFound
+	}
+	if b.UserID != actorID && attendees[i].UserID != actorID {
+		return "", ErrNotOwner
+	}
+	if b.Status != StatusConfirmed {
+		return "", ErrTicketUnavailable
+	}
+	return Ticket{BookingID: b.ID, EventID: b.EventID, Seats: 1, AttendeeID: attendeeID, Version: b.TicketVersion}.sign(s.opts.TicketSecret)
+}
+
+func (s *service) ClaimAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	ctx, span := tracer.Start(ctx, "booking.ClaimAttendees")
+	defer span.End()
+
+	claimed, err := s.repo.ClaimAttendees(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		logger.FromContext(ctx).Info("attendee seats claimed", "user_id", userID, "count", len(claimed))
+	}
+	return claimed, nil
+}
+
+func (s *service) ListClaimedAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	return s.repo.ListAttendeesByUser(ctx, userID)
+}
+
+// normalizeAttendees проверяет посетителей и приводит email к нижнему регистру:
+// по email место потом закрепляется за аккаунтом, поэтому в одной брони он не повторяется.
+func normalizeAttendees(list []BookingAttendee) ([]BookingAttendee, error) {
+	seen := make(map[string]bool, len(list))
+	for i := range list {
+		a := &list[i]
+		a.Name = strings.TrimSpace(a.Name)
+		a.Email = strings.ToLower(strings.TrimSpace(a.Email))
+		if a.Name == "" {
+			return nil, fmt.Errorf("%w: attendee %d has no name", ErrInvalidAttendees, i+1)
+		}
+		if addr, err := mail.ParseAddress(a.Email); err != nil || addr.Address != a.Email {
+			return nil, fmt.Errorf("%w: attendee %d has invalid email", ErrInvalidAttendees, i+1)
+		}
+		if seen[a.Email] {
+			return nil, fmt.Errorf("%w: duplicate email %s", ErrInvalidAttendees, a.Email)
+		}
+		seen[a.Email] = true
+	}
+	return list, nil
+}
+
+func (s *service) RequestTransfer(ctx context.Context, id, actorID int64, email string) (*Transfer, error) {
+	ctx, span := tracer.Start(ctx, "booking.RequestTransfer")
+	defer span.End()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, errors.New("invalid email")
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.UserID != actorID {
+		return nil, ErrNotOwner
+	}
+	e, err := s.opts.Events.Get(ctx, b.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event %d: %w", b.EventID, err)
+	}
+	if !transferOpen(e, time.Now()) {
+		return nil, ErrTransferClosed
+	}
+
+	token, hash, err := newTransferToken(s.opts.TicketSecret)
+	if err != nil {
+		return nil, err
+	}
+	t := &Transfer{BookingID: id, ToEmail: email, TokenHash: hash, ExpiresAt: time.Now().Add(s.opts.TransferTTL)}
+	err = s.repo.CreateTransfer(ctx, t, func(b *Booking) error {
+		if b.UserID != actorID {
+			return ErrNotOwner
+		}
+		// после прохода на вход бронь уже использована
+		if b.Status != StatusConfirmed || b.CheckedInSeats > 0 {
+			return ErrNotModifiable
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking transfer requested", "booking_id", id, "transfer_id", t.ID, "actor_id", actorID)
+
+	send := func(ctx context.Context) {
+		if s.opts.Mailer == nil {
+			return
+		}
+		if err := s.opts.Mailer.Send(ctx, transferMessage(t, e.Title, s.opts.TransferURL, token)); err != nil {
+			logger.FromContext(ctx).Error("send transfer email failed", "transfer_id", t.ID, "error", err)
+		}
+	}
+	if s.opts.Tasks == nil {
+		send(ctx)
+	} else {
+		s.opts.Tasks.Go(ctx, 10*time.Second, send)
+	}
+	return t, nil
+}
+
+func (s *service) AcceptTransfer(ctx context.Context, token string, userID int64) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.AcceptTransfer")
+	defer span.End()
+
+	hash, ok := parseTransferToken(s.opts.TicketSecret, token)
+	if !ok {
+		return nil, ErrTransferNotFound
+	}
+	t, err := s.repo.GetTransferByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(ctx, t.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.opts.Events.Get(ctx, b.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event %d: %w", b.EventID, err)
+	}
+
+	now := time.Now()
+	var fromUserID int64
+	updated, err := s.repo.AcceptTransfer(ctx, t.ID, userID, func(b *Booking, t *Transfer) error {
+		if t.Status != TransferPending {
+			return ErrTransferNotFound
+		}
+		if !now.Before(t.ExpiresAt) {
+			return ErrTransferExpired
+		}
+		// бронь могли вернуть, отменить или передать иначе, пока письмо шло
+		if b.UserID != t.FromUserID {
+			return ErrTransferNotFound
+		}
+		if b.Status != StatusConfirmed || b.CheckedInSeats > 0 {
+			return ErrNotModifiable
+		}
+		if !transferOpen(e, now) {
+			return ErrTransferClosed
+		}
+		fromUserID = b.UserID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("booking transferred", "booking_id", updated.ID, "transfer_id", t.ID,
+		"from_user_id", fromUserID, "to_user_id", userID)
+	return updated, nil
+}
+
+// transferOpen — можно ли ещё передавать брони события.
+func transferOpen(e *event.Event, now time.Time) bool {
+	return now.Before(e.StartsAt.Add(-time.Duration(e.TransferCutoffHours) * time.Hour))
+}
+
+func (s *service) Checkout(ctx context.Context, b *Booking, e *event.Event) (*Checkout, error) {
+	ctx, span := tracer.Start(ctx, "booking.Checkout")
+	defer span.End()
+
+	if s.opts.Payments == nil {
+		return nil, ErrPaymentsDisabled
+	}
+	if e.Price <= 0 {
+		return nil, ErrFreeEvent
+	}
+	expiresAt := time.Now().Add(s.opts.PaymentTTL)
+	b.Status, b.ExpiresAt = StatusPending, &expiresAt
+	id, err := s.Create(ctx, b, e.Capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := e.Price*int64(b.Seats) - b.Discount
+	if amount <= 0 {
+		// промокод покрыл всю сумму: платить нечего, бронь подтверждается сразу
+		if _, err := s.repo.Transition(ctx, id, StatusConfirmed, b.UserID, "paid by promo code", func(b *Booking) error {
+			return checkTransition(b.Status, StatusConfirmed)
+		}); err != nil {
+			return nil, err
+		}
+		logger.FromContext(ctx).Info("booking confirmed by promo code", "booking_id", id, "discount", b.Discount)
+		return &Checkout{
+			BookingID: id, Status: StatusConfirmed, ExpiresAt: expiresAt, Discount: b.Discount, Currency: s.opts.Currency,
+		}, nil
+	}
+	// ключ по брони: повтор после сбоя сети не создаст у провайдера второй платёж
+	intent, err := s.opts.Payments.CreateIntent(ctx, payment.IntentRequest{
+		BookingID: id, Amount: amount, Currency: s.opts.Currency, IdempotencyKey: fmt.Sprintf("booking-%d", id),
+	})
+	if err == nil {
+		err = s.repo.CreatePayment(ctx, &Payment{
+			BookingID: id, Provider: s.opts.Payments.Name(), IntentID: intent.ID,
+			Amount: amount, Currency: s.opts.Currency, Status: PaymentPending,
+		})
+	}
+	if err != nil {
+		// бронь без платежа не оплатить: места освобождаются сразу, а не по истечении срока
+		_, cancelErr := s.repo.Transition(ctx, id, StatusCancelled, 0, "payment unavailable", func(b *Booking) error {
+			return checkTransition(b.Status, StatusCancelled)
+		})
+		if cancelErr != nil {
+			logger.FromContext(ctx).Error("cancel unpaid booking failed", "booking_id", id, "error", cancelErr)
+		}
+		logger.FromContext(ctx).Error("create payment failed", "booking_id", id, "error", err)
+		return nil, ErrPaymentUnavailable
+	}
+	logger.FromContext(ctx).Info("booking awaiting payment", "booking_id", id, "intent_id", intent.ID,
+		"amount", amount, "expires_at", expiresAt)
+	return &Checkout{
+		BookingID: id, Status: StatusPending, ExpiresAt: expiresAt, Amount: amount, Discount: b.Discount,
+		Currency: s.opts.Currency, Provider: s.opts.Payments.Name(), ClientSecret: intent.ClientSecret,
+	}, nil
+}
+
+func (s *service) HandlePayment(ctx context.Context, evt *payment.WebhookEvent) (*Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.HandlePayment")
+	defer span.End()
+
+	if s.opts.Payments == nil {
+		return nil, ErrPaymentsDisabled
+	}
+	provider := s.opts.Payments.Name()
+	now := time.Now()
+	var from PaymentStatus
+	b, p, err := s.repo.UpdatePayment(ctx, provider, evt.IntentID, func(b *Booking, p *Payment) (Status, PaymentStatus, error) {
+		from = p.Status
+		paid := evt.Type == payment.EventAuthorized || evt.Type == payment.EventSucceeded
+		if paid && evt.Amount != p.Amount {
+			return "", "", fmt.Errorf("payment %s: paid %d, expected %d", p.IntentID, evt.Amount, p.Amount)
+		}
+		to, next := paymentOutcome(b, p, evt.Type, now)
+		return to, next, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log := logger.FromContext(ctx).With("booking_id", b.ID, "intent_id", p.IntentID, "event_id", evt.ID, "type", evt.Type)
+	if from != p.Status {
+		log.Info("booking payment updated", "payment_from", from, "payment_to", p.Status, "booking_status", b.Status)
+	}
+
+	switch {
+	case p.Status == PaymentCancelled && evt.Type == payment.EventAuthorized:
+		// деньги только заблокированы: отменяем платёж, и блокировка снимается без возврата
+		log.Warn("payment arrived after booking expired", "captured", false)
+		s.voidPayment(ctx, b.ID, p.IntentID)
+		return b, ErrPaymentExpired
+	case p.Status == PaymentCancelled && evt.Type == payment.EventSucceeded:
+		// деньги списаны: возврат идёт через ту же очередь, что и возвраты при отмене. При ошибке
+		// уведомление не подтверждается, и провайдер присылает его снова
+		log.Warn("payment arrived after booking expired", "captured", true)
+		refund, err := s.repo.RequestRefund(ctx, b.ID, p.ID, p.Amount)
+		if err != nil {
+			return b, fmt.Errorf("request refund for payment %s: %w", p.IntentID, err)
+		}
+		if refund.Status == RefundRequested {
+			s.startRefund(ctx, refund)
+		}
+		return b, ErrPaymentExpired
+	case p.Status == PaymentAuthorized && b.Status == StatusConfirmed:
+		// при ошибке уведомление не подтверждается, и провайдер присылает его снова — списание повторяется
+		if err := s.opts.Payments.Capture(ctx, p.IntentID); err != nil {
+			return b, fmt.Errorf("capture payment %s: %w", p.IntentID, err)
+		}
+		_, _, err := s.repo.UpdatePayment(ctx, provider, p.IntentID, func(b *Booking, p *Payment) (Status, PaymentStatus, error) {
+			if p.Status == PaymentAuthorized {
+				return "", PaymentCaptured, nil
+			}
+			return "", p.Status, nil
+		})
+		if err != nil {
+			return b, err
+		}
+		log.Info("booking payment captured")
+	}
+	return b, nil
+}
+
+func (s *service) ExpirePending(ctx context.Context) ([]Booking, error) {
+	ctx, span := tracer.Start(ctx, "booking.ExpirePending")
+	defer span.End()
+
+	list, payments, err := s.repo.ExpirePending(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range list {
+		logger.FromContext(ctx).Info("unpaid booking expired", "booking_id", b.ID, "event_id", b.EventID, "seats", b.Seats)
+		s.opts.Metrics.BookingOutcome(metrics.BookingExpired)
+	}
+	// клиент мог успеть оплатить у провайдера, пока уведомление ещё в пути: отменённый платёж
+	// уже не оплатить, а запоздавшее уведомление о списании уйдёт в возврат
+	for _, p := range payments {
+		if ctx.Err() != nil {
+			break
+		}
+		s.voidPayment(ctx, p.BookingID, p.IntentID)
+	}
+	return list, nil
+}
+
+// voidPayment отменяет у провайдера платёж intentID без списания. Ошибка только пишется в лог:
+// блокировку провайдер со временем снимет сам, а списание по отменённой брони уходит в возврат.
+func (s *service) voidPayment(ctx context.Context, bookingID int64, intentID string) {
+	log := logger.FromContext(ctx).With("booking_id", bookingID, "intent_id", intentID)
+	if s.opts.Payments == nil {
+		log.Error("payment void skipped: payments are not configured")
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, refundAttemptTimeout)
+	defer cancel()
+	if err := s.opts.Payments.Cancel(ctx, intentID); err != nil {
+		log.Error("payment void failed", "error", err)
+		return
+	}
+	log.Info("booking payment voided")
+}
+
+// startRefund проводит записанный возврат в фоне, а без фоновых задач — сразу.
+func (s *service) startRefund(ctx context.Context, refund *Refund) {
+	process := func(ctx context.Context) { s.processRefund(ctx, refund) }
+	if s.opts.Tasks == nil {
+		process(ctx)
+	} else {
+		s.opts.Tasks.Go(ctx, s.refundTimeout(), process)
+	}
+}
+
+func (s *service) RefundsByEvent(ctx context.Context, eventID int64) ([]Refund, error) {
+	return s.repo.ListRefundsByEvent(ctx, eventID)
+}
+
+func (s *service) RetryRefunds(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "booking.RetryRefunds")
+	defer span.End()
+
+	list, err := s.repo.ListStaleRefunds(ctx, time.Now().Add(-refundRetryAfter))
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.processRefund(ctx, &list[i])
+	}
+	return nil
+}
+
+// refundAttemptTimeout — сколько ждать ответа провайдера на одну попытку возврата
+const refundAttemptTimeout = 30 * time.Second
+
+// refundTimeout — сколько может занять processRefund со всеми попытками и паузами
+func (s *service) refundTimeout() time.Duration {
+	total, wait := time.Duration(0), s.opts.RefundBackoff
+	for i := 0; i < s.opts.RefundAttempts; i++ {
+		total += refundAttemptTimeout + wait
+		wait *= 2
+	}
+	return total
+}
+
+// processRefund проводит возврат через провайдера, повторяя временные ошибки с растущей паузой.
+// Попытки считаются в записи возврата, поэтому повтор после остановки продолжает с того же места;
+// ключ идемпотентности не даёт провайдеру вернуть деньги дважды.
+func (s *service) processRefund(ctx context.Context, r *Refund) {
+	log := logger.FromContext(ctx).With("refund_id", r.ID, "booking_id", r.BookingID)
+	if s.opts.Payments == nil {
+		log.Error("refund skipped: payments are not configured")
+		return
+	}
+	wait := s.opts.RefundBackoff
+	for attempt := r.Attempts + 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, refundAttemptTimeout)
+		res, err := s.opts.Payments.Refund(attemptCtx, payment.RefundRequest{
+			IntentID: r.IntentID, Amount: r.Amount, IdempotencyKey: fmt.Sprintf("refund-%d", r.ID),
+		})
+		cancel()
+
+		status, providerID, lastError := RefundSucceeded, "", ""
+		if err == nil {
+			providerID = res.ID
+		} else {
+			status, lastError = RefundRequested, err.Error()
+			if !payment.Retryable(err) || attempt >= s.opts.RefundAttempts {
+				status = RefundFailed
+			}
+		}
+		saved, saveErr := s.repo.SaveRefundAttempt(ctx, r.ID, status, providerID, lastError)
+		if saveErr != nil {
+			log.Error("save refund attempt failed", "attempt", attempt, "error", saveErr)
+			return
+		}
+		switch saved.Status {
+		case RefundSucceeded:
+			log.Info("booking refunded", "amount", saved.Amount, "attempts", saved.Attempts)
+			return
+		case RefundFailed:
+			log.Error("refund failed", "amount", saved.Amount, "attempts", saved.Attempts, "error", saved.LastError)
+			return
+		}
+		log.Warn("refund attempt failed, retrying", "attempt", attempt, "retry_in", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
 	}
-	return s.repo.Cancel(ctx, id)
 }
@@ -2,35 +2,276 @@ package booking
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/payment"
 )
 
+type eventsStub struct {
+	event *event.Event
+}
+
+func (s eventsStub) Get(ctx context.Context, id int64) (*event.Event, error) {
+	if s.event != nil {
+		return s.event, nil
+	}
+	return &event.Event{ID: id, StartsAt: time.Now().Add(time.Hour)}, nil
+}
+
 type repoStub struct {
-	used int
+	used      int
+	capacity  int
+	booking   *Booking
+	attendees []BookingAttendee
+	// transfer — единственная передача; recipientID — пользователь с email её получателя
+	transfer    *Transfer
+	recipientID int64
+	// payment — единственный платёж; CreatePayment пишет в него
+	payment *Payment
+	// refund — единственный возврат; Cancel пишет в него
+	refund *Refund
+	// discount — скидка по любому промокоду
+	discount int64
 }
 
-func (r repoStub) Create(ctx context.Context, b *Booking) (int64, error) { return 1, nil }
+func (r repoStub) Create(ctx context.Context, b *Booking) (int64, error) {
+	if b.PromoCode != "" {
+		b.Discount = r.discount
+	}
+	return 1, nil
+}
+func (r repoStub) CreateBatch(ctx context.Context, bookings []*Booking, actorID int64, opts BatchOptions) ([]error, error) {
+	errs := make([]error, len(bookings))
+	used, failed := r.used, false
+	for i, b := range bookings {
+		if used+b.Seats > r.capacity {
+			errs[i], failed = ErrNotEnoughSeats, true
+			continue
+		}
+		used += b.Seats
+		b.ID = int64(i + 1)
+	}
+	if opts.Atomic && failed {
+		for _, b := range bookings {
+			b.ID = 0
+		}
+	}
+	return errs, nil
+}
 func (r repoStub) GetByID(ctx context.Context, id int64) (*Booking, error) {
+	if r.booking != nil {
+		if r.booking.ID != id {
+			return nil, ErrNotFound
+		}
+		b := *r.booking
+		return &b, nil
+	}
 	return &Booking{ID: id}, nil
 }
+func (r repoStub) UpdateSeats(ctx context.Context, id int64, seats int, actorID int64) (*Booking, error) {
+	b := *r.booking
+	if b.PromoCodeID != nil {
+		return nil, ErrPaidSeatsChange
+	}
+	if seats > b.Seats && r.used-b.Seats+seats > r.capacity {
+		return nil, ErrNotEnoughSeats
+	}
+	b.Seats = seats
+	return &b, nil
+}
 func (r repoStub) ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]Booking, error) {
 	return nil, nil
 }
-func (r repoStub) Cancel(ctx context.Context, id int64) error { return nil }
-func (r repoStub) CountConfirmedSeats(ctx context.Context, eventID int64) (int, error) {
+func (r repoStub) ListByUser(ctx context.Context, userID int64, f ListFilter) ([]UserBooking, error) {
+	return []UserBooking{{Booking: Booking{UserID: userID, Seats: f.Limit}}}, nil
+}
+func (r repoStub) Transition(ctx context.Context, id int64, to Status, actorID int64, reason string, check func(b *Booking) error) (*Booking, error) {
+	if r.booking == nil || r.booking.ID != id {
+		return nil, ErrNotFound
+	}
+	if err := check(r.booking); err != nil {
+		return nil, err
+	}
+	r.booking.Status = to
+	b := *r.booking
+	return &b, nil
+}
+func (r repoStub) CheckIn(ctx context.Context, id, attendeeID, staffID int64, check func(b *Booking, a *BookingAttendee) (int, error)) (*Booking, error) {
+	if r.booking == nil || r.booking.ID != id {
+		return nil, ErrNotFound
+	}
+	var a *BookingAttendee
+	if attendeeID != 0 {
+		i := slices.IndexFunc(r.attendees, func(a BookingAttendee) bool { return a.ID == attendeeID })
+		if i < 0 {
+			return nil, ErrInvalidTicket
+		}
+		a = &r.attendees[i]
+	}
+	seats, err := check(r.booking, a)
+	if err != nil {
+		return nil, err
+	}
+	if a != nil {
+		now := time.Now()
+		a.CheckedInAt = &now
+	}
+	r.booking.CheckedInSeats += seats
+	if r.booking.CheckedInSeats == r.booking.Seats {
+		r.booking.Status = StatusCheckedIn
+	}
+	b := *r.booking
+	return &b, nil
+}
+func (r repoStub) ListAttendees(ctx context.Context, bookingID int64) ([]BookingAttendee, error) {
+	return r.attendees, nil
+}
+func (r repoStub) ReplaceAttendees(ctx context.Context, bookingID int64, list []BookingAttendee, check func(b *Booking, current []BookingAttendee) error) ([]BookingAttendee, error) {
+	if err := check(r.booking, r.attendees); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].ID, list[i].BookingID = int64(i+1), bookingID
+	}
+	return list, nil
+}
+func (r repoStub) ClaimAttendees(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	return nil, nil
+}
+func (r repoStub) ListAttendeesByUser(ctx context.Context, userID int64) ([]BookingAttendee, error) {
+	return nil, nil
+}
+func (r repoStub) CreateTransfer(ctx context.Context, t *Transfer, check func(b *Booking) error) error {
+	if err := check(r.booking); err != nil {
+		return err
+	}
+	t.ID, t.Status, t.FromUserID = 1, TransferPending, r.booking.UserID
+	*r.transfer = *t
+	return nil
+}
+func (r repoStub) GetTransferByHash(ctx context.Context, hash string) (*Transfer, error) {
+	if r.transfer == nil || r.transfer.TokenHash != hash {
+		return nil, ErrTransferNotFound
+	}
+	t := *r.transfer
+	return &t, nil
+}
+func (r repoStub) AcceptTransfer(ctx context.Context, transferID, userID int64, check func(b *Booking, t *Transfer) error) (*Booking, error) {
+	if userID != r.recipientID {
+		return nil, ErrTransferRecipient
+	}
+	if err := check(r.booking, r.transfer); err != nil {
+		return nil, err
+	}
+	r.booking.UserID = userID
+	r.booking.TicketVersion++
+	r.transfer.Status = TransferAccepted
+	b := *r.booking
+	return &b, nil
+}
+func (r repoStub) EachAttendee(ctx context.Context, eventID int64, fn func(a *Attendee) error) error {
+	return nil
+}
+func (r repoStub) CountOccupiedSeats(ctx context.Context, eventID int64) (int, error) {
 	return r.used, nil
 }
+func (r repoStub) CreatePayment(ctx context.Context, p *Payment) error {
+	p.ID = 1
+	*r.payment = *p
+	return nil
+}
+func (r repoStub) UpdatePayment(ctx context.Context, provider, intentID string, check func(b *Booking, p *Payment) (Status, PaymentStatus, error)) (*Booking, *Payment, error) {
+	if r.payment == nil || r.payment.Provider != provider || r.payment.IntentID != intentID {
+		return nil, nil, ErrPaymentNotFound
+	}
+	to, next, err := check(r.booking, r.payment)
+	if err != nil {
+		return nil, nil, err
+	}
+	if to != "" {
+		r.booking.Status = to
+	}
+	r.payment.Status = next
+	b, p := *r.booking, *r.payment
+	return &b, &p, nil
+}
+func (r repoStub) ExpirePending(ctx context.Context, now time.Time) ([]Booking, []Payment, error) {
+	if r.booking == nil || r.booking.Status != StatusPending || r.booking.ExpiresAt.After(now) {
+		return nil, nil, nil
+	}
+	r.booking.Status = StatusCancelled
+	if r.payment == nil || (r.payment.Status != PaymentPending && r.payment.Status != PaymentFailed) {
+		return []Booking{*r.booking}, nil, nil
+	}
+	r.payment.Status = PaymentCancelled
+	return []Booking{*r.booking}, []Payment{*r.payment}, nil
+}
+func (r repoStub) Cancel(ctx context.Context, id, actorID int64, reason string, check func(b *Booking, p *Payment) (int64, error)) (*Booking, *Refund, error) {
+	if r.booking == nil || r.booking.ID != id {
+		return nil, nil, ErrNotFound
+	}
+	amount, err := check(r.booking, r.payment)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.booking.Status = StatusCancelled
+	b := *r.booking
+	if r.payment != nil && r.payment.Status != PaymentCaptured {
+		r.payment.Status = PaymentCancelled
+	}
+	if r.payment == nil || r.payment.Status != PaymentCaptured || amount == 0 {
+		return &b, nil, nil
+	}
+	*r.refund = Refund{ID: 1, BookingID: id, PaymentID: r.payment.ID, IntentID: r.payment.IntentID, Amount: amount, Status: RefundRequested}
+	refund := *r.refund
+	return &b, &refund, nil
+}
+func (r repoStub) SaveRefundAttempt(ctx context.Context, id int64, status RefundStatus, providerRefundID, lastError string) (*Refund, error) {
+	if r.refund.Status == RefundRequested {
+		r.refund.Status, r.refund.ProviderRefundID, r.refund.LastError = status, providerRefundID, lastError
+		r.refund.Attempts++
+		if status == RefundSucceeded && r.booking.Status == StatusCancelled {
+			r.booking.Status = StatusRefunded
+		}
+	}
+	refund := *r.refund
+	return &refund, nil
+}
+func (r repoStub) ListRefundsByEvent(ctx context.Context, eventID int64) ([]Refund, error) {
+	return []Refund{*r.refund}, nil
+}
+func (r repoStub) RequestRefund(ctx context.Context, bookingID, paymentID, amount int64) (*Refund, error) {
+	if r.refund.ID == 0 {
+		*r.refund = Refund{ID: 1, BookingID: bookingID, PaymentID: paymentID, IntentID: r.payment.IntentID, Amount: amount, Status: RefundRequested}
+	}
+	refund := *r.refund
+	return &refund, nil
+}
+func (r repoStub) ListStaleRefunds(ctx context.Context, before time.Time) ([]Refund, error) {
+	if r.refund == nil || r.refund.Status != RefundRequested {
+		return nil, nil
+	}
+	return []Refund{*r.refund}, nil
+}
 
 func TestService_Create_CapacityExceeded(t *testing.T) {
-	svc := NewService(repoStub{used: 9})
+	svc := NewService(repoStub{used: 9}, Options{Events: eventsStub{}})
 	_, err := svc.Create(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 2}, 10)
-	if err == nil {
-		t.Fatal("expected not enough seats error")
+	if !errors.Is(err, ErrNotEnoughSeats) {
+		t.Fatalf("expected ErrNotEnoughSeats, got %v", err)
 	}
 }
 
 func TestService_Create_Success(t *testing.T) {
-	svc := NewService(repoStub{used: 5})
+	svc := NewService(repoStub{used: 5}, Options{Events: eventsStub{}})
 	id, err := svc.Create(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 3}, 10)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -39,3 +280,720 @@ func TestService_Create_Success(t *testing.T) {
 		t.Fatal("expected non-zero id")
 	}
 }
+
+func TestService_CreateBatch(t *testing.T) {
+	batch := func() []*Booking {
+		return []*Booking{
+			{EventID: 1, UserID: 1, Seats: 2},
+			{EventID: 1, UserID: 2, Seats: 0},
+			{EventID: 1, UserID: 3, Seats: 5},
+		}
+	}
+	svc := NewService(repoStub{used: 5, capacity: 10}, Options{Events: eventsStub{}})
+
+	// атомарно: невалидная строка — репозиторий не вызывается, ничего не создано
+	bookings := batch()
+	errs, err := svc.CreateBatch(context.Background(), bookings, 99, BatchOptions{Atomic: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("unexpected row errors: %v", errs)
+	}
+	for _, b := range bookings {
+		if b.ID != 0 {
+			t.Fatalf("atomic batch with invalid row must not create bookings, got id %d", b.ID)
+		}
+	}
+
+	// по возможности: создаётся то, что влезает
+	bookings = batch()
+	errs, err = svc.CreateBatch(context.Background(), bookings, 99, BatchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bookings[0].ID == 0 || errs[0] != nil {
+		t.Fatalf("first booking should be created, err %v", errs[0])
+	}
+	if errs[1] == nil || bookings[1].ID != 0 {
+		t.Fatal("invalid booking must be rejected")
+	}
+	if !errors.Is(errs[2], ErrNotEnoughSeats) || bookings[2].ID != 0 {
+		t.Fatalf("expected ErrNotEnoughSeats for third booking, got %v", errs[2])
+	}
+}
+
+func TestService_ListByUser(t *testing.T) {
+	svc := NewService(repoStub{}, Options{Events: eventsStub{}})
+	if _, err := svc.ListByUser(context.Background(), 1, ListFilter{Status: "archived"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+	list, err := svc.ListByUser(context.Background(), 1, ListFilter{Status: FilterUpcoming, Limit: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// лимит ограничивается так же, как в ListByEvent
+	if len(list) != 1 || list[0].Seats != 20 {
+		t.Fatalf("unexpected result %+v", list)
+	}
+}
+
+func TestService_ChangeSeats(t *testing.T) {
+	repo := repoStub{used: 8, capacity: 10, booking: &Booking{ID: 1, UserID: 5, Seats: 2, Status: StatusConfirmed}}
+	svc := NewService(repo, Options{Events: eventsStub{}})
+	ctx := context.Background()
+
+	if _, err := svc.ChangeSeats(ctx, 1, 6, 3); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+	if _, err := svc.ChangeSeats(ctx, 2, 5, 3); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.ChangeSeats(ctx, 1, 5, 0); err == nil {
+		t.Fatal("expected error for zero seats")
+	}
+	if _, err := svc.ChangeSeats(ctx, 1, 5, 5); !errors.Is(err, ErrNotEnoughSeats) {
+		t.Fatalf("expected ErrNotEnoughSeats, got %v", err)
+	}
+	b, err := svc.ChangeSeats(ctx, 1, 5, 4)
+	if err != nil || b.Seats != 4 {
+		t.Fatalf("expected 4 seats, got %+v, %v", b, err)
+	}
+	if b, err = svc.ChangeSeats(ctx, 1, 5, 1); err != nil || b.Seats != 1 {
+		t.Fatalf("expected 1 seat, got %+v, %v", b, err)
+	}
+}
+
+func TestService_ChangeSeats_Paid(t *testing.T) {
+	ctx := context.Background()
+	paid := eventsStub{event: &event.Event{ID: 1, Price: 50000, StartsAt: time.Now().Add(time.Hour)}}
+	repo := repoStub{capacity: 10, booking: &Booking{ID: 1, EventID: 1, UserID: 5, Seats: 1, Status: StatusConfirmed}}
+	svc := NewService(repo, Options{Events: paid})
+	// оплачено одно место: остальные бесплатно не добавить, а уменьшение оставило бы возврат на прежнюю сумму
+	if _, err := svc.ChangeSeats(ctx, 1, 5, 10); !errors.Is(err, ErrPaidSeatsChange) {
+		t.Fatalf("expected ErrPaidSeatsChange, got %v", err)
+	}
+
+	promoID := int64(3)
+	repo.booking = &Booking{ID: 1, EventID: 1, UserID: 5, Seats: 2, Status: StatusConfirmed, PromoCodeID: &promoID, Discount: 100}
+	svc = NewService(repo, Options{Events: eventsStub{}})
+	// скидка посчитана на исходные места
+	if _, err := svc.ChangeSeats(ctx, 1, 5, 1); !errors.Is(err, ErrPaidSeatsChange) {
+		t.Fatalf("expected ErrPaidSeatsChange for promo booking, got %v", err)
+	}
+}
+
+func TestService_Cancel(t *testing.T) {
+	repo := repoStub{booking: &Booking{ID: 1, UserID: 5, Seats: 2, Status: StatusConfirmed}}
+	svc := NewService(repo, Options{Events: eventsStub{}})
+	ctx := context.Background()
+
+	if _, err := svc.Cancel(ctx, 2, 5, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.Cancel(ctx, 1, 6, ""); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+	if _, err := svc.Cancel(ctx, 1, 5, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.booking.Status != StatusCancelled {
+		t.Fatalf("expected cancelled, got %s", repo.booking.Status)
+	}
+	if _, err := svc.Cancel(ctx, 1, 5, ""); !errors.Is(err, ErrAlreadyInStatus) {
+		t.Fatalf("expected ErrAlreadyInStatus, got %v", err)
+	}
+}
+
+func TestService_Transition(t *testing.T) {
+	cases := []struct {
+		from, to Status
+		want     error
+	}{
+		{StatusPending, StatusConfirmed, nil},
+		{StatusConfirmed, StatusCheckedIn, nil},
+		{StatusConfirmed, StatusNoShow, nil},
+		{StatusCancelled, StatusRefunded, nil},
+		{StatusCheckedIn, StatusCancelled, ErrInvalidTransition},
+		{StatusRefunded, StatusConfirmed, ErrInvalidTransition},
+		{StatusCancelled, StatusConfirmed, ErrInvalidTransition},
+		{StatusNoShow, StatusNoShow, ErrAlreadyInStatus},
+		{StatusConfirmed, "archived", ErrInvalidStatus},
+	}
+	for _, c := range cases {
+		repo := repoStub{booking: &Booking{ID: 1, UserID: 5, Status: c.from}}
+		svc := NewService(repo, Options{Events: eventsStub{}})
+		_, err := svc.Transition(context.Background(), 1, c.to, 5, "test")
+		if !errors.Is(err, c.want) {
+			t.Fatalf("%s -> %s: expected %v, got %v", c.from, c.to, c.want, err)
+		}
+	}
+}
+
+func TestService_Cancel_Policy(t *testing.T) {
+	policy := event.CancellationPolicy{{HoursBefore: 48, RefundPercent: 100}, {HoursBefore: 24, RefundPercent: 50}}
+	cases := []struct {
+		startsIn time.Duration
+		percent  int
+		wantErr  error
+	}{
+		{72 * time.Hour, 100, nil},
+		{30 * time.Hour, 50, nil},
+		{10 * time.Hour, 0, ErrCancellationClosed},
+		{-time.Hour, 0, ErrCancellationClosed},
+	}
+	for _, c := range cases {
+		// бронь оформлена до появления оплаты: сумма считается по цене события
+		repo := repoStub{booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed, LegacyPaid: true}}
+		events := eventsStub{event: &event.Event{ID: 3, Price: 1500, StartsAt: time.Now().Add(c.startsIn), CancellationPolicy: policy}}
+		svc := NewService(repo, Options{Events: events})
+
+		got, err := svc.Cancel(context.Background(), 1, 5, "")
+		if !errors.Is(err, c.wantErr) {
+			t.Fatalf("starts in %s: expected %v, got %v", c.startsIn, c.wantErr, err)
+		}
+		if err != nil {
+			if repo.booking.Status != StatusConfirmed {
+				t.Fatalf("starts in %s: rejected cancellation changed status to %s", c.startsIn, repo.booking.Status)
+			}
+			continue
+		}
+		want := int64(3000 * c.percent / 100)
+		if got.RefundPercent != c.percent || got.Amount != 3000 || got.Refund != want || got.Fee != 3000-want {
+			t.Fatalf("starts in %s: unexpected cancellation %+v", c.startsIn, got)
+		}
+	}
+}
+
+func TestService_Cancel_Complimentary(t *testing.T) {
+	// бесплатная бронь из импорта на платное событие: платежа нет, и возвращать нечего
+	repo := repoStub{booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed}}
+	events := eventsStub{event: &event.Event{ID: 3, Price: 1500, StartsAt: time.Now().Add(72 * time.Hour)}}
+	c, err := NewService(repo, Options{Events: events}).Cancel(context.Background(), 1, 5, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Amount != 0 || c.Refund != 0 || c.Fee != 0 || c.RefundID != 0 {
+		t.Fatalf("expected nothing paid or refunded, got %+v", c)
+	}
+}
+
+func TestService_CheckIn(t *testing.T) {
+	repo := repoStub{booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 3, Status: StatusConfirmed}}
+	svc := NewService(repo, Options{Events: eventsStub{}, TicketSecret: "secret"})
+	ctx := context.Background()
+
+	if _, err := svc.Ticket(ctx, 1, 6); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+	code, err := svc.Ticket(ctx, 1, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.CheckIn(ctx, 3, code+"x", 1, 9); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("expected ErrInvalidTicket for tampered code, got %v", err)
+	}
+	forged, _ := Ticket{BookingID: 1, EventID: 3, Seats: 3}.sign("other")
+	if _, err := svc.CheckIn(ctx, 3, forged, 1, 9); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("expected ErrInvalidTicket for foreign signature, got %v", err)
+	}
+	if _, err := svc.CheckIn(ctx, 4, code, 1, 9); !errors.Is(err, ErrTicketWrongEvent) {
+		t.Fatalf("expected ErrTicketWrongEvent, got %v", err)
+	}
+
+	res, err := svc.CheckIn(ctx, 3, code, 2, 9)
+	if err != nil || res.Admitted != 2 || res.Remaining != 1 || res.Status != StatusConfirmed {
+		t.Fatalf("partial check-in: got %+v, %v", res, err)
+	}
+	if _, err := svc.CheckIn(ctx, 3, code, 2, 9); !errors.Is(err, ErrCheckInSeats) {
+		t.Fatalf("expected ErrCheckInSeats, got %v", err)
+	}
+	res, err = svc.CheckIn(ctx, 3, code, 0, 9)
+	if err != nil || res.Admitted != 1 || res.Remaining != 0 || res.Status != StatusCheckedIn {
+		t.Fatalf("final check-in: got %+v, %v", res, err)
+	}
+	if _, err := svc.CheckIn(ctx, 3, code, 0, 9); !errors.Is(err, ErrAlreadyCheckedIn) {
+		t.Fatalf("expected ErrAlreadyCheckedIn, got %v", err)
+	}
+	if _, err := svc.Ticket(ctx, 1, 5); !errors.Is(err, ErrTicketUnavailable) {
+		t.Fatalf("expected ErrTicketUnavailable after check-in, got %v", err)
+	}
+}
+
+func TestService_CheckIn_StaleTicket(t *testing.T) {
+	repo := repoStub{booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed}}
+	svc := NewService(repo, Options{Events: eventsStub{}, TicketSecret: "secret"})
+	code, err := svc.Ticket(context.Background(), 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// после смены мест старый билет не действует
+	repo.booking.Seats = 3
+	if _, err := svc.CheckIn(context.Background(), 3, code, 0, 9); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("expected ErrInvalidTicket, got %v", err)
+	}
+}
+
+func TestService_ReplaceAttendees(t *testing.T) {
+	checkedIn := time.Now()
+	repo := repoStub{
+		booking:   &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed},
+		attendees: []BookingAttendee{{ID: 1, BookingID: 1, Name: "Анна", Email: "anna@example.com", CheckedInAt: &checkedIn}},
+	}
+	soon := eventsStub{event: &event.Event{ID: 3, StartsAt: time.Now().Add(2 * time.Hour)}}
+	svc := NewService(repo, Options{Events: eventsStub{}, AttendeeEditCutoff: 30 * time.Minute})
+	ctx := context.Background()
+
+	got, err := svc.ReplaceAttendees(ctx, 1, 5, []AttendeeInput{
+		{Name: " Анна ", Email: "Anna@Example.com"},
+		{Name: "Борис", Email: "boris@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "Анна" || got[0].Email != "anna@example.com" {
+		t.Fatalf("attendees must be normalized, got %+v", got)
+	}
+
+	cases := []struct {
+		name  string
+		svc   Service
+		actor int64
+		list  []AttendeeInput
+		want  error
+	}{
+		{"not owner", svc, 6, nil, ErrNotOwner},
+		{"more than seats", svc, 5, []AttendeeInput{{"Анна", "anna@example.com"}, {"Борис", "b@example.com"}, {"Вера", "v@example.com"}}, ErrTooManyAttendees},
+		{"duplicate email", svc, 5, []AttendeeInput{{"Анна", "anna@example.com"}, {"Аня", "ANNA@example.com"}}, ErrInvalidAttendees},
+		{"no name", svc, 5, []AttendeeInput{{"", "anna@example.com"}}, ErrInvalidAttendees},
+		{"bad email", svc, 5, []AttendeeInput{{"Анна", "anna"}}, ErrInvalidAttendees},
+		{"checked in removed", svc, 5, []AttendeeInput{{"Борис", "boris@example.com"}}, ErrNotModifiable},
+		{"after cutoff", NewService(repo, Options{Events: soon, AttendeeEditCutoff: 3 * time.Hour}), 5, nil, ErrAttendeesLocked},
+	}
+	for _, c := range cases {
+		if _, err := c.svc.ReplaceAttendees(ctx, 1, c.actor, c.list); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestService_CheckIn_AttendeeTicket(t *testing.T) {
+	repo := repoStub{
+		booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed},
+		attendees: []BookingAttendee{
+			{ID: 7, BookingID: 1, Name: "Анна", Email: "anna@example.com", UserID: 8},
+			{ID: 9, BookingID: 1, Name: "Борис", Email: "boris@example.com"},
+		},
+	}
+	svc := NewService(repo, Options{Events: eventsStub{}, TicketSecret: "secret"})
+	ctx := context.Background()
+
+	// билет посетителя получает владелец брони и аккаунт, закрепивший место
+	code, err := svc.AttendeeTicket(ctx, 1, 7, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.AttendeeTicket(ctx, 1, 9, 8); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner for another attendee, got %v", err)
+	}
+	if _, err := svc.AttendeeTicket(ctx, 1, 10, 5); !errors.Is(err, ErrAttendeeNotFound) {
+		t.Fatalf("expected ErrAttendeeNotFound, got %v", err)
+	}
+
+	res, err := svc.CheckIn(ctx, 3, code, 0, 99)
+	if err != nil || res.Admitted != 1 || res.AttendeeID != 7 || res.Remaining != 1 {
+		t.Fatalf("attendee check-in: got %+v, %v", res, err)
+	}
+	if _, err := svc.CheckIn(ctx, 3, code, 0, 99); !errors.Is(err, ErrAlreadyCheckedIn) {
+		t.Fatalf("expected ErrAlreadyCheckedIn on second scan, got %v", err)
+	}
+
+	// посетителя убрали из брони — его билет не действует
+	stale, err := svc.AttendeeTicket(ctx, 1, 9, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.attendees[1].ID = 11
+	if _, err := svc.CheckIn(ctx, 3, stale, 0, 99); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("expected ErrInvalidTicket, got %v", err)
+	}
+}
+
+func TestService_Transfer(t *testing.T) {
+	repo := repoStub{
+		booking:     &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed},
+		transfer:    &Transfer{},
+		recipientID: 8,
+	}
+	events := eventsStub{event: &event.Event{ID: 3, Title: "Go Meetup", StartsAt: time.Now().Add(48 * time.Hour), TransferCutoffHours: 24}}
+	mail := mailer.NewMemoryMailer("noreply@example.com")
+	svc := NewService(repo, Options{Events: events, TicketSecret: "secret", Mailer: mail, TransferURL: "http://localhost/accept"})
+	ctx := context.Background()
+
+	oldTicket, err := svc.Ticket(ctx, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RequestTransfer(ctx, 1, 6, "colleague@example.com"); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+	if _, err := svc.RequestTransfer(ctx, 1, 5, "colleague"); err == nil {
+		t.Fatal("expected error for invalid email")
+	}
+	tr, err := svc.RequestTransfer(ctx, 1, 5, " Colleague@Example.com ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.ToEmail != "colleague@example.com" || tr.Status != TransferPending || !tr.ExpiresAt.After(time.Now()) {
+		t.Fatalf("unexpected transfer %+v", tr)
+	}
+
+	msgs := mail.Messages()
+	if len(msgs) != 1 || msgs[0].To != "colleague@example.com" {
+		t.Fatalf("expected one email to the recipient, got %+v", msgs)
+	}
+	_, rest, _ := strings.Cut(msgs[0].Body, "?token=")
+	token, err := url.QueryUnescape(strings.Fields(rest)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.AcceptTransfer(ctx, token+"x", 8); !errors.Is(err, ErrTransferNotFound) {
+		t.Fatalf("expected ErrTransferNotFound for tampered token, got %v", err)
+	}
+	if _, err := svc.AcceptTransfer(ctx, token, 7); !errors.Is(err, ErrTransferRecipient) {
+		t.Fatalf("expected ErrTransferRecipient, got %v", err)
+	}
+	b, err := svc.AcceptTransfer(ctx, token, 8)
+	if err != nil || b.UserID != 8 {
+		t.Fatalf("accept: got %+v, %v", b, err)
+	}
+	if _, err := svc.AcceptTransfer(ctx, token, 8); !errors.Is(err, ErrTransferNotFound) {
+		t.Fatalf("expected ErrTransferNotFound on second accept, got %v", err)
+	}
+
+	// билет прежнего владельца больше не действует, новый выдаётся получателю
+	if _, err := svc.CheckIn(ctx, 3, oldTicket, 0, 99); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("expected ErrInvalidTicket for the old ticket, got %v", err)
+	}
+	if _, err := svc.Ticket(ctx, 1, 5); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("expected ErrNotOwner for the previous owner, got %v", err)
+	}
+	newTicket, err := svc.Ticket(ctx, 1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CheckIn(ctx, 3, newTicket, 1, 99); err != nil {
+		t.Fatalf("new ticket: unexpected error %v", err)
+	}
+	// после прохода на вход бронь уже не передать
+	if _, err := svc.RequestTransfer(ctx, 1, 8, "other@example.com"); !errors.Is(err, ErrNotModifiable) {
+		t.Fatalf("expected ErrNotModifiable after check-in, got %v", err)
+	}
+}
+
+func TestService_Transfer_Cutoff(t *testing.T) {
+	repo := repoStub{booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed}, transfer: &Transfer{}}
+	events := eventsStub{event: &event.Event{ID: 3, StartsAt: time.Now().Add(12 * time.Hour), TransferCutoffHours: 24}}
+	svc := NewService(repo, Options{Events: events, TicketSecret: "secret"})
+	if _, err := svc.RequestTransfer(context.Background(), 1, 5, "colleague@example.com"); !errors.Is(err, ErrTransferClosed) {
+		t.Fatalf("expected ErrTransferClosed, got %v", err)
+	}
+}
+
+func TestEventSeats_CheckLimits(t *testing.T) {
+	e := eventSeats{MaxSeatsPerBooking: 2, MaxSeatsPerUser: 4, OneBookingPerUser: false}
+	cases := []struct {
+		name                     string
+		seats, userSeats, booked int
+		want                     error
+	}{
+		{"within limits", 2, 2, 1, nil},
+		{"booking too large", 3, 0, 0, ErrBookingSeatsLimit},
+		{"user total exceeded", 2, 3, 2, ErrUserSeatsLimit},
+	}
+	for _, c := range cases {
+		if err := e.checkLimits(c.seats, c.userSeats, c.booked); !errors.Is(err, c.want) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+
+	e = eventSeats{OneBookingPerUser: true}
+	if err := e.checkLimits(1, 1, 1); !errors.Is(err, ErrOneBookingPerUser) {
+		t.Fatalf("expected ErrOneBookingPerUser, got %v", err)
+	}
+	if err := (eventSeats{}).checkLimits(100, 100, 5); err != nil {
+		t.Fatalf("zero limits must not restrict, got %v", err)
+	}
+	if !IsLimitError(fmt.Errorf("create: %w", ErrUserSeatsLimit)) || IsLimitError(ErrNotEnoughSeats) {
+		t.Fatal("IsLimitError must match only limit errors")
+	}
+}
+
+func TestService_Checkout(t *testing.T) {
+	provider := payment.NewFake("whsec")
+	repo := repoStub{capacity: 10, payment: &Payment{}}
+	svc := NewService(repo, Options{Payments: provider, PaymentTTL: 10 * time.Minute})
+	e := &event.Event{ID: 1, Capacity: 10, Price: 150000}
+
+	b := &Booking{EventID: 1, UserID: 1, Seats: 2}
+	c, err := svc.Checkout(context.Background(), b, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.BookingID != 1 || c.Status != StatusPending || c.Amount != 300000 || c.Currency != "rub" || c.ClientSecret == "" {
+		t.Fatalf("unexpected checkout %+v", c)
+	}
+	if b.Status != StatusPending || b.ExpiresAt == nil || time.Until(*b.ExpiresAt) > 10*time.Minute {
+		t.Fatalf("booking must wait for payment, got %+v", b)
+	}
+	if p := repo.payment; p.BookingID != 1 || p.Provider != payment.DriverFake || p.Amount != 300000 || p.Status != PaymentPending {
+		t.Fatalf("unexpected payment %+v", p)
+	}
+
+	if _, err := svc.Checkout(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 1}, &event.Event{ID: 1, Capacity: 10}); !errors.Is(err, ErrFreeEvent) {
+		t.Fatalf("expected ErrFreeEvent, got %v", err)
+	}
+	noPayments := NewService(repo, Options{})
+	if _, err := noPayments.Checkout(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 1}, e); !errors.Is(err, ErrPaymentsDisabled) {
+		t.Fatalf("expected ErrPaymentsDisabled, got %v", err)
+	}
+}
+
+func TestService_HandlePayment(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("whsec")
+	var intent *payment.Intent
+	setup := func(expiresIn time.Duration) (repoStub, Service) {
+		intent, _ = provider.CreateIntent(ctx, payment.IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub"})
+		expiresAt := time.Now().Add(expiresIn)
+		repo := repoStub{
+			booking: &Booking{ID: 1, EventID: 1, UserID: 1, Seats: 1, Status: StatusPending, ExpiresAt: &expiresAt},
+			payment: &Payment{ID: 1, BookingID: 1, Provider: payment.DriverFake, IntentID: intent.ID, Amount: 3000, Status: PaymentPending},
+		}
+		return repo, NewService(repo, Options{Payments: provider})
+	}
+	webhook := func(typ string) *payment.WebhookEvent {
+		return &payment.WebhookEvent{ID: "evt", Type: typ, IntentID: intent.ID, Amount: 3000}
+	}
+
+	repo, svc := setup(time.Minute)
+	// неудачная попытка не отменяет бронь: клиент может оплатить ещё раз до срока
+	if _, err := svc.HandlePayment(ctx, webhook(payment.EventFailed)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.booking.Status != StatusPending || repo.payment.Status != PaymentFailed {
+		t.Fatalf("expected pending booking after failed attempt, got %s/%s", repo.booking.Status, repo.payment.Status)
+	}
+	b, err := svc.HandlePayment(ctx, webhook(payment.EventAuthorized))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Status != StatusConfirmed || repo.payment.Status != PaymentCaptured || !provider.Captured(intent.ID) {
+		t.Fatalf("expected confirmed booking with captured payment, got %s/%s", b.Status, repo.payment.Status)
+	}
+	// повтор уведомления ничего не меняет
+	if b, err := svc.HandlePayment(ctx, webhook(payment.EventSucceeded)); err != nil || b.Status != StatusConfirmed {
+		t.Fatalf("repeated webhook: %v, %+v", err, b)
+	}
+
+	repo, svc = setup(-time.Second)
+	if _, err := svc.HandlePayment(ctx, webhook(payment.EventAuthorized)); !errors.Is(err, ErrPaymentExpired) {
+		t.Fatalf("expected ErrPaymentExpired, got %v", err)
+	}
+	if repo.booking.Status != StatusCancelled || repo.payment.Status != PaymentCancelled {
+		t.Fatalf("late payment must not confirm booking, got %s/%s", repo.booking.Status, repo.payment.Status)
+	}
+	// заблокированные деньги освобождаются отменой платежа у провайдера
+	if !provider.Cancelled(intent.ID) || provider.Captured(intent.ID) {
+		t.Fatal("late authorization must be voided, not captured")
+	}
+
+	repo, svc = setup(time.Minute)
+	if _, err := svc.HandlePayment(ctx, webhook(payment.EventCancelled)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.booking.Status != StatusCancelled {
+		t.Fatalf("cancelled payment must release the booking, got %s", repo.booking.Status)
+	}
+
+	_, svc = setup(time.Minute)
+	wrong := webhook(payment.EventAuthorized)
+	wrong.Amount = 1
+	if _, err := svc.HandlePayment(ctx, wrong); err == nil {
+		t.Fatal("expected error for amount mismatch")
+	}
+	wrong = webhook(payment.EventAuthorized)
+	wrong.IntentID = "pi_other"
+	if _, err := svc.HandlePayment(ctx, wrong); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+	}
+}
+
+func TestService_HandlePayment_LateCaptureRefunded(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("whsec")
+	intent, _ := provider.CreateIntent(ctx, payment.IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub"})
+	provider.Capture(ctx, intent.ID)
+	repo := repoStub{
+		booking: &Booking{ID: 1, EventID: 1, UserID: 1, Seats: 1, Status: StatusCancelled},
+		payment: &Payment{ID: 1, BookingID: 1, Provider: payment.DriverFake, IntentID: intent.ID, Amount: 3000, Status: PaymentCancelled},
+		refund:  &Refund{},
+	}
+	svc := NewService(repo, Options{Payments: provider, RefundAttempts: 3, RefundBackoff: time.Millisecond})
+	webhook := &payment.WebhookEvent{ID: "evt", Type: payment.EventSucceeded, IntentID: intent.ID, Amount: 3000}
+
+	if _, err := svc.HandlePayment(ctx, webhook); !errors.Is(err, ErrPaymentExpired) {
+		t.Fatalf("expected ErrPaymentExpired, got %v", err)
+	}
+	if repo.refund.Status != RefundSucceeded || repo.refund.Amount != 3000 || repo.booking.Status != StatusRefunded {
+		t.Fatalf("late capture must be refunded, got %+v, booking %s", repo.refund, repo.booking.Status)
+	}
+	// повтор уведомления не создаёт второй возврат
+	if _, err := svc.HandlePayment(ctx, webhook); !errors.Is(err, ErrPaymentExpired) {
+		t.Fatalf("expected ErrPaymentExpired, got %v", err)
+	}
+	if refunds := provider.Refunds(); len(refunds) != 1 || refunds[0].Amount != 3000 {
+		t.Fatalf("expected single refund of 3000, got %+v", refunds)
+	}
+}
+
+func TestService_ExpirePending(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("whsec")
+	intent, _ := provider.CreateIntent(ctx, payment.IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub"})
+	expiresAt := time.Now().Add(-time.Minute)
+	repo := repoStub{
+		booking: &Booking{ID: 1, EventID: 1, Seats: 2, Status: StatusPending, ExpiresAt: &expiresAt},
+		payment: &Payment{ID: 1, BookingID: 1, Provider: payment.DriverFake, IntentID: intent.ID, Amount: 3000, Status: PaymentPending},
+	}
+	svc := NewService(repo, Options{Payments: provider})
+
+	expired, err := svc.ExpirePending(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Status != StatusCancelled {
+		t.Fatalf("expected the booking to expire, got %+v", expired)
+	}
+	if !provider.Cancelled(intent.ID) {
+		t.Fatal("expired booking payment must be voided at the provider")
+	}
+}
+
+func TestService_Cancel_Refund(t *testing.T) {
+	ctx := context.Background()
+	policy := event.CancellationPolicy{{HoursBefore: 24, RefundPercent: 50}}
+	events := eventsStub{event: &event.Event{ID: 3, Price: 1500, StartsAt: time.Now().Add(48 * time.Hour), CancellationPolicy: policy}}
+	setup := func(provider *payment.Fake) repoStub {
+		intent, _ := provider.CreateIntent(ctx, payment.IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub"})
+		return repoStub{
+			booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusConfirmed},
+			payment: &Payment{ID: 1, BookingID: 1, IntentID: intent.ID, Amount: 3000, Status: PaymentCaptured},
+			refund:  &Refund{},
+		}
+	}
+	opts := func(provider *payment.Fake) Options {
+		return Options{Events: events, Payments: provider, RefundAttempts: 3, RefundBackoff: time.Millisecond}
+	}
+
+	// временные ошибки провайдера повторяются, а возврат проводится один раз
+	provider := payment.NewFake("whsec")
+	provider.FailRefunds(2, errors.New("connection reset"))
+	repo := setup(provider)
+	c, err := NewService(repo, opts(provider)).Cancel(ctx, 1, 5, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Refund != 1500 || c.RefundID != 1 || c.RefundStatus != RefundRequested {
+		t.Fatalf("unexpected cancellation %+v", c)
+	}
+	if repo.refund.Status != RefundSucceeded || repo.refund.Attempts != 3 || repo.booking.Status != StatusRefunded {
+		t.Fatalf("expected refunded booking after 3 attempts, got %+v, booking %s", repo.refund, repo.booking.Status)
+	}
+	if refunds := provider.Refunds(); len(refunds) != 1 || refunds[0].Amount != 1500 {
+		t.Fatalf("expected single refund of 1500, got %+v", refunds)
+	}
+
+	// закончились попытки: возврат остаётся на ручной разбор, бронь — отменённой
+	provider = payment.NewFake("whsec")
+	provider.FailRefunds(3, errors.New("connection reset"))
+	repo = setup(provider)
+	if _, err := NewService(repo, opts(provider)).Cancel(ctx, 1, 5, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.refund.Status != RefundFailed || repo.refund.Attempts != 3 || repo.refund.LastError == "" || repo.booking.Status != StatusCancelled {
+		t.Fatalf("expected failed refund, got %+v, booking %s", repo.refund, repo.booking.Status)
+	}
+
+	// отказ по существу не повторяется
+	provider = payment.NewFake("whsec")
+	provider.FailRefunds(1, payment.ErrRefundExceeded)
+	repo = setup(provider)
+	if _, err := NewService(repo, opts(provider)).Cancel(ctx, 1, 5, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.refund.Status != RefundFailed || repo.refund.Attempts != 1 {
+		t.Fatalf("expected failed refund after 1 attempt, got %+v", repo.refund)
+	}
+
+	// деньги не списаны — возвращать нечего
+	provider = payment.NewFake("whsec")
+	repo = setup(provider)
+	repo.payment.Status = PaymentAuthorized
+	c, err = NewService(repo, opts(provider)).Cancel(ctx, 1, 5, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Amount != 0 || c.Refund != 0 || c.RefundID != 0 || len(provider.Refunds()) != 0 {
+		t.Fatalf("expected no refund for uncaptured payment, got %+v", c)
+	}
+	if !provider.Cancelled(repo.payment.IntentID) || repo.payment.Status != PaymentCancelled {
+		t.Fatal("uncaptured payment must be voided at the provider")
+	}
+}
+
+func TestService_RetryRefunds(t *testing.T) {
+	ctx := context.Background()
+	provider := payment.NewFake("whsec")
+	intent, _ := provider.CreateIntent(ctx, payment.IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub"})
+	// процесс остановился после двух неудачных попыток из трёх
+	repo := repoStub{
+		booking: &Booking{ID: 1, EventID: 3, UserID: 5, Seats: 2, Status: StatusCancelled},
+		refund:  &Refund{ID: 1, BookingID: 1, IntentID: intent.ID, Amount: 3000, Status: RefundRequested, Attempts: 2},
+	}
+	svc := NewService(repo, Options{Events: eventsStub{}, Payments: provider, RefundAttempts: 3, RefundBackoff: time.Millisecond})
+
+	provider.FailRefunds(1, errors.New("connection reset"))
+	if err := svc.RetryRefunds(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.refund.Status != RefundFailed || repo.refund.Attempts != 3 {
+		t.Fatalf("expected failed refund on last attempt, got %+v", repo.refund)
+	}
+}
+
+func TestService_Checkout_PromoCode(t *testing.T) {
+	provider := payment.NewFake("whsec")
+	e := &event.Event{ID: 1, Capacity: 10, Price: 150000}
+
+	repo := repoStub{capacity: 10, payment: &Payment{}, discount: 75000}
+	c, err := NewService(repo, Options{Payments: provider}).Checkout(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 2, PromoCode: "spring25"}, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Status != StatusPending || c.Amount != 225000 || c.Discount != 75000 || repo.payment.Amount != 225000 {
+		t.Fatalf("expected discounted payment, got %+v, payment %+v", c, repo.payment)
+	}
+
+	// скидка на всю сумму: платёж не создаётся, бронь подтверждается сразу
+	repo = repoStub{capacity: 10, payment: &Payment{}, discount: 300000, booking: &Booking{ID: 1, UserID: 1, Seats: 2, Status: StatusPending}}
+	c, err = NewService(repo, Options{Payments: provider}).Checkout(context.Background(), &Booking{EventID: 1, UserID: 1, Seats: 2, PromoCode: "FREE"}, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Status != StatusConfirmed || c.Amount != 0 || c.ClientSecret != "" || repo.booking.Status != StatusConfirmed || repo.payment.ID != 0 {
+		t.Fatalf("expected confirmed booking without payment, got %+v", c)
+	}
+}
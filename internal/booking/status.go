@@ -0,0 +1,61 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status — состояние брони.
+type Status string
+
+const (
+	// StatusPending — бронь создана, но ещё не подтверждена (например, ждёт оплаты)
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = "confirmed"
+	StatusCancelled Status = "cancelled"
+	StatusCheckedIn Status = "checked_in"
+	StatusNoShow    Status = "no_show"
+	StatusRefunded  Status = "refunded"
+)
+
+var (
+	ErrInvalidStatus = errors.New("invalid booking status")
+	// ErrInvalidTransition — переход из текущего статуса в запрошенный запрещён.
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	// ErrAlreadyInStatus — бронь уже в запрошенном статусе (например, повторная отмена).
+	ErrAlreadyInStatus = errors.New("booking is already in this status")
+)
+
+// transitions — разрешённые переходы между статусами. Статусы без исходящих переходов конечные.
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCancelled, StatusCheckedIn, StatusNoShow, StatusRefunded},
+	StatusCancelled: {StatusRefunded},
+	StatusCheckedIn: nil,
+	StatusNoShow:    nil,
+	StatusRefunded:  nil,
+}
+
+// occupiedStatusesSQL — статусы, в которых бронь занимает места события.
+const occupiedStatusesSQL = `('pending','confirmed','checked_in','no_show')`
+
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// checkTransition проверяет, можно ли перевести бронь из from в to.
+func checkTransition(from, to Status) error {
+	if !to.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if from == to {
+		return fmt.Errorf("%w: %s", ErrAlreadyInStatus, to)
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+}
@@ -0,0 +1,72 @@
+package booking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidTicket — подпись не сходится, код повреждён или устарел (например, после смены мест).
+	ErrInvalidTicket = errors.New("invalid ticket")
+	// ErrTicketWrongEvent — билет выписан на другое событие.
+	ErrTicketWrongEvent = errors.New("ticket is for another event")
+	// ErrTicketUnavailable — билет есть только у подтверждённой брони.
+	ErrTicketUnavailable = errors.New("ticket is only available for confirmed bookings")
+	// ErrAlreadyCheckedIn — по брони уже прошли все места.
+	ErrAlreadyCheckedIn = errors.New("booking is already checked in")
+	// ErrCheckInSeats — запрошено больше мест, чем осталось отметить.
+	ErrCheckInSeats = errors.New("not enough seats left to check in")
+)
+
+// Ticket — содержимое QR-кода билета. Код подписан HMAC, поэтому проверяется без обращения к базе,
+// а в базе сверяется только актуальность.
+type Ticket struct {
+	BookingID int64 `json:"b"`
+	EventID   int64 `json:"e"`
+	Seats     int   `json:"s"`
+	// AttendeeID — билет посетителя на одно место; 0 — билет на всю бронь
+	AttendeeID int64 `json:"a,omitempty"`
+	// Version — Booking.TicketVersion на момент выдачи
+	Version int `json:"v,omitempty"`
+}
+
+// ticketDomain отделяет подписи билетов от других токенов на том же секрете.
+const ticketDomain = "ticket."
+
+func (t Ticket) sign(secret string) (string, error) {
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(payload)
+	return raw + "." + ticketSignature(secret, raw), nil
+}
+
+func parseTicket(secret, code string) (Ticket, error) {
+	var t Ticket
+	raw, sig, ok := strings.Cut(strings.TrimSpace(code), ".")
+	if !ok || raw == "" {
+		return t, ErrInvalidTicket
+	}
+	if !hmac.Equal([]byte(sig), []byte(ticketSignature(secret, raw))) {
+		return t, ErrInvalidTicket
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return t, ErrInvalidTicket
+	}
+	if err := json.Unmarshal(payload, &t); err != nil || t.BookingID == 0 {
+		return t, ErrInvalidTicket
+	}
+	return t, nil
+}
+
+func ticketSignature(secret, raw string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ticketDomain + raw))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
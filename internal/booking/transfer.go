@@ -0,0 +1,113 @@
+package booking
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/mailer"
+)
+
+var (
+	// ErrTransferNotFound — токен не подходит, передача уже принята или заменена новой.
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrTransferExpired — приглашение истекло.
+	ErrTransferExpired = errors.New("transfer has expired")
+	// ErrTransferRecipient — принять передачу может только аккаунт с подтверждённым email получателя.
+	ErrTransferRecipient = errors.New("transfer is addressed to another email")
+	// ErrTransferToSelf — получатель совпадает с владельцем брони.
+	ErrTransferToSelf = errors.New("cannot transfer booking to its owner")
+	// ErrTransferClosed — до начала события осталось меньше, чем допускает его transfer_cutoff_hours.
+	ErrTransferClosed = errors.New("transfer window is closed")
+)
+
+// Статусы передачи брони
+const (
+	TransferPending  = "pending"
+	TransferAccepted = "accepted"
+	// TransferCancelled — передачу заменила новая до того, как получатель её принял
+	TransferCancelled = "cancelled"
+)
+
+// Transfer — передача брони другому пользователю. Бронь переходит к получателю, когда он принимает
+// передачу токеном из письма.
+type Transfer struct {
+	ID         int64      `db:"id" json:"id" example:"1"`
+	BookingID  int64      `db:"booking_id" json:"booking_id" example:"1"`
+	FromUserID int64      `db:"from_user_id" json:"from_user_id" example:"1"`
+	ToEmail    string     `db:"to_email" json:"to_email" example:"colleague@example.com"`
+	ToUserID   int64      `db:"to_user_id" json:"to_user_id,omitempty" example:"2"`
+	Status     string     `db:"status" json:"status" example:"pending"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	// TokenHash — хэш токена из письма; сам токен не хранится
+	TokenHash string `db:"token_hash" json:"-"`
+}
+
+// TransferRequest модель запроса на передачу брони
+type TransferRequest struct {
+	Email string `json:"email" example:"colleague@example.com"`
+}
+
+// AcceptTransferRequest модель запроса на принятие переданной брони
+type AcceptTransferRequest struct {
+	Token string `json:"token" example:"c2VjcmV0.c2lnbmF0dXJl"`
+}
+
+// transferDomain отделяет подписи токенов передачи от билетов на том же секрете.
+const transferDomain = "transfer."
+
+// newTransferToken генерирует токен для письма и хэш для хранения.
+func newTransferToken(secret string) (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	return raw + "." + transferSignature(secret, raw), hashTransferToken(raw), nil
+}
+
+// parseTransferToken проверяет подпись и возвращает хэш для поиска в базе.
+func parseTransferToken(secret, token string) (string, bool) {
+	raw, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || raw == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(transferSignature(secret, raw))) {
+		return "", false
+	}
+	return hashTransferToken(raw), true
+}
+
+func transferSignature(secret, raw string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(transferDomain + raw))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashTransferToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func transferMessage(t *Transfer, eventTitle, acceptURL, token string) mailer.Message {
+	link := acceptURL + "?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      t.ToEmail,
+		Subject: "Вам передают бронь",
+		Body: fmt.Sprintf("Здравствуйте!\n\n"+
+			"Вам передают бронь на событие «%s». Чтобы принять её, войдите в аккаунт с этим email и перейдите по ссылке "+
+			"(действует до %s UTC):\n%s\n\n"+
+			"После принятия бронь и билет будут оформлены на вас.\n"+
+			"Если вы не ждали этого письма, просто проигнорируйте его.\n",
+			eventTitle, t.ExpiresAt.UTC().Format("2006-01-02 15:04"), link),
+	}
+}
@@ -0,0 +1,27 @@
+package bulkimport
+
+import (
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/cache"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/user"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIImporter = "bulk-importer"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIImporter,
+			Build: func(ctn container.Container) (interface{}, error) {
+				return New(
+					ctn.Get(booking.DIBookingService).(booking.Service),
+					ctn.Get(user.DIUserService).(user.Service),
+					ctn.Get(event.DIEventService).(event.Service),
+					ctn.Get(cache.DICacheService).(cache.Service),
+				), nil
+			},
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package bulkimport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"laschool.ru/event-booking-service/internal/http/handlers"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+// maxBodyBytes — предел размера загружаемого CSV.
+const maxBodyBytes = 5 << 20
+
+// Handler обслуживает POST /admin/bookings/import.
+type Handler struct {
+	importer *Importer
+}
+
+func NewHandler(importer *Importer) *Handler {
+	return &Handler{importer: importer}
+}
+
+// ImportBookings godoc
+// @Summary      Импорт броней из CSV
+// @Description  Создаёт брони по строкам event_id,email,seats (заголовок необязателен). Пользователи без аккаунта создаются автоматически. Строки платных событий и пользователей без подтверждённого email отклоняются, если не передан complimentary=true — тогда брони выдаются бесплатно. В режиме all_or_nothing при ошибке в любой строке ничего не сохраняется и возвращается 422; в режиме best_effort сохраняются корректные строки. Только для администраторов.
+// @Tags         admin
+// @Accept       text/csv
+// @Produce      json
+// @Param        mode  query  string  false  "Режим: all_or_nothing (по умолчанию) или best_effort"
+// @Param        complimentary  query  bool  false  "Бесплатные брони: разрешить платные события и неподтверждённые email"
+// @Param        file  body   string  true   "CSV с колонками event_id,email,seats"
+// @Success      200  {object}  bulkimport.Report
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse
+// @Failure      413  {object}  handlers.ErrorResponse
+// @Failure      422  {object}  bulkimport.Report
+// @Security     Bearer
+// @Router       /admin/bookings/import [post]
+func (h *Handler) ImportBookings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	mode, err := ParseMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	complimentary := false
+	if v := r.URL.Query().Get("complimentary"); v != "" {
+		if complimentary, err = strconv.ParseBool(v); err != nil {
+			handlers.WriteError(w, http.StatusBadRequest, "complimentary must be true or false")
+			return
+		}
+	}
+	actorID, _ := reqctx.UserID(r.Context())
+
+	report, err := h.importer.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxBodyBytes), mode, actorID, complimentary)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		handlers.WriteError(w, http.StatusRequestEntityTooLarge, "file too large")
+	case errors.Is(err, ErrInvalidCSV), errors.Is(err, ErrEmpty), errors.Is(err, ErrTooManyRows):
+		handlers.WriteError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		handlers.WriteError(w, http.StatusInternalServerError, "import failed")
+	case mode == ModeAllOrNothing && !report.Committed:
+		handlers.WriteJSON(w, http.StatusUnprocessableEntity, report)
+	default:
+		handlers.WriteJSON(w, http.StatusOK, report)
+	}
+}
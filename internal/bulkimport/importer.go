@@ -0,0 +1,329 @@
+// Package bulkimport загружает групповые бронирования из CSV (event_id, email, seats).
+// Пользователи находятся или создаются по email, брони создаются через booking.Service,
+// поэтому вместимость проверяется так же, как при обычном бронировании.
+package bulkimport
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/user"
+)
+
+var tracer = otel.Tracer("laschool.ru/event-booking-service/internal/bulkimport")
+
+// Mode — что делать со строками, если часть файла не проходит.
+type Mode string
+
+const (
+	// ModeAllOrNothing — при ошибке хотя бы в одной строке не создаётся ни одна бронь
+	ModeAllOrNothing Mode = "all_or_nothing"
+	// ModeBestEffort — создаются все брони, которые прошли проверки
+	ModeBestEffort Mode = "best_effort"
+)
+
+// MaxRows — предел строк в одном файле: все брони создаются одной транзакцией.
+const MaxRows = 5000
+
+var (
+	ErrInvalidMode = errors.New("invalid import mode")
+	ErrEmpty       = errors.New("import file has no rows")
+	ErrTooManyRows = fmt.Errorf("import file has more than %d rows", MaxRows)
+	ErrInvalidCSV  = errors.New("invalid csv")
+)
+
+// Статусы строк отчёта
+const (
+	RowCreated = "created"
+	RowFailed  = "failed"
+	// RowSkipped — строка корректна, но не сохранена, потому что в режиме all_or_nothing упала другая
+	RowSkipped = "skipped"
+)
+
+// RowResult — итог по одной строке файла.
+type RowResult struct {
+	Line        int    `json:"line" example:"2"`
+	EventID     int64  `json:"event_id,omitempty" example:"1"`
+	Email       string `json:"email,omitempty" example:"alice@example.com"`
+	Seats       int    `json:"seats,omitempty" example:"2"`
+	Status      string `json:"status" example:"created"`
+	UserID      int64  `json:"user_id,omitempty" example:"7"`
+	UserCreated bool   `json:"user_created,omitempty"`
+	BookingID   int64  `json:"booking_id,omitempty" example:"42"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Report — отчёт об импорте.
+type Report struct {
+	Mode    Mode `json:"mode" example:"all_or_nothing"`
+	Total   int  `json:"total" example:"3"`
+	Created int  `json:"created" example:"3"`
+	Failed  int  `json:"failed" example:"0"`
+	// Committed — брони сохранены: в all_or_nothing все, в best_effort хотя бы одна
+	Committed bool        `json:"committed"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// Bookings — часть booking.Service, нужная импорту.
+type Bookings interface {
+	CreateBatch(ctx context.Context, bookings []*booking.Booking, actorID int64, opts booking.BatchOptions) ([]error, error)
+}
+
+// Users — часть user.Service, нужная импорту.
+type Users interface {
+	EnsureByEmail(ctx context.Context, email string) (*user.User, bool, error)
+}
+
+// Events — часть event.Service, нужная импорту.
+type Events interface {
+	Get(ctx context.Context, id int64) (*event.Event, error)
+}
+
+// CacheInvalidator сбрасывает закэшированные списки броней; nil — кэш не трогаем.
+type CacheInvalidator interface {
+	DeletePattern(ctx context.Context, pattern string) error
+}
+
+type Importer struct {
+	bookings Bookings
+	users    Users
+	events   Events
+	cache    CacheInvalidator
+}
+
+func New(bookings Bookings, users Users, events Events, cache CacheInvalidator) *Importer {
+	return &Importer{bookings: bookings, users: users, events: events, cache: cache}
+}
+
+// ParseMode разбирает режим импорта; пустая строка — all_or_nothing.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeAllOrNothing:
+		return ModeAllOrNothing, nil
+	case ModeBestEffort:
+		return ModeBestEffort, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrInvalidMode, s)
+}
+
+// Import читает CSV из r и создаёт брони от имени actorID. Ошибки строк попадают в отчёт,
+// ошибка возвращается, только если файл нельзя обработать целиком. Без complimentary строки платных
+// событий и пользователей без подтверждённого email (в том числе только что созданных) отклоняются;
+// с ним администратор выдаёт бесплатные брони и на них.
+//
+// В режиме all_or_nothing аккаунты для новых email создаются до броней и остаются,
+// даже если брони потом откатываются: войти в них можно только через сброс пароля.
+func (im *Importer) Import(ctx context.Context, r io.Reader, mode Mode, actorID int64, complimentary bool) (*Report, error) {
+	ctx, span := tracer.Start(ctx, "bulkimport.Import")
+	defer span.End()
+
+	if mode != ModeAllOrNothing && mode != ModeBestEffort {
+		return nil, fmt.Errorf("%w %q", ErrInvalidMode, mode)
+	}
+	rows, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Mode: mode, Total: len(rows), Rows: rows}
+	atomic := mode == ModeAllOrNothing
+
+	if err := im.checkEvents(ctx, rows); err != nil {
+		return nil, err
+	}
+	if atomic && hasFailed(rows) {
+		return im.finish(ctx, report, nil), nil
+	}
+
+	// один и тот же email в нескольких строках разрешается один раз
+	users := make(map[string]*user.User)
+	for i := range rows {
+		row := &rows[i]
+		if row.Status == RowFailed {
+			continue
+		}
+		u, ok := users[row.Email]
+		if !ok {
+			var created bool
+			u, created, err = im.users.EnsureByEmail(ctx, row.Email)
+			if err != nil {
+				row.Status, row.Error = RowFailed, err.Error()
+				continue
+			}
+			users[row.Email] = u
+			row.UserCreated = created
+		}
+		row.UserID = u.ID
+	}
+	if atomic && hasFailed(rows) {
+		return im.finish(ctx, report, nil), nil
+	}
+
+	var batch []*booking.Booking
+	var index []int
+	for i, row := range rows {
+		if row.Status == RowFailed {
+			continue
+		}
+		batch = append(batch, &booking.Booking{EventID: row.EventID, UserID: row.UserID, Seats: row.Seats})
+		index = append(index, i)
+	}
+	if len(batch) > 0 {
+		errs, err := im.bookings.CreateBatch(ctx, batch, actorID, booking.BatchOptions{Atomic: atomic, Complimentary: complimentary})
+		if err != nil {
+			return nil, fmt.Errorf("create bookings: %w", err)
+		}
+		for j, i := range index {
+			switch {
+			case errs[j] != nil:
+				rows[i].Status, rows[i].Error = RowFailed, errs[j].Error()
+			case batch[j].ID != 0:
+				rows[i].Status, rows[i].BookingID = RowCreated, batch[j].ID
+			}
+		}
+	}
+	return im.finish(ctx, report, batch), nil
+}
+
+// checkEvents помечает строки с несуществующими и уже прошедшими событиями.
+func (im *Importer) checkEvents(ctx context.Context, rows []RowResult) error {
+	checked := make(map[int64]string)
+	now := time.Now()
+	for i := range rows {
+		row := &rows[i]
+		if row.Status == RowFailed {
+			continue
+		}
+		msg, ok := checked[row.EventID]
+		if !ok {
+			e, err := im.events.Get(ctx, row.EventID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				msg = "event not found"
+			case err != nil:
+				return fmt.Errorf("get event %d: %w", row.EventID, err)
+			case !e.EndsAt.After(now):
+				msg = "event has already ended"
+			}
+			checked[row.EventID] = msg
+		}
+		if msg != "" {
+			row.Status, row.Error = RowFailed, msg
+		}
+	}
+	return nil
+}
+
+// finish подводит итоги, помечает несохранённые строки и сбрасывает кэш затронутых событий.
+func (im *Importer) finish(ctx context.Context, report *Report, batch []*booking.Booking) *Report {
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		switch row.Status {
+		case RowCreated:
+			report.Created++
+		case RowFailed:
+			report.Failed++
+		default:
+			row.Status = RowSkipped
+		}
+	}
+	report.Committed = report.Created > 0
+	logger.FromContext(ctx).Info("bookings imported", "mode", report.Mode, "total", report.Total,
+		"created", report.Created, "failed", report.Failed)
+
+	if im.cache == nil || report.Created == 0 {
+		return report
+	}
+	invalidated := make(map[int64]bool)
+	for _, b := range batch {
+		if b.ID == 0 || invalidated[b.EventID] {
+			continue
+		}
+		invalidated[b.EventID] = true
+		if err := im.cache.DeletePattern(ctx, fmt.Sprintf("event:%d:bookings*", b.EventID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", b.EventID, "error", err)
+		}
+	}
+	if err := im.cache.DeletePattern(ctx, "stats:bookings*"); err != nil {
+		logger.FromContext(ctx).Warn("cache invalidation failed", "error", err)
+	}
+	return report
+}
+
+func hasFailed(rows []RowResult) bool {
+	for _, row := range rows {
+		if row.Status == RowFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// parse читает строки event_id,email,seats. Первая строка пропускается, если это заголовок.
+func parse(r io.Reader) ([]RowResult, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var rows []RowResult
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		if first && strings.EqualFold(strings.TrimSpace(rec[0]), "event_id") {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, parseRow(line, rec))
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+	return rows, nil
+}
+
+func parseRow(line int, rec []string) RowResult {
+	row := RowResult{Line: line}
+	fail := func(msg string) RowResult {
+		row.Status, row.Error = RowFailed, msg
+		return row
+	}
+	if len(rec) != 3 {
+		return fail(fmt.Sprintf("expected 3 columns (event_id, email, seats), got %d", len(rec)))
+	}
+	row.Email = strings.ToLower(strings.TrimSpace(rec[1]))
+
+	var err error
+	if row.EventID, err = strconv.ParseInt(strings.TrimSpace(rec[0]), 10, 64); err != nil || row.EventID <= 0 {
+		return fail("invalid event_id")
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return fail("invalid email")
+	}
+	if row.Seats, err = strconv.Atoi(strings.TrimSpace(rec[2])); err != nil || row.Seats <= 0 {
+		return fail("seats must be a positive integer")
+	}
+	return row
+}
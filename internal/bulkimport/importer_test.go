@@ -0,0 +1,227 @@
+package bulkimport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/user"
+)
+
+type eventsStub map[int64]*event.Event
+
+func (s eventsStub) Get(ctx context.Context, id int64) (*event.Event, error) {
+	if e, ok := s[id]; ok {
+		return e, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+type usersStub struct {
+	ids    map[string]int64
+	ensure int
+}
+
+func (s *usersStub) EnsureByEmail(ctx context.Context, email string) (*user.User, bool, error) {
+	s.ensure++
+	if id, ok := s.ids[email]; ok {
+		return &user.User{ID: id, Email: email}, false, nil
+	}
+	id := int64(100 + len(s.ids))
+	s.ids[email] = id
+	return &user.User{ID: id, Email: email}, true, nil
+}
+
+// bookingsStub принимает брони, пока на событии остаются места. Брони платных событий
+// принимаются только бесплатными, как в booking.Service.
+type bookingsStub struct {
+	free  map[int64]int
+	paid  map[int64]bool
+	calls int
+}
+
+func (s *bookingsStub) CreateBatch(ctx context.Context, bookings []*booking.Booking, actorID int64, opts booking.BatchOptions) ([]error, error) {
+	s.calls++
+	errs := make([]error, len(bookings))
+	free := make(map[int64]int, len(s.free))
+	for k, v := range s.free {
+		free[k] = v
+	}
+	failed := false
+	for i, b := range bookings {
+		if s.paid[b.EventID] && !opts.Complimentary {
+			errs[i], failed = booking.ErrPaymentRequired, true
+			continue
+		}
+		if b.Seats > free[b.EventID] {
+			errs[i], failed = booking.ErrNotEnoughSeats, true
+			continue
+		}
+		free[b.EventID] -= b.Seats
+		b.ID = int64(i + 1)
+	}
+	if opts.Atomic && failed {
+		for _, b := range bookings {
+			b.ID = 0
+		}
+		return errs, nil
+	}
+	s.free = free
+	return errs, nil
+}
+
+type cacheStub struct{ patterns []string }
+
+func (c *cacheStub) DeletePattern(ctx context.Context, pattern string) error {
+	c.patterns = append(c.patterns, pattern)
+	return nil
+}
+
+func newImporter(free map[int64]int) (*Importer, *bookingsStub, *usersStub, *cacheStub) {
+	future := &event.Event{StartsAt: time.Now().Add(24 * time.Hour), EndsAt: time.Now().Add(26 * time.Hour)}
+	past := &event.Event{StartsAt: time.Now().Add(-26 * time.Hour), EndsAt: time.Now().Add(-24 * time.Hour)}
+	bookings := &bookingsStub{free: free}
+	users := &usersStub{ids: map[string]int64{"alice@example.com": 7}}
+	cache := &cacheStub{}
+	return New(bookings, users, eventsStub{1: future, 2: future, 3: past}, cache), bookings, users, cache
+}
+
+func TestImport_BestEffort(t *testing.T) {
+	im, _, users, cache := newImporter(map[int64]int{1: 5, 2: 1})
+	csv := "event_id,email,seats\n" +
+		"1,Alice@Example.com,2\n" +
+		"2,bob@example.com,3\n" +
+		"3,carol@example.com,1\n" +
+		"1,not-an-email,1\n" +
+		"1,bob@example.com,1\n"
+
+	report, err := im.Import(context.Background(), strings.NewReader(csv), ModeBestEffort, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Total != 5 || report.Created != 2 || report.Failed != 3 || !report.Committed {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	want := []struct {
+		line   int
+		status string
+		err    string
+	}{
+		{2, RowCreated, ""},
+		{3, RowFailed, booking.ErrNotEnoughSeats.Error()},
+		{4, RowFailed, "event has already ended"},
+		{5, RowFailed, "invalid email"},
+		{6, RowCreated, ""},
+	}
+	for i, w := range want {
+		row := report.Rows[i]
+		if row.Line != w.line || row.Status != w.status || row.Error != w.err {
+			t.Errorf("row %d: got line=%d status=%s err=%q, want %+v", i, row.Line, row.Status, row.Error, w)
+		}
+	}
+	if report.Rows[0].UserID != 7 || report.Rows[0].UserCreated {
+		t.Errorf("existing user must be reused, got %+v", report.Rows[0])
+	}
+	if !report.Rows[1].UserCreated || report.Rows[4].UserCreated || report.Rows[4].UserID != report.Rows[1].UserID {
+		t.Errorf("bob must be created once and reused, got %+v / %+v", report.Rows[1], report.Rows[4])
+	}
+	if users.ensure != 2 {
+		t.Errorf("expected 2 EnsureByEmail calls, got %d", users.ensure)
+	}
+	if len(cache.patterns) != 2 || cache.patterns[0] != "event:1:bookings*" {
+		t.Errorf("unexpected cache invalidation: %v", cache.patterns)
+	}
+}
+
+func TestImport_AllOrNothing(t *testing.T) {
+	t.Run("invalid row skips the rest", func(t *testing.T) {
+		im, bookings, users, _ := newImporter(map[int64]int{1: 10})
+		report, err := im.Import(context.Background(), strings.NewReader("1,alice@example.com,2\n404,bob@example.com,1\n"), ModeAllOrNothing, 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Committed || report.Created != 0 || report.Failed != 1 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if report.Rows[0].Status != RowSkipped || report.Rows[1].Error != "event not found" {
+			t.Fatalf("unexpected rows: %+v", report.Rows)
+		}
+		if bookings.calls != 0 || users.ensure != 0 {
+			t.Fatal("nothing must be written when validation fails")
+		}
+	})
+
+	t.Run("capacity failure rolls back", func(t *testing.T) {
+		im, _, _, cache := newImporter(map[int64]int{1: 2})
+		report, err := im.Import(context.Background(), strings.NewReader("1,alice@example.com,2\n1,bob@example.com,1\n"), ModeAllOrNothing, 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Committed || report.Rows[0].Status != RowSkipped || report.Rows[0].BookingID != 0 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if len(cache.patterns) != 0 {
+			t.Fatalf("cache must not be touched, got %v", cache.patterns)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		im, _, _, _ := newImporter(map[int64]int{1: 3})
+		report, err := im.Import(context.Background(), strings.NewReader("1,alice@example.com,2\n1,bob@example.com,1\n"), ModeAllOrNothing, 1, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !report.Committed || report.Created != 2 || report.Rows[1].BookingID == 0 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	})
+}
+
+func TestImport_Complimentary(t *testing.T) {
+	body := "1,alice@example.com,1\n2,bob@example.com,1\n"
+	im, bookings, _, _ := newImporter(map[int64]int{1: 5, 2: 5})
+	bookings.paid = map[int64]bool{2: true}
+
+	report, err := im.Import(context.Background(), strings.NewReader(body), ModeBestEffort, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Rows[0].Status != RowCreated || report.Rows[1].Error != booking.ErrPaymentRequired.Error() {
+		t.Fatalf("paid event row must be rejected without complimentary, got %+v", report.Rows)
+	}
+
+	report, err = im.Import(context.Background(), strings.NewReader(body), ModeBestEffort, 1, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 2 {
+		t.Fatalf("complimentary import must book paid events, got %+v", report.Rows)
+	}
+}
+
+func TestImport_FileErrors(t *testing.T) {
+	im, _, _, _ := newImporter(nil)
+	cases := map[string]struct {
+		body string
+		mode Mode
+		want error
+	}{
+		"empty":      {"event_id,email,seats\n", ModeBestEffort, ErrEmpty},
+		"bad quotes": {"1,\"alice,2\n", ModeBestEffort, ErrInvalidCSV},
+		"bad mode":   {"1,alice@example.com,2\n", Mode("some"), ErrInvalidMode},
+		"too many":   {strings.Repeat("1,alice@example.com,1\n", MaxRows+1), ModeBestEffort, ErrTooManyRows},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := im.Import(context.Background(), strings.NewReader(tc.body), tc.mode, 1, false)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/metrics"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -22,7 +23,8 @@ func init() {
 				if !ok {
 					return nil, fmt.Errorf("expected *redis.Client, got %T", redisRaw)
 				}
-				return NewService(redisClient, time.Duration(cfg.Redis.LockTTL)), nil
+				m := ctn.Get(metrics.DIMetrics).(*metrics.Metrics)
+				return NewService(redisClient, time.Duration(cfg.Redis.LockTTL), m), nil
 			},
 		})
 	})
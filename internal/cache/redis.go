@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/tracing"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -43,8 +46,19 @@ func init() {
 				if err := pingWithRetry(rdb, rdb.Options().MaxRetries, time.Duration(cfg.Redis.MinRetryBackoff)*time.Second); err != nil {
 					return nil, fmt.Errorf("failed to connect to Redis after %d attempts: %w", cfg.Redis.MaxRetries, err)
 				}
+				m := ctn.Get(metrics.DIMetrics).(*metrics.Metrics)
+				if err := m.RegisterRedisStats(rdb); err != nil {
+					return nil, err
+				}
+				tr := ctn.Get(tracing.DITracing).(*tracing.Tracing)
+				if err := redisotel.InstrumentTracing(rdb, redisotel.WithTracerProvider(tr.TracerProvider())); err != nil {
+					return nil, fmt.Errorf("redis tracing: %w", err)
+				}
 				return rdb, nil
 			},
+			Close: func(obj interface{}) error {
+				return obj.(*redis.Client).Close()
+			},
 		})
 	})
 }
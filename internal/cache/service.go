@@ -5,11 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math/rand"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/metrics"
 )
 
 const (
@@ -34,11 +35,13 @@ type service struct {
 	retryDelay    time.Duration
 	maxRetryDelay time.Duration
 	maxRetries    int
+	metrics       *metrics.Metrics
 }
 
-func NewService(redis *redis.Client, lockTTL time.Duration) Service {
+func NewService(redis *redis.Client, lockTTL time.Duration, m *metrics.Metrics) Service {
 	return &service{
 		redis:         redis,
+		metrics:       m,
 		LockTTL:       lockTTL,
 		retryDelay:    retryDelayConst,
 		maxRetryDelay: maxRetryDelayConst,
@@ -116,6 +119,7 @@ func (s *service) DeletePattern(ctx context.Context, pattern string) error {
 func (s *service) GetProtected(ctx context.Context, key string, calculate func() (interface{}, error), baseTTL time.Duration) ([]byte, error) {
 	data, err := s.redis.Get(ctx, key).Bytes()
 	if err == nil && len(data) > 0 {
+		s.metrics.CacheResult(metrics.CacheHit)
 		return data, nil
 	}
 
@@ -141,8 +145,10 @@ func (s *service) GetWithLock(ctx context.Context, key string, calculate func()
 	}
 	if gotLock {
 		defer s.releaseLock(ctx, lockKey)
+		s.metrics.CacheResult(metrics.CacheMiss)
 		return s.calculateAndStore(ctx, key, calculate, baseTTL)
 	}
+	s.metrics.CacheResult(metrics.CacheLockWait)
 	return s.waitForCalculation(ctx, key, calculate, baseTTL)
 }
 
@@ -156,7 +162,7 @@ func (s *service) tryAcquireLock(ctx context.Context, lockKey string) (bool, err
 
 func (s *service) releaseLock(ctx context.Context, lockKey string) {
 	if err := s.redis.Del(ctx, lockKey).Err(); err != nil {
-		log.Printf("WARNING: Failed to release lock %s: %v", lockKey, err)
+		logger.FromContext(ctx).Warn("failed to release cache lock", "lock_key", lockKey, "error", err)
 	}
 }
 
@@ -167,7 +173,7 @@ func (s *service) calculateAndStore(ctx context.Context, key string, calculate f
 	}
 	ttl := s.WithJitter(baseTTL)
 	if err := s.Set(ctx, key, data, ttl); err != nil {
-		log.Printf("WARNING: Failed to cache data for key %s: %v", key, err)
+		logger.FromContext(ctx).Warn("failed to cache data", "key", key, "error", err)
 	}
 
 	return data, nil
@@ -189,6 +195,7 @@ func (s *service) waitForCalculation(ctx context.Context, key string, calculate
 			return nil, err
 		}
 		if found {
+			s.metrics.CacheResult(metrics.CacheHit)
 			return result, nil
 		}
 
@@ -199,7 +206,8 @@ func (s *service) waitForCalculation(ctx context.Context, key string, calculate
 		}
 	}
 
-	log.Printf("WARNING: Cache stampede protection timeout for key %s, calculating ourselves", key)
+	logger.FromContext(ctx).Warn("cache stampede protection timeout, calculating ourselves", "key", key)
+	s.metrics.CacheResult(metrics.CacheLockTimeout)
 	return s.calculateAndStore(ctx, key, calculate, baseTTL)
 }
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"time"
 
@@ -11,11 +12,29 @@ type Server struct {
 	Port         int           `yaml:"port"`
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// ShutdownTimeout — сколько ждать завершения запросов и фоновых задач при остановке
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ReadinessDrain — сколько после сигнала /readyz отвечает 503, а сервер ещё принимает запросы,
+	// чтобы балансировщик успел вывести экземпляр. Входит в ShutdownTimeout; по умолчанию 5s, <0 — без паузы
+	ReadinessDrain time.Duration `yaml:"readiness_drain"`
+	// TrustProxy — брать IP клиента из X-Forwarded-For (только за доверенным прокси)
+	TrustProxy bool `yaml:"trust_proxy"`
+	// ProxyHops — сколько доверенных прокси дописывают X-Forwarded-For перед сервисом (по умолчанию 1).
+	// Без TrustProxy сбрасывается в 0
+	ProxyHops int `yaml:"proxy_hops"`
 }
 
 type Database struct {
-	DSN         string `yaml:"dsn"`
-	AutoMigrate bool   `yaml:"auto_migrate"`
+	DSN           string `yaml:"dsn"`
+	AutoMigrate   bool   `yaml:"auto_migrate"`
+	MigrationsDir string `yaml:"migrations_dir"`
+}
+
+// Health — таймауты проверок зависимостей в /readyz
+type Health struct {
+	PostgresTimeout   time.Duration `yaml:"postgres_timeout"`
+	RedisTimeout      time.Duration `yaml:"redis_timeout"`
+	MigrationsTimeout time.Duration `yaml:"migrations_timeout"`
 }
 
 type JWT struct {
@@ -46,11 +65,132 @@ type Redis struct {
 	LockTTL int `yaml:"lock_ttl"`
 }
 
+type Log struct {
+	Level  string `yaml:"level"`  // debug, info, warn, error
+	Format string `yaml:"format"` // json, text
+}
+
+type Tracing struct {
+	Enabled     bool    `yaml:"enabled"`
+	Exporter    string  `yaml:"exporter"` // otlp, stdout, memory
+	Endpoint    string  `yaml:"endpoint"` // host:port OTLP/HTTP коллектора
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// RateLimitRule — лимит для одного маршрута.
+type RateLimitRule struct {
+	Route  string        `yaml:"route"`  // шаблон маршрута из роутера, например /users/login
+	Method string        `yaml:"method"` // пусто — любой метод
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+	Key    string        `yaml:"key"` // ip, user, api_key
+}
+
+type RateLimit struct {
+	Enabled bool `yaml:"enabled"`
+	// FailOpen — пропускать запросы, если Redis недоступен; иначе отвечать 503
+	FailOpen bool            `yaml:"fail_open"`
+	Rules    []RateLimitRule `yaml:"rules"`
+	// APIKeys — выданные интеграциям ключи. Отдельный счётчик по ключу "api_key" получают
+	// только они, запросы с неизвестным X-API-Key считаются по IP
+	APIKeys []string `yaml:"api_keys"`
+}
+
+// LoginProtection — защита входа от перебора паролей.
+type LoginProtection struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxAccountFailures — неудачных попыток на email за FailureWindow до блокировки
+	MaxAccountFailures int `yaml:"max_account_failures"`
+	// MaxIPFailures — неудачных попыток с одного IP за FailureWindow до блокировки
+	MaxIPFailures int           `yaml:"max_ip_failures"`
+	FailureWindow time.Duration `yaml:"failure_window"`
+	// BaseLockout удваивается с каждой следующей блокировкой, но не больше MaxLockout
+	BaseLockout time.Duration `yaml:"base_lockout"`
+	MaxLockout  time.Duration `yaml:"max_lockout"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Mail — отправка писем пользователям.
+type Mail struct {
+	// Driver — smtp, file или memory
+	Driver string `yaml:"driver"`
+	From   string `yaml:"from"`
+	// Dir — каталог для писем драйвера file
+	Dir  string `yaml:"dir"`
+	SMTP SMTP   `yaml:"smtp"`
+}
+
+// Account — настройки жизненного цикла аккаунта.
+type Account struct {
+	// BaseURL — публичный адрес сервиса для ссылок в письмах
+	BaseURL         string        `yaml:"base_url"`
+	VerificationTTL time.Duration `yaml:"verification_ttl"`
+	// ResetURL — страница сброса пароля, куда ведёт ссылка из письма; по умолчанию BaseURL + /users/password/reset
+	ResetURL string        `yaml:"reset_url"`
+	ResetTTL time.Duration `yaml:"reset_ttl"`
+}
+
 type Config struct {
-	Server   Server   `yaml:"server"`
-	Database Database `yaml:"database"`
-	JWT      JWT      `yaml:"jwt"`
-	Redis    Redis    `yaml:"redis"`
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	JWT       JWT       `yaml:"jwt"`
+	Redis     Redis     `yaml:"redis"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Health    Health    `yaml:"health"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+
+	LoginProtection LoginProtection `yaml:"login_protection"`
+	Mail            Mail            `yaml:"mail"`
+	Account         Account         `yaml:"account"`
+	Booking         Booking         `yaml:"booking"`
+	Payment         Payment         `yaml:"payment"`
+}
+
+// Booking — правила работы с бронями.
+type Booking struct {
+	// AttendeeEditCutoff — за сколько до начала события закрывается редактирование посетителей брони
+	AttendeeEditCutoff time.Duration `yaml:"attendee_edit_cutoff"`
+	// TransferTTL — сколько действует приглашение принять переданную бронь
+	TransferTTL time.Duration `yaml:"transfer_ttl"`
+	// TransferURL — страница принятия брони, куда ведёт ссылка из письма; по умолчанию Account.BaseURL + /bookings/transfers/accept
+	TransferURL string `yaml:"transfer_url"`
+}
+
+// Stripe — доступ к Stripe-совместимому API платежей.
+type Stripe struct {
+	APIKey string `yaml:"api_key"`
+	// BaseURL — адрес API; по умолчанию https://api.stripe.com
+	BaseURL string        `yaml:"base_url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Payment — оплата броней платных событий.
+type Payment struct {
+	// Driver — stripe или fake (без сети, для тестов и локальной разработки); обязателен
+	Driver string `yaml:"driver"`
+	// Currency — валюта цен событий в формате ISO 4217 в нижнем регистре
+	Currency string `yaml:"currency"`
+	// WebhookSecret — ключ проверки подписи уведомлений провайдера
+	WebhookSecret string `yaml:"webhook_secret"`
+	// PendingTTL — сколько неоплаченная бронь держит места
+	PendingTTL time.Duration `yaml:"pending_ttl"`
+	// ExpireInterval — как часто снимаются просроченные неоплаченные брони и повторяются брошенные возвраты;
+	// 0 — раз в минуту, отрицательное значение — ошибка конфигурации
+	ExpireInterval time.Duration `yaml:"expire_interval"`
+	// RefundAttempts — сколько раз обращаться к провайдеру за возвратом
+	RefundAttempts int `yaml:"refund_attempts"`
+	// RefundBackoff — пауза перед повтором возврата; удваивается с каждой попыткой
+	RefundBackoff time.Duration `yaml:"refund_backoff"`
+	Stripe        Stripe        `yaml:"stripe"`
 }
 
 func Load(path string) (*Config, error) {
@@ -63,6 +203,49 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	switch {
+	case !cfg.Server.TrustProxy:
+		cfg.Server.ProxyHops = 0
+	case cfg.Server.ProxyHops <= 0:
+		cfg.Server.ProxyHops = 1
+	}
+	if cfg.Server.ReadinessDrain == 0 {
+		cfg.Server.ReadinessDrain = 5 * time.Second
+	}
+	if cfg.Database.MigrationsDir == "" {
+		cfg.Database.MigrationsDir = "deploy/migrations"
+	}
+	if cfg.Account.VerificationTTL == 0 {
+		cfg.Account.VerificationTTL = 24 * time.Hour
+	}
+	if cfg.Account.ResetTTL == 0 {
+		cfg.Account.ResetTTL = 30 * time.Minute
+	}
+	if cfg.Booking.AttendeeEditCutoff == 0 {
+		cfg.Booking.AttendeeEditCutoff = 24 * time.Hour
+	}
+	if cfg.Booking.TransferTTL == 0 {
+		cfg.Booking.TransferTTL = 72 * time.Hour
+	}
+	if cfg.Payment.Currency == "" {
+		cfg.Payment.Currency = "rub"
+	}
+	if cfg.Payment.PendingTTL == 0 {
+		cfg.Payment.PendingTTL = 15 * time.Minute
+	}
+	switch {
+	case cfg.Payment.ExpireInterval == 0:
+		cfg.Payment.ExpireInterval = time.Minute
+	case cfg.Payment.ExpireInterval < 0:
+		// интервал уходит в time.NewTicker, который паникует на неположительном значении
+		return nil, fmt.Errorf("payment.expire_interval must be positive, got %s", cfg.Payment.ExpireInterval)
+	}
+	if cfg.Payment.RefundAttempts == 0 {
+		cfg.Payment.RefundAttempts = 5
+	}
+	if cfg.Payment.RefundBackoff == 0 {
+		cfg.Payment.RefundBackoff = 2 * time.Second
+	}
 	return &cfg, nil
 }
 
@@ -36,3 +36,31 @@ func RunMigrations(ctx context.Context, database *sqlx.DB, migrationsDir string)
 		return err
 	}
 }
+
+// MigrationVersion возвращает последнюю применённую версию миграций.
+// В отличие от goose.GetDBVersion не создаёт служебную таблицу, поэтому годится для проб.
+func MigrationVersion(ctx context.Context, database *sqlx.DB) (int64, error) {
+	var version int64
+	const q = `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`
+	if err := database.GetContext(ctx, &version, q); err != nil {
+		return 0, fmt.Errorf("migration version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestMigrationVersion возвращает версию последнего файла миграций в migrationsDir.
+func LatestMigrationVersion(migrationsDir string) (int64, error) {
+	abs, err := filepath.Abs(migrationsDir)
+	if err != nil {
+		return 0, fmt.Errorf("migrations path: %w", err)
+	}
+	migrations, err := goose.CollectMigrations(abs, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, err
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, err
+	}
+	return last.Version, nil
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/tracing"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -22,16 +26,30 @@ func init() {
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 
-				db, err := sqlx.Open("pgx", cfg.Database.DSN)
+				tr := ctn.Get(tracing.DITracing).(*tracing.Tracing)
+
+				// каждый запрос к БД попадает в трассировку отдельным спаном
+				sqlDB, err := otelsql.Open("pgx", cfg.Database.DSN,
+					otelsql.WithTracerProvider(tr.TracerProvider()),
+					otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+				)
 				if err != nil {
 					return nil, err
 				}
+				db := sqlx.NewDb(sqlDB, "pgx")
 				// Проверим соединение
 				if err := db.PingContext(ctx); err != nil {
 					return nil, err
 				}
+				m := ctn.Get(metrics.DIMetrics).(*metrics.Metrics)
+				if err := m.RegisterDBStats(db.DB, "eventdb"); err != nil {
+					return nil, err
+				}
 				return db, nil
 			},
+			Close: func(obj interface{}) error {
+				return obj.(*sqlx.DB).Close()
+			},
 		})
 	})
 }
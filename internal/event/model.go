@@ -1,6 +1,9 @@
 package event
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Event struct {
 	ID          int64     `db:"id" json:"id"`
@@ -10,6 +13,17 @@ type Event struct {
 	StartsAt    time.Time `db:"starts_at" json:"starts_at"`
 	EndsAt      time.Time `db:"ends_at" json:"ends_at"`
 	Capacity    int       `db:"capacity" json:"capacity"`
+	// Price — цена места в копейках, 0 — бесплатное событие
+	Price              int64              `db:"price" json:"price"`
+	CancellationPolicy CancellationPolicy `db:"cancellation_policy" json:"cancellation_policy"`
+	// TransferCutoffHours — за сколько часов до начала закрывается передача броней; 0 — до начала
+	TransferCutoffHours int `db:"transfer_cutoff_hours" json:"transfer_cutoff_hours"`
+	// Ограничения для одного пользователя; 0 — без ограничения
+	MaxSeatsPerBooking int  `db:"max_seats_per_booking" json:"max_seats_per_booking"`
+	MaxSeatsPerUser    int  `db:"max_seats_per_user" json:"max_seats_per_user"`
+	OneBookingPerUser  bool `db:"one_booking_per_user" json:"one_booking_per_user"`
+	// OrganizerID — создатель события; 0 — не задан (события, созданные до появления организаторов)
+	OrganizerID int64     `db:"organizer_id" json:"organizer_id"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -22,4 +36,26 @@ type CreateEventRequest struct {
 	StartsAt    time.Time `json:"starts_at" example:"2026-01-15T18:00:00Z"`
 	EndsAt      time.Time `json:"ends_at" example:"2026-01-15T21:00:00Z"`
 	Capacity    int       `json:"capacity" example:"100"`
+	// Price — цена места в копейках
+	Price              int64              `json:"price" example:"150000"`
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"`
+	// TransferCutoffHours — за сколько часов до начала закрывается передача броней
+	TransferCutoffHours int `json:"transfer_cutoff_hours" example:"24"`
+	// MaxSeatsPerBooking — мест в одной брони, 0 — без ограничения
+	MaxSeatsPerBooking int `json:"max_seats_per_booking" example:"4"`
+	// MaxSeatsPerUser — мест на пользователя во всех его бронях события, 0 — без ограничения
+	MaxSeatsPerUser int `json:"max_seats_per_user" example:"6"`
+	// OneBookingPerUser — не больше одной действующей брони на пользователя
+	OneBookingPerUser bool `json:"one_booking_per_user" example:"false"`
+}
+
+// validateLimits проверяет ограничения на бронирование одним пользователем.
+func (e *Event) validateLimits() error {
+	if e.MaxSeatsPerBooking < 0 || e.MaxSeatsPerUser < 0 {
+		return errors.New("booking limits must not be negative")
+	}
+	if e.MaxSeatsPerBooking > 0 && e.MaxSeatsPerUser > 0 && e.MaxSeatsPerBooking > e.MaxSeatsPerUser {
+		return errors.New("max_seats_per_booking cannot exceed max_seats_per_user")
+	}
+	return nil
 }
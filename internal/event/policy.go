@@ -0,0 +1,82 @@
+package event
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CancellationRule — отмена не позднее чем за HoursBefore часов до начала события
+// возвращает RefundPercent процентов стоимости брони.
+type CancellationRule struct {
+	HoursBefore   int `json:"hours_before" example:"48"`
+	RefundPercent int `json:"refund_percent" example:"100"`
+}
+
+// CancellationPolicy — правила отмены брони события. Отмена разрешена, пока действует хотя бы одно
+// правило; из действующих применяется правило с самым ранним сроком. Пустая политика —
+// бесплатная отмена до начала события.
+type CancellationPolicy []CancellationRule
+
+// Validate проверяет, что сроки правил не повторяются, а проценты лежат в диапазоне 0..100.
+func (p CancellationPolicy) Validate() error {
+	seen := make(map[int]bool, len(p))
+	for _, r := range p {
+		if r.HoursBefore < 0 {
+			return errors.New("cancellation policy: hours_before must not be negative")
+		}
+		if r.RefundPercent < 0 || r.RefundPercent > 100 {
+			return errors.New("cancellation policy: refund_percent must be between 0 and 100")
+		}
+		if seen[r.HoursBefore] {
+			return fmt.Errorf("cancellation policy: duplicate rule for %d hours", r.HoursBefore)
+		}
+		seen[r.HoursBefore] = true
+	}
+	return nil
+}
+
+// RefundPercent возвращает процент возврата при отмене в момент now для события, начинающегося в startsAt.
+// ok == false — отмена уже невозможна.
+func (p CancellationPolicy) RefundPercent(startsAt, now time.Time) (percent int, ok bool) {
+	if len(p) == 0 {
+		return 100, now.Before(startsAt)
+	}
+	best := -1
+	for _, r := range p {
+		deadline := startsAt.Add(-time.Duration(r.HoursBefore) * time.Hour)
+		if now.After(deadline) || r.HoursBefore <= best {
+			continue
+		}
+		best, percent = r.HoursBefore, r.RefundPercent
+	}
+	return percent, best >= 0
+}
+
+// Value сохраняет политику в JSONB; пустая политика хранится как [].
+func (p CancellationPolicy) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (p *CancellationPolicy) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("cancellation policy: unsupported type %T", src)
+	}
+}
@@ -24,19 +24,23 @@ func NewRepository(db *sqlx.DB) Repository {
 
 func (r *repository) Create(ctx context.Context, e *Event) (int64, error) {
 	const q = `
-        INSERT INTO events (title, description, location, starts_at, ends_at, capacity)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO events (title, description, location, starts_at, ends_at, capacity, price, cancellation_policy, organizer_id, transfer_cutoff_hours,
+            max_seats_per_booking, max_seats_per_user, one_booking_per_user)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, 0), $10, $11, $12, $13)
         RETURNING id
     `
 	var id int64
-	if err := r.db.QueryRowxContext(ctx, q, e.Title, e.Description, e.Location, e.StartsAt, e.EndsAt, e.Capacity).Scan(&id); err != nil {
+	if err := r.db.QueryRowxContext(ctx, q, e.Title, e.Description, e.Location, e.StartsAt, e.EndsAt, e.Capacity, e.Price, e.CancellationPolicy, e.OrganizerID, e.TransferCutoffHours,
+		e.MaxSeatsPerBooking, e.MaxSeatsPerUser, e.OneBookingPerUser).Scan(&id); err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
 func (r *repository) GetByID(ctx context.Context, id int64) (*Event, error) {
-	const q = `SELECT id, title, description, location, starts_at, ends_at, capacity, created_at, updated_at FROM events WHERE id=$1`
+	const q = `SELECT id, title, description, location, starts_at, ends_at, capacity, price, cancellation_policy, transfer_cutoff_hours,
+		max_seats_per_booking, max_seats_per_user, one_booking_per_user, COALESCE(organizer_id, 0) AS organizer_id, created_at, updated_at
+		FROM events WHERE id=$1`
 	var e Event
 	if err := r.db.GetContext(ctx, &e, q, id); err != nil {
 		return nil, err
@@ -46,7 +50,9 @@ func (r *repository) GetByID(ctx context.Context, id int64) (*Event, error) {
 
 func (r *repository) List(ctx context.Context, limit, offset int) ([]Event, error) {
 	const q = `
-        SELECT id, title, description, location, starts_at, ends_at, capacity, created_at, updated_at
+        SELECT id, title, description, location, starts_at, ends_at, capacity, price, cancellation_policy,
+            transfer_cutoff_hours, max_seats_per_booking, max_seats_per_user, one_booking_per_user,
+            COALESCE(organizer_id, 0) AS organizer_id, created_at, updated_at
         FROM events
         ORDER BY starts_at DESC
         LIMIT $1 OFFSET $2
@@ -61,10 +67,13 @@ func (r *repository) List(ctx context.Context, limit, offset int) ([]Event, erro
 func (r *repository) Update(ctx context.Context, e *Event) error {
 	const q = `
         UPDATE events
-        SET title=$1, description=$2, location=$3, starts_at=$4, ends_at=$5, capacity=$6, updated_at=NOW()
-        WHERE id=$7
+        SET title=$1, description=$2, location=$3, starts_at=$4, ends_at=$5, capacity=$6,
+            price=$7, cancellation_policy=$8, transfer_cutoff_hours=$9,
+            max_seats_per_booking=$10, max_seats_per_user=$11, one_booking_per_user=$12, updated_at=NOW()
+        WHERE id=$13
     `
-	_, err := r.db.ExecContext(ctx, q, e.Title, e.Description, e.Location, e.StartsAt, e.EndsAt, e.Capacity, e.ID)
+	_, err := r.db.ExecContext(ctx, q, e.Title, e.Description, e.Location, e.StartsAt, e.EndsAt, e.Capacity,
+		e.Price, e.CancellationPolicy, e.TransferCutoffHours, e.MaxSeatsPerBooking, e.MaxSeatsPerUser, e.OneBookingPerUser, e.ID)
 	return err
 }
 
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"laschool.ru/event-booking-service/internal/logger"
 )
 
+var tracer = otel.Tracer("laschool.ru/event-booking-service/internal/event")
+
 type Service interface {
 	Create(ctx context.Context, e *Event) (int64, error)
 	Get(ctx context.Context, id int64) (*Event, error)
@@ -32,10 +37,29 @@ func (s *service) Create(ctx context.Context, e *Event) (int64, error) {
 	if e.Capacity <= 0 {
 		return 0, errors.New("capacity must be positive")
 	}
-	return s.repo.Create(ctx, e)
+	if e.Price < 0 {
+		return 0, errors.New("price must not be negative")
+	}
+	if e.TransferCutoffHours < 0 {
+		return 0, errors.New("transfer_cutoff_hours must not be negative")
+	}
+	if err := e.validateLimits(); err != nil {
+		return 0, err
+	}
+	if err := e.CancellationPolicy.Validate(); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.Create(ctx, e)
+	if err != nil {
+		return 0, err
+	}
+	logger.FromContext(ctx).Info("event created", "event_id", id)
+	return id, nil
 }
 
 func (s *service) Get(ctx context.Context, id int64) (*Event, error) {
+	ctx, span := tracer.Start(ctx, "event.Get")
+	defer span.End()
 	return s.repo.GetByID(ctx, id)
 }
 
@@ -53,15 +77,35 @@ func (s *service) Update(ctx context.Context, e *Event) error {
 	if e.ID == 0 {
 		return errors.New("id is required")
 	}
+	if e.Price < 0 {
+		return errors.New("price must not be negative")
+	}
+	if e.TransferCutoffHours < 0 {
+		return errors.New("transfer_cutoff_hours must not be negative")
+	}
+	if err := e.validateLimits(); err != nil {
+		return err
+	}
+	if err := e.CancellationPolicy.Validate(); err != nil {
+		return err
+	}
 	if e.UpdatedAt.IsZero() {
 		e.UpdatedAt = time.Now()
 	}
-	return s.repo.Update(ctx, e)
+	if err := s.repo.Update(ctx, e); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("event updated", "event_id", e.ID)
+	return nil
 }
 
 func (s *service) Delete(ctx context.Context, id int64) error {
 	if id == 0 {
 		return errors.New("id is required")
 	}
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("event deleted", "event_id", id)
+	return nil
 }
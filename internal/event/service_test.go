@@ -42,3 +42,69 @@ func TestService_Create_Success(t *testing.T) {
 		t.Fatal("expected non-zero id")
 	}
 }
+
+func TestCancellationPolicy_RefundPercent(t *testing.T) {
+	starts := time.Date(2026, 1, 15, 18, 0, 0, 0, time.UTC)
+	policy := CancellationPolicy{{HoursBefore: 24, RefundPercent: 50}, {HoursBefore: 48, RefundPercent: 100}}
+	cases := []struct {
+		name    string
+		policy  CancellationPolicy
+		before  time.Duration
+		percent int
+		ok      bool
+	}{
+		{"free window", policy, 72 * time.Hour, 100, true},
+		{"free window edge", policy, 48 * time.Hour, 100, true},
+		{"fee window", policy, 30 * time.Hour, 50, true},
+		{"closed", policy, 10 * time.Hour, 0, false},
+		{"empty policy before start", nil, time.Minute, 100, true},
+		{"empty policy after start", nil, -time.Minute, 0, false},
+	}
+	for _, c := range cases {
+		percent, ok := c.policy.RefundPercent(starts, starts.Add(-c.before))
+		if ok != c.ok || (ok && percent != c.percent) {
+			t.Fatalf("%s: got %d%% ok=%v, want %d%% ok=%v", c.name, percent, ok, c.percent, c.ok)
+		}
+	}
+}
+
+func TestCancellationPolicy_Validate(t *testing.T) {
+	bad := []CancellationPolicy{
+		{{HoursBefore: -1, RefundPercent: 50}},
+		{{HoursBefore: 24, RefundPercent: 120}},
+		{{HoursBefore: 24, RefundPercent: 50}, {HoursBefore: 24, RefundPercent: 100}},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", p)
+		}
+	}
+	if err := (CancellationPolicy{{HoursBefore: 48, RefundPercent: 100}, {HoursBefore: 0, RefundPercent: 0}}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestService_Create_BookingLimits(t *testing.T) {
+	svc := NewService(repoStub{})
+	base := func() *Event {
+		return &Event{Title: "A", Capacity: 10, StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}
+	}
+
+	e := base()
+	e.MaxSeatsPerUser = -1
+	if _, err := svc.Create(context.Background(), e); err == nil {
+		t.Fatal("expected error for negative limit")
+	}
+
+	e = base()
+	e.MaxSeatsPerBooking, e.MaxSeatsPerUser = 5, 3
+	if _, err := svc.Create(context.Background(), e); err == nil {
+		t.Fatal("expected error when per-booking limit exceeds per-user limit")
+	}
+
+	e = base()
+	e.MaxSeatsPerBooking, e.MaxSeatsPerUser, e.OneBookingPerUser = 2, 4, true
+	if _, err := svc.Create(context.Background(), e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+// parseAttendeeTicketPath достаёт ID брони и посетителя из /bookings/{id}/attendees/{attendeeID}/ticket.png.
+func parseAttendeeTicketPath(path string) (bookingID, attendeeID int64, ok bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 5 || parts[2] != "attendees" || parts[4] != "ticket.png" {
+		return 0, 0, false
+	}
+	bookingID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	attendeeID, err = strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return bookingID, attendeeID, true
+}
+
+// writeAttendeesError переводит ошибки работы с посетителями в HTTP-статусы.
+func writeAttendeesError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, booking.ErrNotFound), errors.Is(err, booking.ErrAttendeeNotFound):
+		WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, booking.ErrNotOwner):
+		WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, booking.ErrAttendeesLocked), errors.Is(err, booking.ErrNotModifiable),
+		errors.Is(err, booking.ErrTooManyAttendees), errors.Is(err, booking.ErrTicketUnavailable):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, booking.ErrInvalidAttendees):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// ListAttendees godoc
+// @Summary      Посетители брони
+// @Description  Возвращает поимённых посетителей своей брони
+// @Tags         bookings
+// @Security     Bearer
+// @Produce      json
+// @Param        id   path  int  true  "ID бронирования"
+// @Success      200  {array}   booking.BookingAttendee
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Router       /bookings/{id}/attendees [get]
+func (h *BookingHandler) ListAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseSubpathID(r.URL.Path, "attendees")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	list, err := h.bookings.Attendees(r.Context(), id, userID)
+	if err != nil {
+		writeAttendeesError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
+
+// UpdateAttendees godoc
+// @Summary      Изменить посетителей брони
+// @Description  Заменяет список посетителей своей брони (не больше одного на место). Посетители с прежним email
+// @Description  сохраняют билет и закрепление за аккаунтом, у новых email — новые билеты, убранные билеты перестают действовать.
+// @Description  Менять список можно до отсечки перед началом события (booking.attendee_edit_cutoff); прошедших на вход убрать нельзя
+// @Tags         bookings
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id         path  int                             true  "ID бронирования"
+// @Param        attendees  body  booking.UpdateAttendeesRequest  true  "Новый список посетителей"
+// @Success      200  {array}   booking.BookingAttendee
+// @Failure      400  {object}  handlers.ErrorResponse  "Нет имени, некорректный или повторяющийся email"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Failure      409  {object}  handlers.ErrorResponse  "Редактирование закрыто, посетителей больше мест или бронь нельзя изменить"
+// @Router       /bookings/{id}/attendees [put]
+func (h *BookingHandler) UpdateAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseSubpathID(r.URL.Path, "attendees")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req booking.UpdateAttendeesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	list, err := h.bookings.ReplaceAttendees(r.Context(), id, userID, req.Attendees)
+	if err != nil {
+		writeAttendeesError(w, err)
+		return
+	}
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		if err := h.cache.Delete(ctx, fmt.Sprintf("booking:%d", id)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "booking_id", id, "error", err)
+		}
+	})
+	WriteJSON(w, http.StatusOK, list)
+}
+
+// GetAttendeeTicket godoc
+// @Summary      Билет посетителя
+// @Description  Возвращает PNG с QR-кодом билета на одно место. Доступен владельцу брони и аккаунту, закрепившему место
+// @Tags         bookings
+// @Security     Bearer
+// @Produce      png
+// @Param        id           path  int  true  "ID бронирования"
+// @Param        attendee_id  path  int  true  "ID посетителя"
+// @Success      200  {file}    binary  "QR-код билета"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь и чужое место"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование или посетитель не найдены"
+// @Failure      409  {object}  handlers.ErrorResponse  "Бронь не подтверждена"
+// @Router       /bookings/{id}/attendees/{attendee_id}/ticket.png [get]
+func (h *BookingHandler) GetAttendeeTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, attendeeID, ok := parseAttendeeTicketPath(r.URL.Path)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	code, err := h.bookings.AttendeeTicket(r.Context(), id, attendeeID, userID)
+	if err != nil {
+		writeAttendeesError(w, err)
+		return
+	}
+	writeTicketPNG(w, r, code)
+}
+
+// ClaimAttendees godoc
+// @Summary      Закрепить места за собой
+// @Description  Закрепляет за текущим пользователем места в чужих бронях, где он указан посетителем по email.
+// @Description  Email аккаунта должен быть подтверждён. После этого билет места можно получить самому
+// @Tags         users
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {array}   booking.BookingAttendee  "Места, закреплённые этим запросом"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
+// @Router       /users/me/attendees/claim [post]
+func (h *BookingHandler) ClaimAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	list, err := h.bookings.ClaimAttendees(r.Context(), userID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "failed to claim attendees")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
+
+// ListMyAttendees godoc
+// @Summary      Мои места в чужих бронях
+// @Description  Возвращает места действующих броней, закреплённые за текущим пользователем
+// @Tags         users
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {array}   booking.BookingAttendee
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
+// @Router       /users/me/attendees [get]
+func (h *BookingHandler) ListMyAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	list, err := h.bookings.ListClaimedAttendees(r.Context(), userID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "failed to list attendees")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+func TestUpdateAttendees(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	valid := []booking.AttendeeInput{{Name: "Анна", Email: "anna@example.com"}}
+	cases := []struct {
+		path   string
+		userID int64
+		list   []booking.AttendeeInput
+		want   int
+	}{
+		{"/bookings/7/attendees", 1, valid, http.StatusOK},
+		{"/bookings/7/attendees", 2, valid, http.StatusForbidden},
+		{"/bookings/404/attendees", 1, valid, http.StatusNotFound},
+		{"/bookings/409/attendees", 1, valid, http.StatusConflict},
+		{"/bookings/7/attendees", 1, []booking.AttendeeInput{{Email: "anna@example.com"}}, http.StatusBadRequest},
+		{"/bookings/abc/attendees", 1, valid, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		body, _ := json.Marshal(booking.UpdateAttendeesRequest{Attendees: c.list})
+		req := httptest.NewRequest(http.MethodPut, c.path, bytes.NewReader(body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+
+		h.UpdateAttendees(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("%s by user %d: expected status %d, got %d: %s", c.path, c.userID, c.want, w.Code, w.Body)
+		}
+		if w.Code == http.StatusOK {
+			var got []booking.BookingAttendee
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil || len(got) != 1 || got[0].ID == 0 {
+				t.Fatalf("unexpected body %v, %v", got, err)
+			}
+		}
+	}
+}
+
+func TestGetAttendeeTicket(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		path   string
+		userID int64
+		want   int
+	}{
+		{"/bookings/7/attendees/3/ticket.png", 1, http.StatusOK},
+		{"/bookings/7/attendees/3/ticket.png", 2, http.StatusForbidden},
+		{"/bookings/7/attendees/404/ticket.png", 1, http.StatusNotFound},
+		{"/bookings/7/attendees/x/ticket.png", 1, http.StatusBadRequest},
+		{"/bookings/7/attendees/3", 1, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+
+		h.GetAttendeeTicket(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("%s by user %d: expected status %d, got %d", c.path, c.userID, c.want, w.Code)
+		}
+		if w.Code == http.StatusOK && !bytes.HasPrefix(w.Body.Bytes(), []byte("\x89PNG")) {
+			t.Fatal("expected PNG body")
+		}
+	}
+}
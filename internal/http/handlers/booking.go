@@ -3,19 +3,35 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/skip2/go-qrcode"
+	"laschool.ru/event-booking-service/internal/background"
 	"laschool.ru/event-booking-service/internal/booking"
 	"laschool.ru/event-booking-service/internal/cache"
 	"laschool.ru/event-booking-service/internal/event"
-	"laschool.ru/event-booking-service/pkg/container"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/promo"
 )
 
+// BookingHandler обслуживает эндпоинты /bookings и /events/{id}/bookings.
+type BookingHandler struct {
+	bookings booking.Service
+	events   event.Service
+	cache    cache.Service
+	tasks    *background.Group
+}
+
+func NewBookingHandler(bookings booking.Service, events event.Service, cache cache.Service, tasks *background.Group) *BookingHandler {
+	return &BookingHandler{bookings: bookings, events: events, cache: cache, tasks: tasks}
+}
+
 func parseID(path string) (int64, bool) {
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	if len(parts) < 2 {
@@ -28,40 +44,74 @@ func parseID(path string) (int64, bool) {
 	return id, true
 }
 
+// parseSubpathID достаёт ID из пути вида /{resource}/{id}/{suffix}.
+func parseSubpathID(path, suffix string) (int64, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[2] != suffix {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePagination читает limit и offset из query; некорректные значения игнорируются,
+// границы проверяют сервисы.
+func parsePagination(r *http.Request) (limit, offset int) {
+	limit = 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if p, err := strconv.Atoi(v); err == nil {
+			limit = p
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if p, err := strconv.Atoi(v); err == nil {
+			offset = p
+		}
+	}
+	return limit, offset
+}
+
 // POST /bookings
 // CreateBooking godoc
 // @Summary      Создать бронирование
-// @Description  Создает новое бронирование для события
+// @Description  Создает новое бронирование для события. Можно сразу указать посетителей — не больше одного на место.
+// @Description  Бронь платного события создаётся в статусе pending вместе с платежом: в ответе client_secret для
+// @Description  платёжной формы и срок, до которого нужно оплатить. Подтверждает бронь уведомление провайдера.
+// @Description  promo_code даёт скидку на платное событие; если она покрывает всю сумму, бронь подтверждается сразу.
+// @Description  Неподходящий код — 422 с причиной: не найден, ещё не действует, истёк, исчерпан, не для этого события
 // @Tags         bookings
 // @Security     Bearer
 // @Accept       json
 // @Produce      json
 // @Param        booking  body  booking.CreateBookingRequest  true  "Данные бронирования"
-// @Success      201  {object}  map[string]int64  "id of created booking"
+// @Success      201  {object}  booking.Checkout  "id созданной брони; для платных событий — ещё и данные оплаты"
 // @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Email не подтверждён"
+// @Failure      409  {object}  handlers.ErrorResponse  "Превышены ограничения события на одного пользователя"
+// @Failure      422  {object}  handlers.ErrorResponse  "Промокод не применим"
+// @Failure      502  {object}  handlers.ErrorResponse  "Провайдер платежей не создал платёж"
+// @Failure      503  {object}  handlers.ErrorResponse  "Оплата не настроена"
 // @Router       /bookings [post]
-func CreateBooking(w http.ResponseWriter, r *http.Request) {
+func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	bsvc := ctn.Get(booking.DIBookingService).(booking.Service)
-	esvc := ctn.Get(event.DIEventService).(event.Service)
-	cachesrv := ctn.Get(cache.DICacheService).(cache.Service)
-
 	var req booking.CreateBookingRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		WriteError(w, http.StatusBadRequest, "invalid json")
 		return
 	}
+	// бронь оформляется на аутентифицированного пользователя, а не на user_id из тела
+	if userID, ok := reqctx.UserID(r.Context()); ok {
+		req.UserID = userID
+	}
 
-	e, err := esvc.Get(r.Context(), req.EventID)
+	e, err := h.events.Get(r.Context(), req.EventID)
 	if err != nil {
 		WriteError(w, http.StatusBadRequest, "event not found")
 		return
@@ -71,35 +121,63 @@ func CreateBooking(w http.ResponseWriter, r *http.Request) {
 		EventID:   req.EventID,
 		UserID:    req.UserID,
 		Seats:     req.Seats,
-		Status:    "confirmed",
+		Status:    booking.StatusConfirmed,
 		CreatedAt: time.Now(),
+		PromoCode: req.PromoCode,
+	}
+	for _, a := range req.Attendees {
+		newBooking.Attendees = append(newBooking.Attendees, booking.BookingAttendee{Name: a.Name, Email: a.Email})
+	}
+	// платное событие: бронь ждёт оплаты, бесплатное — подтверждается сразу
+	var checkout *booking.Checkout
+	var id int64
+	if e.Price > 0 {
+		checkout, err = h.bookings.Checkout(r.Context(), newBooking, e)
+		if err == nil {
+			id = checkout.BookingID
+		}
+	} else {
+		id, err = h.bookings.Create(r.Context(), newBooking, e.Capacity)
 	}
-	id, err := bsvc.Create(r.Context(), newBooking, e.Capacity)
 	if err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+		switch {
+		case errors.Is(err, booking.ErrEmailNotVerified):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case booking.IsLimitError(err):
+			WriteError(w, http.StatusConflict, err.Error())
+		case promo.IsRedeemError(err):
+			WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, booking.ErrPaymentUnavailable):
+			WriteError(w, http.StatusBadGateway, err.Error())
+		case errors.Is(err, booking.ErrPaymentsDisabled):
+			WriteError(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			WriteError(w, http.StatusBadRequest, err.Error())
+		}
 		return
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-
+	h.tasks.Go(r.Context(), 3*time.Second, func(ctx context.Context) {
 		// Удаляем кэш для этого события
 		pattern := fmt.Sprintf("event:%d:bookings*", req.EventID)
-		if err := cachesrv.DeletePattern(ctx, pattern); err != nil {
-			log.Printf("WARNING: Cache invalidation failed for event %d: %v", req.EventID, err)
+		if err := h.cache.DeletePattern(ctx, pattern); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", req.EventID, "error", err)
 		} else {
-			log.Printf("Cache invalidated for event %d after booking creation", req.EventID)
+			logger.FromContext(ctx).Debug("cache invalidated after booking creation", "event_id", req.EventID)
 		}
 
 		bookingKey := fmt.Sprintf("booking:%d", id)
-		if err := cachesrv.Set(ctx, bookingKey, newBooking, 30*time.Minute); err != nil {
-			log.Printf("WARNING: Failed to cache booking %d: %v", id, err)
+		if err := h.cache.Set(ctx, bookingKey, newBooking, 30*time.Minute); err != nil {
+			logger.FromContext(ctx).Warn("failed to cache booking", "booking_id", id, "error", err)
 		} else {
-			log.Printf("Booking %d cached successfully", id)
+			logger.FromContext(ctx).Debug("booking cached", "booking_id", id)
 		}
-	}()
-	writeJSON(w, http.StatusCreated, map[string]int64{"id": id})
+	})
+	if checkout != nil {
+		WriteJSON(w, http.StatusCreated, checkout)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]int64{"id": id})
 }
 
 // GetBooking godoc
@@ -113,7 +191,7 @@ func CreateBooking(w http.ResponseWriter, r *http.Request) {
 // @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /bookings/{id} [get]
-func GetBooking(w http.ResponseWriter, r *http.Request) {
+func (h *BookingHandler) GetBooking(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -123,18 +201,12 @@ func GetBooking(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	bsvc := ctn.Get(booking.DIBookingService).(booking.Service)
-	b, err := bsvc.Get(r.Context(), id)
+	b, err := h.bookings.Get(r.Context(), id)
 	if err != nil {
 		WriteError(w, http.StatusNotFound, "not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, b)
+	WriteJSON(w, http.StatusOK, b)
 }
 
 // ListBookingsByEvent godoc
@@ -150,7 +222,7 @@ func GetBooking(w http.ResponseWriter, r *http.Request) {
 // @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /events/{id}/bookings [get]
-func ListBookingsByEvent(w http.ResponseWriter, r *http.Request) {
+func (h *BookingHandler) ListBookingsByEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -166,25 +238,8 @@ func ListBookingsByEvent(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid event id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	bsvc := ctn.Get(booking.DIBookingService).(booking.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
 
-	limit, offset := 20, 0
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if p, err := strconv.Atoi(v); err == nil {
-			limit = p
-		}
-	}
-	if v := r.URL.Query().Get("offset"); v != "" {
-		if p, err := strconv.Atoi(v); err == nil {
-			offset = p
-		}
-	}
+	limit, offset := parsePagination(r)
 
 	if r.URL.Query().Get("refresh") == "true" {
 		// Удаляем ТОЛЬКО бронирования этого события
@@ -192,21 +247,21 @@ func ListBookingsByEvent(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 		defer cancel()
 
-		if err := cacheService.DeletePattern(ctx, pattern); err != nil {
-			log.Printf("Cache invalidation failed for event %d: %v", eventID, err)
+		if err := h.cache.DeletePattern(ctx, pattern); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", eventID, "error", err)
 			// Не возвращаем ошибку клиенту - продолжаем работу
 		} else {
-			log.Printf("Cache invalidated for event %d bookings (manual refresh)", eventID)
+			logger.FromContext(ctx).Debug("bookings cache invalidated (manual refresh)", "event_id", eventID)
 		}
 	}
 
 	cacheKey := fmt.Sprintf("event:%d:bookings:limit:%d:offset:%d", eventID, limit, offset)
 
 	calculateFunc := func() (interface{}, error) {
-		return bsvc.ListByEvent(r.Context(), eventID, limit, offset)
+		return h.bookings.ListByEvent(r.Context(), eventID, limit, offset)
 	}
 
-	data, err := cacheService.GetProtected(r.Context(), cacheKey, calculateFunc, 5*time.Minute)
+	data, err := h.cache.GetProtected(r.Context(), cacheKey, calculateFunc, 5*time.Minute)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, "failed to list bookings")
 		return
@@ -216,21 +271,131 @@ func ListBookingsByEvent(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusInternalServerError, "failed to parse cached data")
 		return
 	}
-	writeJSON(w, http.StatusOK, bookings)
+	WriteJSON(w, http.StatusOK, bookings)
+}
+
+// UpdateBooking godoc
+// @Summary      Изменить количество мест
+// @Description  Атомарно меняет количество мест в своей брони. Увеличение проверяется по вместимости события
+// @Tags         bookings
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                          true  "ID бронирования"
+// @Param        booking  body  booking.UpdateBookingRequest  true  "Новое количество мест"
+// @Success      200  {object}  booking.Booking
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
+// @Failure      404  {object}  handlers.ErrorResponse
+// @Failure      409  {object}  handlers.ErrorResponse  "Не хватает мест, превышены ограничения события, бронь нельзя изменить, бронь платная или с промокодом, или посетителей больше новых мест"
+// @Router       /bookings/{id} [patch]
+func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseID(r.URL.Path)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req booking.UpdateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	b, err := h.bookings.ChangeSeats(r.Context(), id, userID, req.Seats)
+	if err != nil {
+		switch {
+		case errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrNotOwner):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, booking.ErrNotEnoughSeats), errors.Is(err, booking.ErrNotModifiable),
+			errors.Is(err, booking.ErrTooManyAttendees), errors.Is(err, booking.ErrPaidSeatsChange), booking.IsLimitError(err):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			WriteError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		if err := h.cache.DeletePattern(ctx, fmt.Sprintf("event:%d:bookings*", b.EventID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", b.EventID, "error", err)
+		}
+		if err := h.cache.Delete(ctx, fmt.Sprintf("booking:%d", id)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "booking_id", id, "error", err)
+		}
+	})
+	WriteJSON(w, http.StatusOK, b)
+}
+
+// ListMyBookings godoc
+// @Summary      Мои бронирования
+// @Description  Возвращает бронирования текущего пользователя с кратким описанием событий
+// @Tags         bookings
+// @Security     Bearer
+// @Produce      json
+// @Param        status  query  string  false  "Фильтр"  Enums(confirmed, cancelled, upcoming, past)
+// @Param        limit   query  int     false  "Лимит записей"
+// @Param        offset  query  int     false  "Смещение"
+// @Success      200  {array}   booking.UserBooking
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный фильтр"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
+// @Router       /users/me/bookings [get]
+func (h *BookingHandler) ListMyBookings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	limit, offset := parsePagination(r)
+	filter := booking.ListFilter{Status: r.URL.Query().Get("status"), Limit: limit, Offset: offset}
+
+	list, err := h.bookings.ListByUser(r.Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, booking.ErrInvalidFilter) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "failed to list bookings")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
 }
 
 // CancelBooking godoc
 // @Summary      Отменить бронирование
-// @Description  Отменяет бронирование по ID
+// @Description  Отменяет свою бронь по ID с учётом политики отмены события и возвращает сумму возврата.
+// @Description  Переход фиксируется в истории статусов. По оплаченной брони возврат проводится через
+// @Description  провайдера в фоне: refund_id и refund_status показывают его состояние, после успешного
+// @Description  возврата бронь переходит в refunded
 // @Tags         bookings
 // @Security     Bearer
+// @Produce      json
 // @Param        id   path      int  true  "ID бронирования"
-// @Success      204  "Бронирование отменено"
+// @Success      200  {object}  booking.Cancellation  "Бронирование отменено"
 // @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
 // @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Failure      409  {object}  handlers.ErrorResponse  "Бронь уже отменена, по ней уже проходили, её статус не допускает отмены или окно отмены закрыто"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /bookings/{id} [delete]
-func CancelBooking(w http.ResponseWriter, r *http.Request) {
+func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -240,26 +405,159 @@ func CancelBooking(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	c, err := h.bookings.Cancel(r.Context(), id, userID, "")
 	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
+		switch {
+		case errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrNotOwner):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, booking.ErrAlreadyInStatus), errors.Is(err, booking.ErrInvalidTransition),
+			errors.Is(err, booking.ErrCancellationClosed), errors.Is(err, booking.ErrNotModifiable):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "failed to cancel booking")
+		}
 		return
 	}
-	bsvc := ctn.Get(booking.DIBookingService).(booking.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		// Удаляем все связанное с бронированиями
+		h.cache.DeletePattern(ctx, "event:*:bookings*")
+		h.cache.DeletePattern(ctx, fmt.Sprintf("booking:%d", id))
+		h.cache.DeletePattern(ctx, "stats:bookings*")
+	})
+	WriteJSON(w, http.StatusOK, c)
+}
 
-	if err := bsvc.Cancel(r.Context(), id); err != nil {
-		WriteError(w, http.StatusInternalServerError, "failed to cancel booking")
+// GetTicket godoc
+// @Summary      Билет с QR-кодом
+// @Description  Возвращает PNG с QR-кодом подписанного билета своей подтверждённой брони. Код сканируют на входе
+// @Tags         bookings
+// @Security     Bearer
+// @Produce      png
+// @Param        id   path  int  true  "ID бронирования"
+// @Success      200  {file}    binary  "QR-код билета"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Failure      409  {object}  handlers.ErrorResponse  "Бронь не подтверждена"
+// @Router       /bookings/{id}/ticket.png [get]
+func (h *BookingHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseSubpathID(r.URL.Path, "ticket.png")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
 
-		// Удаляем все связанное с бронированиями
-		cacheService.DeletePattern(ctx, "event:*:bookings*")
-		cacheService.DeletePattern(ctx, fmt.Sprintf("booking:%d", id))
-		cacheService.DeletePattern(ctx, "stats:bookings*")
-	}()
-	w.WriteHeader(http.StatusNoContent)
+	code, err := h.bookings.Ticket(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrNotOwner):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, booking.ErrTicketUnavailable):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "failed to issue ticket")
+		}
+		return
+	}
+	writeTicketPNG(w, r, code)
+}
+
+// writeTicketPNG отдаёт код билета картинкой с QR-кодом.
+func writeTicketPNG(w http.ResponseWriter, r *http.Request, code string) {
+	png, err := qrcode.Encode(code, qrcode.Medium, 512)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("qr encode failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to issue ticket")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	// билет — пропуск на событие, промежуточным кэшам его хранить незачем
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// CheckIn godoc
+// @Summary      Отметить посетителя
+// @Description  Проверяет отсканированный код билета и пропускает указанное число мест (по умолчанию все оставшиеся).
+// @Description  Бронь на несколько мест можно отмечать частями; повторный проход по отмеченным местам отклоняется.
+// @Description  Доступно сотрудникам (staff) и администраторам
+// @Tags         events
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                     true  "ID события"
+// @Param        checkin  body  booking.CheckInRequest  true  "Код билета"
+// @Success      200  {object}  booking.CheckIn
+// @Failure      400  {object}  handlers.ErrorResponse  "Недействительный билет или билет на другое событие"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Нет роли staff или admin"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Failure      409  {object}  handlers.ErrorResponse  "Уже отмечены все места или бронь не подтверждена"
+// @Router       /events/{id}/checkin [post]
+func (h *BookingHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	eventID, ok := parseSubpathID(r.URL.Path, "checkin")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	staffID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req booking.CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	res, err := h.bookings.CheckIn(r.Context(), eventID, req.Code, req.Seats, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrAlreadyCheckedIn), errors.Is(err, booking.ErrCheckInSeats),
+			errors.Is(err, booking.ErrTicketUnavailable), errors.Is(err, booking.ErrInvalidTransition):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			// недействительный билет, билет на другое событие, некорректное число мест
+			WriteError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		if err := h.cache.DeletePattern(ctx, fmt.Sprintf("event:%d:bookings*", eventID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", eventID, "error", err)
+		}
+		if err := h.cache.Delete(ctx, fmt.Sprintf("booking:%d", res.BookingID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "booking_id", res.BookingID, "error", err)
+		}
+	})
+	WriteJSON(w, http.StatusOK, res)
 }
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+func newBookingHandlerStub(capacity int) (*BookingHandler, *bookingServiceStub) {
+	bookings := &bookingServiceStub{}
+	events := &eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Capacity: capacity}}}
+	return NewBookingHandler(bookings, events, cacheStub{}, background.NewGroup()), bookings
+}
+
+func TestCreateBooking_Success(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	body := bytes.NewBufferString(`{"event_id":1,"user_id":1,"seats":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", body)
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]int64
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["id"] != 42 {
+		t.Fatalf("expected id 42, got %d", resp["id"])
+	}
+}
+
+func TestCreateBooking_UnknownEvent(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	body := bytes.NewBufferString(`{"event_id":2,"user_id":1,"seats":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", body)
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreateBooking_CapacityExceeded(t *testing.T) {
+	h, _ := newBookingHandlerStub(1)
+	body := bytes.NewBufferString(`{"event_id":1,"user_id":1,"seats":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", body)
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreateBooking_UnverifiedEmail(t *testing.T) {
+	h, bookings := newBookingHandlerStub(10)
+	bookings.unverified = map[int64]bool{5: true}
+	// user_id из тела игнорируется: бронь оформляется на пользователя из токена
+	body := bytes.NewBufferString(`{"event_id":1,"user_id":1,"seats":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", body)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 5))
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", w.Code)
+	}
+	if bookings.lastUserID != 5 {
+		t.Fatalf("expected booking for user 5, got %d", bookings.lastUserID)
+	}
+}
+
+func TestCreateBooking_UserLimit(t *testing.T) {
+	h, bookings := newBookingHandlerStub(10)
+	bookings.createErr = booking.ErrOneBookingPerUser
+	body := bytes.NewBufferString(`{"event_id":1,"seats":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", body)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 5))
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListBookingsByEvent(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	req := httptest.NewRequest(http.MethodGet, "/events/1/bookings", nil)
+	w := httptest.NewRecorder()
+
+	h.ListBookingsByEvent(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var list []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected 1 booking, got %d", len(list))
+	}
+}
+
+func TestUpdateBooking(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		path   string
+		userID int64
+		body   string
+		want   int
+	}{
+		{"/bookings/7", 1, `{"seats":3}`, http.StatusOK},
+		{"/bookings/7", 1, `{"seats":11}`, http.StatusConflict},
+		{"/bookings/7", 2, `{"seats":3}`, http.StatusForbidden},
+		{"/bookings/404", 1, `{"seats":3}`, http.StatusNotFound},
+		{"/bookings/abc", 1, `{"seats":3}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPatch, c.path, bytes.NewBufferString(c.body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+		h.UpdateBooking(w, req)
+		if w.Code != c.want {
+			t.Fatalf("%s %s as %d: expected status %d, got %d", c.path, c.body, c.userID, c.want, w.Code)
+		}
+	}
+}
+
+func TestListMyBookings(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+
+	w := httptest.NewRecorder()
+	h.ListMyBookings(w, httptest.NewRequest(http.MethodGet, "/users/me/bookings", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without user, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me/bookings?status=archived", nil)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 5))
+	w = httptest.NewRecorder()
+	h.ListMyBookings(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for unknown filter, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/me/bookings?status=upcoming&limit=5", nil)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 5))
+	w = httptest.NewRecorder()
+	h.ListMyBookings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var list []booking.UserBooking
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(list) != 1 || list[0].UserID != 5 || list[0].Event.Title != "Go Meetup" {
+		t.Fatalf("unexpected response %+v", list)
+	}
+}
+
+func TestCancelBooking(t *testing.T) {
+	h, bookings := newBookingHandlerStub(10)
+	cases := []struct {
+		path   string
+		userID int64
+		want   int
+	}{
+		{"/bookings/7", 1, http.StatusOK},
+		{"/bookings/7", 1, http.StatusConflict},
+		{"/bookings/409", 1, http.StatusConflict},
+		{"/bookings/8", 2, http.StatusForbidden},
+		{"/bookings/404", 1, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodDelete, c.path, nil)
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+
+		h.CancelBooking(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("%s by user %d: expected status %d, got %d", c.path, c.userID, c.want, w.Code)
+		}
+	}
+	if len(bookings.cancelled) != 1 || bookings.cancelled[0] != 7 {
+		t.Fatalf("expected booking 7 to be cancelled once, got %v", bookings.cancelled)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/bookings/8", nil)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 1))
+	w := httptest.NewRecorder()
+	h.CancelBooking(w, req)
+	var c booking.Cancellation
+	if err := json.NewDecoder(w.Body).Decode(&c); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if c.BookingID != 8 || c.Refund != 500 {
+		t.Fatalf("unexpected cancellation %+v", c)
+	}
+}
+
+func TestGetTicket(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		path   string
+		userID int64
+		want   int
+	}{
+		{"/bookings/7/ticket.png", 1, http.StatusOK},
+		{"/bookings/7/ticket.png", 2, http.StatusForbidden},
+		{"/bookings/404/ticket.png", 1, http.StatusNotFound},
+		{"/bookings/abc/ticket.png", 1, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+
+		h.GetTicket(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("%s by user %d: expected status %d, got %d", c.path, c.userID, c.want, w.Code)
+		}
+		if w.Code == http.StatusOK {
+			if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+				t.Fatalf("expected image/png, got %q", ct)
+			}
+			if !bytes.HasPrefix(w.Body.Bytes(), []byte("\x89PNG")) {
+				t.Fatal("expected PNG body")
+			}
+		}
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		code string
+		want int
+	}{
+		{"ticket-1", http.StatusOK},
+		{"used", http.StatusConflict},
+		{"forged", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		body, _ := json.Marshal(booking.CheckInRequest{Code: c.code})
+		req := httptest.NewRequest(http.MethodPost, "/events/3/checkin", bytes.NewReader(body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), 9))
+		w := httptest.NewRecorder()
+
+		h.CheckIn(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("code %q: expected status %d, got %d", c.code, c.want, w.Code)
+		}
+	}
+}
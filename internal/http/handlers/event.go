@@ -4,21 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"laschool.ru/event-booking-service/internal/background"
 	"laschool.ru/event-booking-service/internal/cache"
 	"laschool.ru/event-booking-service/internal/event"
-	"laschool.ru/event-booking-service/pkg/container"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
 )
 
-func writeJSON(w http.ResponseWriter, status int, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(v)
+// EventHandler обслуживает эндпоинты /events.
+type EventHandler struct {
+	events event.Service
+	cache  cache.Service
+	tasks  *background.Group
+}
+
+func NewEventHandler(events event.Service, cache cache.Service, tasks *background.Group) *EventHandler {
+	return &EventHandler{events: events, cache: cache, tasks: tasks}
 }
 
 func parseIDFromPath(path string) (int64, bool) {
@@ -44,20 +50,12 @@ func parseIDFromPath(path string) (int64, bool) {
 // @Success      201  {object}  map[string]int64  "id of created event"
 // @Failure      400  {object}  handlers.ErrorResponse
 // @Router       /events [post]
-func CreateEvent(w http.ResponseWriter, r *http.Request) {
+func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	svc := ctn.Get(event.DIEventService).(event.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
-
 	var req event.CreateEventRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -65,31 +63,37 @@ func CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	organizerID, _ := reqctx.UserID(r.Context())
 	newEvent := &event.Event{Title: req.Title,
-		Description: req.Description,
-		Location:    req.Location,
-		StartsAt:    req.StartsAt,
-		EndsAt:      req.EndsAt,
-		Capacity:    req.Capacity}
-
-	id, err := svc.Create(r.Context(), newEvent)
+		Description:         req.Description,
+		Location:            req.Location,
+		StartsAt:            req.StartsAt,
+		EndsAt:              req.EndsAt,
+		Capacity:            req.Capacity,
+		Price:               req.Price,
+		CancellationPolicy:  req.CancellationPolicy,
+		TransferCutoffHours: req.TransferCutoffHours,
+		MaxSeatsPerBooking:  req.MaxSeatsPerBooking,
+		MaxSeatsPerUser:     req.MaxSeatsPerUser,
+		OneBookingPerUser:   req.OneBookingPerUser,
+		OrganizerID:         organizerID}
+
+	id, err := h.events.Create(r.Context(), newEvent)
 	if err != nil {
 		WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
+	h.tasks.Go(r.Context(), 3*time.Second, func(ctx context.Context) {
 		eventKey := fmt.Sprintf("event:%d", id)
-		if err := cacheService.Set(ctx, eventKey, newEvent, 30*time.Minute); err != nil {
-			log.Printf("WARNING: Failed to cache event %d: %v", id, err)
+		if err := h.cache.Set(ctx, eventKey, newEvent, 30*time.Minute); err != nil {
+			logger.FromContext(ctx).Warn("failed to cache event", "event_id", id, "error", err)
 		} else {
-			log.Printf("Event %d cached successfully", id)
+			logger.FromContext(ctx).Debug("event cached", "event_id", id)
 		}
-	}()
+	})
 
-	writeJSON(w, http.StatusCreated, map[string]int64{"id": id})
+	WriteJSON(w, http.StatusCreated, map[string]int64{"id": id})
 }
 
 // GetEvent godoc
@@ -114,7 +118,7 @@ func CreateEvent(w http.ResponseWriter, r *http.Request) {
 // @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /events/{id} [get]
-func GetEvent(w http.ResponseWriter, r *http.Request) {
+func (h *EventHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -124,19 +128,13 @@ func GetEvent(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	svc := ctn.Get(event.DIEventService).(event.Service)
 
-	e, err := svc.Get(r.Context(), id)
+	e, err := h.events.Get(r.Context(), id)
 	if err != nil {
 		WriteError(w, http.StatusNotFound, "not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, e)
+	WriteJSON(w, http.StatusOK, e)
 }
 
 // ListEvents godoc
@@ -149,49 +147,30 @@ func GetEvent(w http.ResponseWriter, r *http.Request) {
 // @Success      200  {array}  event.Event  "Пример успешного ответа"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /events [get]
-func ListEvents(w http.ResponseWriter, r *http.Request) {
+func (h *EventHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-	svc := ctn.Get(event.DIEventService).(event.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
 
-	// простые параметры пагинации из query
-	limit := 20
-	offset := 0
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if p, err := strconv.Atoi(v); err == nil {
-			limit = p
-		}
-	}
-	if v := r.URL.Query().Get("offset"); v != "" {
-		if p, err := strconv.Atoi(v); err == nil {
-			offset = p
-		}
-	}
+	limit, offset := parsePagination(r)
 
 	// Принудительное обновление кэша
 	if r.URL.Query().Get("refresh") == "true" {
 		// Удаляем все кэшированные списки событий
-		if err := cacheService.DeletePattern(r.Context(), "events:*"); err != nil {
-			log.Printf("Cache invalidation failed: %v", err)
+		if err := h.cache.DeletePattern(r.Context(), "events:*"); err != nil {
+			logger.FromContext(r.Context()).Warn("events cache invalidation failed", "error", err)
 		} else {
-			log.Printf("Events cache invalidated")
+			logger.FromContext(r.Context()).Debug("events cache invalidated")
 		}
 	}
 
 	cacheKey := fmt.Sprintf("events:list:limit:%d:offset:%d", limit, offset)
 	calculateFunc := func() (interface{}, error) {
-		return svc.List(r.Context(), limit, offset)
+		return h.events.List(r.Context(), limit, offset)
 	}
 
-	data, err := cacheService.GetProtected(r.Context(), cacheKey, calculateFunc, 5*time.Minute)
+	data, err := h.cache.GetProtected(r.Context(), cacheKey, calculateFunc, 5*time.Minute)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, "failed to list events")
 		return
@@ -203,12 +182,12 @@ func ListEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, events)
+	WriteJSON(w, http.StatusOK, events)
 }
 
 // UpdateEvent godoc
 // @Summary      Обновить событие
-// @Description  Обновляет данные события по ID
+// @Description  Обновляет данные события по ID. Доступно организатору события и администраторам
 // @Tags         events
 // @Security     Bearer
 // @Accept       json
@@ -216,10 +195,12 @@ func ListEvents(w http.ResponseWriter, r *http.Request) {
 // @Param        event  body   event.CreateEventRequest  true  "Данные события"
 // @Success      204  "Событие обновлено"
 // @Failure      400  {object}  handlers.ErrorResponse  "Некорректные данные"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не организатор и не администратор"
 // @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /events/{id} [put]
-func UpdateEvent(w http.ResponseWriter, r *http.Request) {
+func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -229,21 +210,24 @@ func UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
+	// цену, политику отмены и ограничения меняет только организатор: иначе их можно подкрутить под свою бронь
+	if _, ok := requireOrganizer(w, r, h.events, id, "update the event"); !ok {
 		return
 	}
-	svc := ctn.Get(event.DIEventService).(event.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
 
 	var req struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		Location    string    `json:"location"`
-		StartsAt    time.Time `json:"starts_at"`
-		EndsAt      time.Time `json:"ends_at"`
-		Capacity    int       `json:"capacity"`
+		Title               string                   `json:"title"`
+		Description         string                   `json:"description"`
+		Location            string                   `json:"location"`
+		StartsAt            time.Time                `json:"starts_at"`
+		EndsAt              time.Time                `json:"ends_at"`
+		Capacity            int                      `json:"capacity"`
+		Price               int64                    `json:"price"`
+		CancellationPolicy  event.CancellationPolicy `json:"cancellation_policy"`
+		TransferCutoffHours int                      `json:"transfer_cutoff_hours"`
+		MaxSeatsPerBooking  int                      `json:"max_seats_per_booking"`
+		MaxSeatsPerUser     int                      `json:"max_seats_per_user"`
+		OneBookingPerUser   bool                     `json:"one_booking_per_user"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		WriteError(w, http.StatusBadRequest, "invalid json")
@@ -251,49 +235,54 @@ func UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	updatedEvent := &event.Event{
-		ID:          id,
-		Title:       req.Title,
-		Description: req.Description,
-		Location:    req.Location,
-		StartsAt:    req.StartsAt,
-		EndsAt:      req.EndsAt,
-		Capacity:    req.Capacity,
-		UpdatedAt:   time.Now(),
+		ID:                  id,
+		Title:               req.Title,
+		Description:         req.Description,
+		Location:            req.Location,
+		StartsAt:            req.StartsAt,
+		EndsAt:              req.EndsAt,
+		Capacity:            req.Capacity,
+		Price:               req.Price,
+		CancellationPolicy:  req.CancellationPolicy,
+		TransferCutoffHours: req.TransferCutoffHours,
+		MaxSeatsPerBooking:  req.MaxSeatsPerBooking,
+		MaxSeatsPerUser:     req.MaxSeatsPerUser,
+		OneBookingPerUser:   req.OneBookingPerUser,
+		UpdatedAt:           time.Now(),
 	}
 
-	err = svc.Update(r.Context(), updatedEvent)
+	err := h.events.Update(r.Context(), updatedEvent)
 	if err != nil {
 		WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
 		// 1. Обновляем событие
 		eventKey := fmt.Sprintf("event:%d", id)
-		cacheService.Set(ctx, eventKey, updatedEvent, 30*time.Minute)
+		h.cache.Set(ctx, eventKey, updatedEvent, 30*time.Minute)
 
 		// 2. Инвалидируем списки
-		cacheService.DeletePattern(ctx, "events:list*")
+		h.cache.DeletePattern(ctx, "events:list*")
 
-		log.Printf("Event %d cache updated", id)
-	}()
+		logger.FromContext(ctx).Debug("event cache updated", "event_id", id)
+	})
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // DeleteEvent godoc
 // @Summary      Удалить событие
-// @Description  Удаляет событие по ID
+// @Description  Удаляет событие по ID. Доступно организатору события и администраторам
 // @Tags         events
 // @Security     Bearer
 // @Param        id   path      int  true  "ID события"
 // @Success      204  "Событие удалено"
 // @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не организатор и не администратор"
 // @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
 // @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
 // @Router       /events/{id} [delete]
-func DeleteEvent(w http.ResponseWriter, r *http.Request) {
+func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -303,28 +292,21 @@ func DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid id")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, "container init failed")
+	if _, ok := requireOrganizer(w, r, h.events, id, "delete the event"); !ok {
 		return
 	}
-	svc := ctn.Get(event.DIEventService).(event.Service)
-	cacheService := ctn.Get(cache.DICacheService).(cache.Service)
 
-	if err := svc.Delete(r.Context(), id); err != nil {
+	if err := h.events.Delete(r.Context(), id); err != nil {
 		WriteError(w, http.StatusInternalServerError, "failed to delete event")
 		return
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
 		cacheKey := fmt.Sprintf("event:%d", id)
-		cacheService.Delete(ctx, cacheKey)
-		cacheService.DeletePattern(ctx, "events:list*")
+		h.cache.Delete(ctx, cacheKey)
+		h.cache.DeletePattern(ctx, "events:list*")
 
-		log.Printf("Event %d cache updated", id)
-	}()
+		logger.FromContext(ctx).Debug("event cache updated", "event_id", id)
+	})
 	w.WriteHeader(http.StatusNoContent)
 }
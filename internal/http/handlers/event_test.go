@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/event"
+)
+
+func TestGetEvent(t *testing.T) {
+	h := NewEventHandler(&eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Title: "A"}}}, cacheStub{}, background.NewGroup())
+
+	req := httptest.NewRequest(http.MethodGet, "/events/1", nil)
+	w := httptest.NewRecorder()
+	h.GetEvent(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/events/2", nil)
+	w = httptest.NewRecorder()
+	h.GetEvent(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestCreateEvent_Validation(t *testing.T) {
+	h := NewEventHandler(&eventServiceStub{}, cacheStub{}, background.NewGroup())
+	body := bytes.NewBufferString(`{"title":"","capacity":10}`)
+	req := httptest.NewRequest(http.MethodPost, "/events", body)
+	w := httptest.NewRecorder()
+
+	h.CreateEvent(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUpdateDeleteEvent_Access(t *testing.T) {
+	h := NewEventHandler(&eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Title: "A", OrganizerID: 5}}}, cacheStub{}, background.NewGroup())
+	body := `{"title":"A","capacity":10,"starts_at":"2026-01-15T18:00:00Z","ends_at":"2026-01-15T21:00:00Z"}`
+	cases := []struct {
+		userID int64
+		role   string
+		want   int
+	}{
+		{5, "user", http.StatusNoContent},
+		{6, "admin", http.StatusNoContent},
+		{6, "user", http.StatusForbidden},
+		{6, "staff", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := exportRequest("/events/1", c.userID, c.role)
+		req.Method, req.Body = http.MethodPut, io.NopCloser(bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		h.UpdateEvent(w, req)
+		if w.Code != c.want {
+			t.Fatalf("update as %d/%s: expected %d, got %d", c.userID, c.role, c.want, w.Code)
+		}
+
+		req = exportRequest("/events/1", c.userID, c.role)
+		req.Method = http.MethodDelete
+		w = httptest.NewRecorder()
+		h.DeleteEvent(w, req)
+		if w.Code != c.want {
+			t.Fatalf("delete as %d/%s: expected %d, got %d", c.userID, c.role, c.want, w.Code)
+		}
+	}
+}
+
+func TestUpdateEvent_LimitsOnlyByOrganizer(t *testing.T) {
+	events := &eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Title: "A", OrganizerID: 5, MaxSeatsPerBooking: 2, MaxSeatsPerUser: 4, OneBookingPerUser: true}}}
+	h := NewEventHandler(events, cacheStub{}, background.NewGroup())
+	update := func(userID int64) int {
+		body := `{"title":"A","capacity":10,"starts_at":"2026-01-15T18:00:00Z","ends_at":"2026-01-15T21:00:00Z",
+			"max_seats_per_booking":0,"max_seats_per_user":0,"one_booking_per_user":false}`
+		req := exportRequest("/events/1", userID, "user")
+		req.Method, req.Body = http.MethodPut, io.NopCloser(bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		h.UpdateEvent(w, req)
+		return w.Code
+	}
+
+	// посторонний не снимет ограничения, чтобы выкупить всё событие
+	if code := update(6); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+	if e := events.events[1]; e.MaxSeatsPerBooking != 2 || e.MaxSeatsPerUser != 4 || !e.OneBookingPerUser {
+		t.Fatalf("limits changed by a stranger: %+v", e)
+	}
+	if code := update(5); code != http.StatusNoContent {
+		t.Fatalf("expected 204 for organizer, got %d", code)
+	}
+	if e := events.events[1]; e.MaxSeatsPerBooking != 0 || e.OneBookingPerUser {
+		t.Fatalf("organizer could not change limits: %+v", e)
+	}
+}
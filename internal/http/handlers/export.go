@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+// roleAdmin совпадает с user.RoleAdmin: пакет user сам импортирует handlers
+const roleAdmin = "admin"
+
+var attendeeHeader = []string{"booking_id", "name", "email", "seats", "booked_at", "checked_in_seats", "check_in_status"}
+
+// checkInStatus — отметка на входе для таблицы посетителей.
+func checkInStatus(a *booking.Attendee) string {
+	switch {
+	case a.Status == booking.StatusNoShow:
+		return "no_show"
+	case a.CheckedInSeats == 0:
+		return "not_checked_in"
+	case a.CheckedInSeats < a.Seats:
+		return "partial"
+	default:
+		return "checked_in"
+	}
+}
+
+// safeCell экранирует значение, которое Excel или LibreOffice приняли бы за формулу:
+// имя вроде "=HYPERLINK(...)" вводит пользователь, а открывает таблицу организатор.
+func safeCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func attendeeRow(a *booking.Attendee) []string {
+	return []string{
+		strconv.FormatInt(a.BookingID, 10),
+		safeCell(a.Name),
+		safeCell(a.Email),
+		strconv.Itoa(a.Seats),
+		a.BookedAt.UTC().Format(time.RFC3339),
+		strconv.Itoa(a.CheckedInSeats),
+		checkInStatus(a),
+	}
+}
+
+// authorizeOrganizer проверяет, что текущий пользователь — организатор события или администратор,
+// и пишет ошибку в ответ, если нет. action — что запрещено, для текста ошибки.
+func (h *BookingHandler) authorizeOrganizer(w http.ResponseWriter, r *http.Request, suffix, action string) (int64, bool) {
+	eventID, ok := parseSubpathID(r.URL.Path, suffix)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid event id")
+		return 0, false
+	}
+	if _, ok := requireOrganizer(w, r, h.events, eventID, action); !ok {
+		return 0, false
+	}
+	return eventID, true
+}
+
+// requireOrganizer загружает событие и проверяет, что текущий пользователь — его организатор
+// или администратор; иначе пишет ошибку в ответ.
+func requireOrganizer(w http.ResponseWriter, r *http.Request, events event.Service, eventID int64, action string) (*event.Event, bool) {
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return nil, false
+	}
+	e, err := events.Get(r.Context(), eventID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "event not found")
+		return nil, false
+	}
+	if reqctx.Role(r.Context()) != roleAdmin && (e.OrganizerID == 0 || e.OrganizerID != userID) {
+		WriteError(w, http.StatusForbidden, "only the organizer or an admin can "+action)
+		return nil, false
+	}
+	return e, true
+}
+
+func setAttachment(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// ExportAttendeesCSV godoc
+// @Summary      Список посетителей (CSV)
+// @Description  Выгружает все подтверждённые брони события с именем и email пользователя, числом мест,
+// @Description  временем брони и отметкой на входе. Строки отдаются потоком прямо из базы.
+// @Description  Доступно организатору события и администраторам
+// @Tags         events
+// @Security     Bearer
+// @Produce      text/csv
+// @Param        id   path  int  true  "ID события"
+// @Success      200  {file}    binary  "CSV"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не организатор и не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
+// @Router       /events/{id}/attendees.csv [get]
+func (h *BookingHandler) ExportAttendeesCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	eventID, ok := h.authorizeOrganizer(w, r, "attendees.csv", "export attendees")
+	if !ok {
+		return
+	}
+
+	setAttachment(w, "text/csv; charset=utf-8", fmt.Sprintf("event-%d-attendees.csv", eventID))
+	cw := csv.NewWriter(w)
+	if err := cw.Write(attendeeHeader); err != nil {
+		return
+	}
+	rows := 0
+	err := h.bookings.EachAttendee(r.Context(), eventID, func(a *booking.Attendee) error {
+		if err := cw.Write(attendeeRow(a)); err != nil {
+			return err
+		}
+		// сбрасываем буфер пачками, чтобы клиент получал файл по мере чтения из базы
+		if rows++; rows%500 == 0 {
+			cw.Flush()
+			return cw.Error()
+		}
+		return nil
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	logExportResult(r.Context(), "csv", eventID, rows, err)
+}
+
+// ExportAttendeesXLSX godoc
+// @Summary      Список посетителей (XLSX)
+// @Description  То же, что attendees.csv, в формате Excel. Строки пишутся потоковым писателем листа
+// @Tags         events
+// @Security     Bearer
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        id   path  int  true  "ID события"
+// @Success      200  {file}    binary  "XLSX"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не организатор и не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
+// @Failure      500  {object}  handlers.ErrorResponse  "Внутренняя ошибка сервера"
+// @Router       /events/{id}/attendees.xlsx [get]
+func (h *BookingHandler) ExportAttendeesXLSX(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	eventID, ok := h.authorizeOrganizer(w, r, "attendees.xlsx", "export attendees")
+	if !ok {
+		return
+	}
+
+	// xlsx — zip-архив, поэтому файл собирается до отправки; потоковый писатель excelize
+	// держит строки во временном файле, а не в памяти
+	f := excelize.NewFile()
+	defer f.Close()
+	const sheet = "Sheet1"
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "failed to export attendees")
+		return
+	}
+	rows := 0
+	setRow := func(values ...any) error {
+		cell, err := excelize.CoordinatesToCellName(1, rows+1)
+		if err != nil {
+			return err
+		}
+		rows++
+		return sw.SetRow(cell, values)
+	}
+	header := make([]any, len(attendeeHeader))
+	for i, v := range attendeeHeader {
+		header[i] = v
+	}
+	err = setRow(header...)
+	if err == nil {
+		err = h.bookings.EachAttendee(r.Context(), eventID, func(a *booking.Attendee) error {
+			return setRow(a.BookingID, safeCell(a.Name), safeCell(a.Email), a.Seats, a.BookedAt.UTC(), a.CheckedInSeats, checkInStatus(a))
+		})
+	}
+	if err == nil {
+		err = sw.Flush()
+	}
+	if err != nil {
+		logExportResult(r.Context(), "xlsx", eventID, rows-1, err)
+		WriteError(w, http.StatusInternalServerError, "failed to export attendees")
+		return
+	}
+
+	setAttachment(w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		fmt.Sprintf("event-%d-attendees.xlsx", eventID))
+	err = f.Write(w)
+	logExportResult(r.Context(), "xlsx", eventID, rows-1, err)
+}
+
+func logExportResult(ctx context.Context, format string, eventID int64, rows int, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// заголовки уже отправлены — остаётся только оборвать файл и записать ошибку
+		logger.FromContext(ctx).Error("attendees export failed", "format", format, "event_id", eventID, "rows", rows, "error", err)
+		return
+	}
+	logger.FromContext(ctx).Info("attendees exported", "format", format, "event_id", eventID, "rows", rows)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+func newExportHandlerStub() *BookingHandler {
+	events := &eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Capacity: 10, OrganizerID: 5}}}
+	return NewBookingHandler(&bookingServiceStub{}, events, cacheStub{}, background.NewGroup())
+}
+
+func exportRequest(path string, userID int64, role string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	ctx := reqctx.WithRole(reqctx.WithUserID(req.Context(), userID), role)
+	return req.WithContext(ctx)
+}
+
+func TestExportAttendees_Access(t *testing.T) {
+	h := newExportHandlerStub()
+	cases := []struct {
+		path   string
+		userID int64
+		role   string
+		want   int
+	}{
+		{"/events/1/attendees.csv", 5, "user", http.StatusOK},
+		{"/events/1/attendees.csv", 6, "admin", http.StatusOK},
+		{"/events/1/attendees.csv", 6, "user", http.StatusForbidden},
+		{"/events/1/attendees.csv", 6, "staff", http.StatusForbidden},
+		{"/events/2/attendees.csv", 5, "user", http.StatusNotFound},
+		{"/events/x/attendees.csv", 5, "user", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ExportAttendeesCSV(w, exportRequest(c.path, c.userID, c.role))
+		if w.Code != c.want {
+			t.Fatalf("%s as %d/%s: expected %d, got %d", c.path, c.userID, c.role, c.want, w.Code)
+		}
+	}
+}
+
+func TestExportAttendeesCSV(t *testing.T) {
+	h := newExportHandlerStub()
+	w := httptest.NewRecorder()
+	h.ExportAttendeesCSV(w, exportRequest("/events/1/attendees.csv", 5, "user"))
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(records))
+	}
+	want := []string{"1", "Alice", "alice@example.com", "2", "2026-01-10T12:00:00Z", "1", "partial"}
+	for i, v := range want {
+		if records[1][i] != v {
+			t.Fatalf("row 1: got %v, want %v", records[1], want)
+		}
+	}
+	if records[2][1] != "Bob, Jr." || records[2][6] != "not_checked_in" {
+		t.Fatalf("row 2: got %v", records[2])
+	}
+}
+
+func TestExportAttendeesXLSX(t *testing.T) {
+	h := newExportHandlerStub()
+	w := httptest.NewRecorder()
+	h.ExportAttendeesXLSX(w, exportRequest("/events/1/attendees.xlsx", 6, "admin"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatalf("invalid xlsx: %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "booking_id" || rows[1][2] != "alice@example.com" || rows[2][6] != "not_checked_in" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+}
+
+func TestAttendeeRow_EscapesFormulas(t *testing.T) {
+	cases := map[string]string{
+		"=HYPERLINK(\"http://evil\")": "'=HYPERLINK(\"http://evil\")",
+		"+1+1":                        "'+1+1",
+		"-2+3":                        "'-2+3",
+		"@SUM(A1)":                    "'@SUM(A1)",
+		"\tcmd":                       "'\tcmd",
+		"\rcmd":                       "'\rcmd",
+		"Alice":                       "Alice",
+		"":                            "",
+	}
+	for name, want := range cases {
+		row := attendeeRow(&booking.Attendee{BookingID: 1, Name: name, Email: name})
+		if row[1] != want || row[2] != want {
+			t.Fatalf("%q: got %q/%q, want %q", name, row[1], row[2], want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// HealthCheck — проверка одной зависимости для /readyz.
+type HealthCheck struct {
+	Name    string
+	Timeout time.Duration
+	Check   func(ctx context.Context) error
+}
+
+// CheckResult — результат одной проверки в отчёте /readyz.
+type CheckResult struct {
+	Status    string  `json:"status" example:"ok"`
+	LatencyMS float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessReport — ответ /readyz.
+type ReadinessReport struct {
+	Status string                 `json:"status" example:"ready"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// HealthHandler обслуживает /livez и /readyz.
+type HealthHandler struct {
+	checks       []HealthCheck
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// SetShuttingDown переводит /readyz в "not ready", чтобы балансировщик перестал слать трафик.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Livez godoc
+// @Summary      Liveness-проба
+// @Description  Процесс жив и обрабатывает запросы. Зависимости не проверяются.
+// @Tags         health
+// @Produce      plain
+// @Success      200  "ok"
+// @Router       /livez [get]
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// Readyz godoc
+// @Summary      Readiness-проба
+// @Description  Проверяет PostgreSQL, Redis и версию миграций. Во время остановки сервиса возвращает 503.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  handlers.ReadinessReport
+// @Failure      503  {object}  handlers.ReadinessReport
+// @Router       /readyz [get]
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := ReadinessReport{Status: "ready", Checks: make(map[string]CheckResult, len(h.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c HealthCheck) {
+			defer wg.Done()
+			res := runCheck(r.Context(), c)
+			mu.Lock()
+			report.Checks[c.Name] = res
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for _, res := range report.Checks {
+		if res.Status != "ok" {
+			report.Status = "not ready"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	if h.shuttingDown.Load() {
+		report.Status = "not ready"
+		status = http.StatusServiceUnavailable
+	}
+	WriteJSON(w, status, report)
+}
+
+func runCheck(parent context.Context, c HealthCheck) CheckResult {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Check(ctx)
+	res := CheckResult{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = "error"
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func okCheck(name string) HealthCheck {
+	return HealthCheck{Name: name, Check: func(ctx context.Context) error { return nil }}
+}
+
+func TestReadyz_AllOK(t *testing.T) {
+	h := NewHealthHandler(okCheck("postgres"), okCheck("redis"))
+	w := httptest.NewRecorder()
+	h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var report ReadinessReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if report.Status != "ready" || len(report.Checks) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestReadyz_FailedAndTimedOutChecks(t *testing.T) {
+	h := NewHealthHandler(
+		okCheck("postgres"),
+		HealthCheck{Name: "redis", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		HealthCheck{Name: "slow", Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	)
+	w := httptest.NewRecorder()
+	h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+	var report ReadinessReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if report.Checks["postgres"].Status != "ok" || report.Checks["redis"].Status != "error" || report.Checks["slow"].Status != "error" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestReadyz_ShuttingDown(t *testing.T) {
+	h := NewHealthHandler(okCheck("postgres"))
+	h.SetShuttingDown()
+
+	w := httptest.NewRecorder()
+	h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.Livez(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected livez 200, got %d", w.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 type ErrorResponse struct {
-	Status  int         `json:"status"`
-	Message interface{} `json:"message"`
+	Status    int         `json:"status"`
+	Message   interface{} `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
 }
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/cache"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/payment"
+)
+
+// maxWebhookSize — ограничение тела уведомления провайдера
+const maxWebhookSize = 1 << 20
+
+// PaymentHandler принимает уведомления провайдера платежей.
+type PaymentHandler struct {
+	bookings booking.Service
+	provider payment.Provider
+	cache    cache.Service
+	tasks    *background.Group
+}
+
+func NewPaymentHandler(bookings booking.Service, provider payment.Provider, cache cache.Service, tasks *background.Group) *PaymentHandler {
+	return &PaymentHandler{bookings: bookings, provider: provider, cache: cache, tasks: tasks}
+}
+
+// WebhookResponse — результат обработки уведомления
+type WebhookResponse struct {
+	// Status — processed, ignored (уведомление не о наших платежах) или expired (оплата после срока брони)
+	Status string `json:"status" example:"processed"`
+}
+
+// PaymentWebhook godoc
+// @Summary      Уведомление об оплате
+// @Description  Принимает уведомление провайдера платежей, подписанное webhook_secret. Оплата подтверждает
+// @Description  ожидающую бронь и списывает заблокированные деньги; отмена платежа освобождает места.
+// @Description  Повторные уведомления безопасны. При 5xx провайдер присылает уведомление снова
+// @Tags         payments
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  handlers.WebhookResponse
+// @Failure      400  {object}  handlers.ErrorResponse  "Неверная подпись или тело"
+// @Failure      500  {object}  handlers.ErrorResponse  "Не удалось обработать, провайдер повторит"
+// @Router       /webhooks/payments [post]
+func (h *PaymentHandler) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	evt, err := h.provider.VerifyWebhook(payload, r.Header)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("payment webhook rejected", "error", err)
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if evt.Type == "" {
+		WriteJSON(w, http.StatusOK, WebhookResponse{Status: "ignored"})
+		return
+	}
+
+	b, err := h.bookings.HandlePayment(r.Context(), evt)
+	switch {
+	case errors.Is(err, booking.ErrPaymentNotFound):
+		// провайдер присылает уведомления обо всех платежах аккаунта, не только о наших
+		WriteJSON(w, http.StatusOK, WebhookResponse{Status: "ignored"})
+		return
+	case errors.Is(err, booking.ErrPaymentExpired):
+		WriteJSON(w, http.StatusOK, WebhookResponse{Status: "expired"})
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("payment webhook failed", "intent_id", evt.IntentID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to process payment")
+		return
+	}
+
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		invalidateBooking(ctx, h.cache, b)
+	})
+	WriteJSON(w, http.StatusOK, WebhookResponse{Status: "processed"})
+}
+
+// invalidateBooking сбрасывает кэш брони и списков броней её события.
+func invalidateBooking(ctx context.Context, c cache.Service, b *booking.Booking) {
+	if err := c.DeletePattern(ctx, fmt.Sprintf("event:%d:bookings*", b.EventID)); err != nil {
+		logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", b.EventID, "error", err)
+	}
+	if err := c.Delete(ctx, fmt.Sprintf("booking:%d", b.ID)); err != nil {
+		logger.FromContext(ctx).Warn("cache invalidation failed", "booking_id", b.ID, "error", err)
+	}
+}
+
+// RetryRefunds повторяет возвраты, брошенные незавершёнными. Запускается по расписанию.
+func (h *PaymentHandler) RetryRefunds(ctx context.Context) {
+	if err := h.bookings.RetryRefunds(ctx); err != nil {
+		logger.FromContext(ctx).Error("retry refunds failed", "error", err)
+	}
+}
+
+// ExpireUnpaid снимает просроченные неоплаченные брони и сбрасывает их кэш. Запускается по расписанию.
+func (h *PaymentHandler) ExpireUnpaid(ctx context.Context) {
+	expired, err := h.bookings.ExpirePending(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("expire unpaid bookings failed", "error", err)
+		return
+	}
+	for i := range expired {
+		invalidateBooking(ctx, h.cache, &expired[i])
+	}
+}
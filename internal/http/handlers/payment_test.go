@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/payment"
+)
+
+func TestCreateBooking_PaidEvent(t *testing.T) {
+	events := &eventServiceStub{events: map[int64]*event.Event{1: {ID: 1, Capacity: 10, Price: 150000}}}
+	h := NewBookingHandler(&bookingServiceStub{}, events, cacheStub{}, background.NewGroup())
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBufferString(`{"event_id":1,"seats":2}`))
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 1))
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var c booking.Checkout
+	if err := json.NewDecoder(w.Body).Decode(&c); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if c.BookingID != 42 || c.Status != booking.StatusPending || c.Amount != 300000 || c.ClientSecret == "" {
+		t.Fatalf("unexpected checkout %+v", c)
+	}
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	provider := payment.NewFake("whsec")
+	h := NewPaymentHandler(&bookingServiceStub{}, provider, cacheStub{}, background.NewGroup())
+	send := func(payload []byte, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", bytes.NewReader(payload))
+		req.Header = header
+		w := httptest.NewRecorder()
+		h.PaymentWebhook(w, req)
+		return w
+	}
+
+	cases := []struct {
+		intentID string
+		want     string
+	}{
+		{"pi_42", "processed"},
+		{"pi_late", "expired"},
+		{"pi_unknown", "ignored"},
+	}
+	for _, c := range cases {
+		w := send(provider.Webhook(payment.EventAuthorized, c.intentID))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", c.intentID, w.Code, w.Body.String())
+		}
+		var resp WebhookResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp.Status != c.want {
+			t.Fatalf("%s: expected %q, got %q", c.intentID, c.want, resp.Status)
+		}
+	}
+
+	payload, header := provider.Webhook(payment.EventAuthorized, "pi_42")
+	header.Set(payment.FakeSignatureHeader, "t=1,v1=00")
+	if w := send(payload, header); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for bad signature, got %d", w.Code)
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/promo"
+)
+
+// PromoHandler обслуживает эндпоинты /admin/promo-codes.
+type PromoHandler struct {
+	promos promo.Service
+}
+
+func NewPromoHandler(promos promo.Service) *PromoHandler {
+	return &PromoHandler{promos: promos}
+}
+
+func promoFromRequest(req *promo.PromoCodeRequest) *promo.PromoCode {
+	return &promo.PromoCode{
+		Code:       req.Code,
+		Kind:       req.Kind,
+		Value:      req.Value,
+		MaxUses:    req.MaxUses,
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+		EventIDs:   req.EventIDs,
+	}
+}
+
+// writePromoError пишет ошибку сохранения промокода.
+func writePromoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, promo.ErrNotFound):
+		WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, promo.ErrCodeExists):
+		WriteError(w, http.StatusConflict, err.Error())
+	default:
+		WriteError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// CreatePromoCode godoc
+// @Summary      Создать промокод
+// @Description  Скидка в процентах (kind=percent, value 1–100) или фиксированной суммой в копейках (kind=fixed).
+// @Description  max_uses — сколько броней можно оформить по коду (0 — без ограничения), valid_from и valid_until —
+// @Description  окно действия, event_ids — события, на которые действует код (пусто — на все). Код хранится
+// @Description  в верхнем регистре. Только для администраторов
+// @Tags         promo-codes
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        promo  body  promo.PromoCodeRequest  true  "Промокод"
+// @Success      201  {object}  map[string]int64  "id созданного промокода"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректные данные"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не администратор"
+// @Failure      409  {object}  handlers.ErrorResponse  "Код уже существует"
+// @Router       /admin/promo-codes [post]
+func (h *PromoHandler) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req promo.PromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	id, err := h.promos.Create(r.Context(), promoFromRequest(&req))
+	if err != nil {
+		writePromoError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, map[string]int64{"id": id})
+}
+
+// ListPromoCodes godoc
+// @Summary      Список промокодов
+// @Description  Промокоды с числом использований, новые первыми. Только для администраторов
+// @Tags         promo-codes
+// @Security     Bearer
+// @Produce      json
+// @Param        limit   query  int  false  "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param        offset  query  int  false  "Смещение"
+// @Success      200  {array}   promo.PromoCode
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не администратор"
+// @Router       /admin/promo-codes [get]
+func (h *PromoHandler) ListPromoCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit, offset := parsePagination(r)
+	list, err := h.promos.List(r.Context(), limit, offset)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("list promo codes failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to list promo codes")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
+
+// GetPromoCode godoc
+// @Summary      Получить промокод
+// @Description  Только для администраторов
+// @Tags         promo-codes
+// @Security     Bearer
+// @Produce      json
+// @Param        id   path      int  true  "ID промокода"
+// @Success      200  {object}  promo.PromoCode
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Промокод не найден"
+// @Router       /admin/promo-codes/{id} [get]
+func (h *PromoHandler) GetPromoCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseID(r.URL.Path)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	p, err := h.promos.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, promo.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.FromContext(r.Context()).Error("get promo code failed", "promo_code_id", id, "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to get promo code")
+		return
+	}
+	WriteJSON(w, http.StatusOK, p)
+}
+
+// UpdatePromoCode godoc
+// @Summary      Изменить промокод
+// @Description  Заменяет условия промокода. Число использований сохраняется; уже оформленные брони не меняются.
+// @Description  Только для администраторов
+// @Tags         promo-codes
+// @Security     Bearer
+// @Accept       json
+// @Param        id     path  int                     true  "ID промокода"
+// @Param        promo  body  promo.PromoCodeRequest  true  "Промокод"
+// @Success      204  "Промокод обновлён"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректные данные"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Промокод не найден"
+// @Failure      409  {object}  handlers.ErrorResponse  "Код уже существует"
+// @Router       /admin/promo-codes/{id} [put]
+func (h *PromoHandler) UpdatePromoCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseID(r.URL.Path)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req promo.PromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	p := promoFromRequest(&req)
+	p.ID = id
+	if err := h.promos.Update(r.Context(), p); err != nil {
+		writePromoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeletePromoCode godoc
+// @Summary      Удалить промокод
+// @Description  Код перестаёт применяться; скидка в уже оформленных бронях сохраняется. Только для администраторов
+// @Tags         promo-codes
+// @Security     Bearer
+// @Param        id   path  int  true  "ID промокода"
+// @Success      204  "Промокод удалён"
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Промокод не найден"
+// @Router       /admin/promo-codes/{id} [delete]
+func (h *PromoHandler) DeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseID(r.URL.Path)
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.promos.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, promo.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.FromContext(r.Context()).Error("delete promo code failed", "promo_code_id", id, "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to delete promo code")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/promo"
+)
+
+func TestPromoCodesCRUD(t *testing.T) {
+	promos := &promoServiceStub{codes: map[int64]*promo.PromoCode{}}
+	h := NewPromoHandler(promos)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.CreatePromoCode(w, httptest.NewRequest(http.MethodPost, "/admin/promo-codes", bytes.NewBufferString(body)))
+		return w
+	}
+	if w := create(`{"code":"spring25","kind":"percent","value":25,"max_uses":100,"event_ids":[1]}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create(`{"code":"SPRING25","kind":"fixed","value":5000}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate code, got %d", w.Code)
+	}
+	if w := create(`{"code":"SUMMER","kind":"bogo","value":1}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid kind, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	h.GetPromoCode(w, httptest.NewRequest(http.MethodGet, "/admin/promo-codes/1", nil))
+	var p promo.PromoCode
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil || p.Code != "SPRING25" || p.MaxUses != 100 {
+		t.Fatalf("unexpected promo code %+v: %v", p, err)
+	}
+
+	w = httptest.NewRecorder()
+	h.UpdatePromoCode(w, httptest.NewRequest(http.MethodPut, "/admin/promo-codes/1", bytes.NewBufferString(`{"code":"SPRING30","kind":"percent","value":30}`)))
+	if w.Code != http.StatusNoContent || promos.codes[1].Value != 30 {
+		t.Fatalf("expected 204 and updated value, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.DeletePromoCode(w, httptest.NewRequest(http.MethodDelete, "/admin/promo-codes/1", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.GetPromoCode(w, httptest.NewRequest(http.MethodGet, "/admin/promo-codes/1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestCreateBooking_PromoCodeRejected(t *testing.T) {
+	h, bookings := newBookingHandlerStub(10)
+	bookings.createErr = promo.ErrExpired
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewBufferString(`{"event_id":1,"seats":2,"promo_code":"OLD"}`))
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 5))
+	w := httptest.NewRecorder()
+
+	h.CreateBooking(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Message != promo.ErrExpired.Error() {
+		t.Fatalf("expected reason %q, got %+v", promo.ErrExpired, resp)
+	}
+}
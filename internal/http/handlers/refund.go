@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+// ListEventRefunds godoc
+// @Summary      Возвраты по событию
+// @Description  Возвраты оплаты по отменённым броням события, новые первыми. requested — возврат проводится,
+// @Description  succeeded — деньги вернулись, failed — провайдер отказал или закончились попытки,
+// @Description  вернуть нужно вручную. Доступно организатору события и администраторам
+// @Tags         events
+// @Security     Bearer
+// @Produce      json
+// @Param        id   path  int  true  "ID события"
+// @Success      200  {array}   booking.Refund
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный ID"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Не организатор и не администратор"
+// @Failure      404  {object}  handlers.ErrorResponse  "Событие не найдено"
+// @Router       /events/{id}/refunds [get]
+func (h *BookingHandler) ListEventRefunds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	eventID, ok := h.authorizeOrganizer(w, r, "refunds", "view refunds")
+	if !ok {
+		return
+	}
+	list, err := h.bookings.RefundsByEvent(r.Context(), eventID)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("list refunds failed", "event_id", eventID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "failed to list refunds")
+		return
+	}
+	WriteJSON(w, http.StatusOK, list)
+}
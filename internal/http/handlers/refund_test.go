@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/booking"
+)
+
+func TestListEventRefunds(t *testing.T) {
+	h := newExportHandlerStub()
+	cases := []struct {
+		userID int64
+		role   string
+		want   int
+	}{
+		{5, "user", http.StatusOK},
+		{6, "admin", http.StatusOK},
+		{6, "user", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ListEventRefunds(w, exportRequest("/events/1/refunds", c.userID, c.role))
+		if w.Code != c.want {
+			t.Fatalf("as %d/%s: expected %d, got %d", c.userID, c.role, c.want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ListEventRefunds(w, exportRequest("/events/1/refunds", 5, "user"))
+	var list []booking.Refund
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(list) != 1 || list[0].EventID != 1 || list[0].Status != booking.RefundSucceeded {
+		t.Fatalf("unexpected refunds %+v", list)
+	}
+}
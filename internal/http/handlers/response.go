@@ -5,11 +5,21 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader — заголовок с ID запроса, его выставляет middleware.RequestIDMiddleware.
+const RequestIDHeader = "X-Request-ID"
+
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
 func WriteError(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(ErrorResponse{
-		Status:  status,
-		Message: v,
+		Status:    status,
+		Message:   v,
+		RequestID: w.Header().Get(RequestIDHeader),
 	})
 }
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/event"
+	"laschool.ru/event-booking-service/internal/payment"
+	"laschool.ru/event-booking-service/internal/promo"
+)
+
+type eventServiceStub struct {
+	events map[int64]*event.Event
+}
+
+func (s *eventServiceStub) Create(ctx context.Context, e *event.Event) (int64, error) {
+	if e.Title == "" {
+		return 0, errors.New("title is required")
+	}
+	return 1, nil
+}
+func (s *eventServiceStub) Get(ctx context.Context, id int64) (*event.Event, error) {
+	e, ok := s.events[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return e, nil
+}
+func (s *eventServiceStub) List(ctx context.Context, limit, offset int) ([]event.Event, error) {
+	var list []event.Event
+	for _, e := range s.events {
+		list = append(list, *e)
+	}
+	return list, nil
+}
+func (s *eventServiceStub) Update(ctx context.Context, e *event.Event) error {
+	if s.events != nil {
+		e.OrganizerID = s.events[e.ID].OrganizerID
+		s.events[e.ID] = e
+	}
+	return nil
+}
+func (s *eventServiceStub) Delete(ctx context.Context, id int64) error { return nil }
+
+type bookingServiceStub struct {
+	used       int
+	cancelled  []int64
+	unverified map[int64]bool
+	lastUserID int64
+	createErr  error
+}
+
+func (s *bookingServiceStub) Create(ctx context.Context, b *booking.Booking, eventCapacity int) (int64, error) {
+	s.lastUserID = b.UserID
+	if s.unverified[b.UserID] {
+		return 0, booking.ErrEmailNotVerified
+	}
+	if s.createErr != nil {
+		return 0, s.createErr
+	}
+	if s.used+b.Seats > eventCapacity {
+		return 0, errors.New("not enough seats")
+	}
+	s.used += b.Seats
+	return 42, nil
+}
+func (s *bookingServiceStub) Checkout(ctx context.Context, b *booking.Booking, e *event.Event) (*booking.Checkout, error) {
+	if _, err := s.Create(ctx, b, e.Capacity); err != nil {
+		return nil, err
+	}
+	return &booking.Checkout{BookingID: 42, Status: booking.StatusPending, Amount: e.Price * int64(b.Seats),
+		Currency: "rub", Provider: "fake", ClientSecret: "pi_42_secret"}, nil
+}
+func (s *bookingServiceStub) HandlePayment(ctx context.Context, evt *payment.WebhookEvent) (*booking.Booking, error) {
+	switch evt.IntentID {
+	case "pi_42":
+		return &booking.Booking{ID: 42, EventID: 1, Status: booking.StatusConfirmed}, nil
+	case "pi_late":
+		return nil, booking.ErrPaymentExpired
+	}
+	return nil, booking.ErrPaymentNotFound
+}
+func (s *bookingServiceStub) ExpirePending(ctx context.Context) ([]booking.Booking, error) {
+	return nil, nil
+}
+func (s *bookingServiceStub) RefundsByEvent(ctx context.Context, eventID int64) ([]booking.Refund, error) {
+	return []booking.Refund{{ID: 1, BookingID: 1, EventID: eventID, Amount: 1500, Status: booking.RefundSucceeded}}, nil
+}
+func (s *bookingServiceStub) RetryRefunds(ctx context.Context) error { return nil }
+func (s *bookingServiceStub) CreateBatch(ctx context.Context, bookings []*booking.Booking, actorID int64, opts booking.BatchOptions) ([]error, error) {
+	return make([]error, len(bookings)), nil
+}
+func (s *bookingServiceStub) Get(ctx context.Context, id int64) (*booking.Booking, error) {
+	return &booking.Booking{ID: id, Status: "confirmed"}, nil
+}
+func (s *bookingServiceStub) ListByEvent(ctx context.Context, eventID int64, limit, offset int) ([]booking.Booking, error) {
+	return []booking.Booking{{ID: 1, EventID: eventID}}, nil
+}
+func (s *bookingServiceStub) ChangeSeats(ctx context.Context, id, actorID int64, seats int) (*booking.Booking, error) {
+	switch {
+	case id == 404:
+		return nil, booking.ErrNotFound
+	case actorID != 1:
+		return nil, booking.ErrNotOwner
+	case seats > 10:
+		return nil, booking.ErrNotEnoughSeats
+	}
+	return &booking.Booking{ID: id, EventID: 1, UserID: actorID, Seats: seats, Status: "confirmed"}, nil
+}
+func (s *bookingServiceStub) ListByUser(ctx context.Context, userID int64, f booking.ListFilter) ([]booking.UserBooking, error) {
+	if f.Status == "archived" {
+		return nil, booking.ErrInvalidFilter
+	}
+	return []booking.UserBooking{{
+		Booking: booking.Booking{ID: 1, UserID: userID},
+		Event:   booking.EventSummary{ID: 1, Title: "Go Meetup"},
+	}}, nil
+}
+func (s *bookingServiceStub) Cancel(ctx context.Context, id, actorID int64, reason string) (*booking.Cancellation, error) {
+	switch {
+	case id == 404:
+		return nil, booking.ErrNotFound
+	case id == 409:
+		return nil, booking.ErrCancellationClosed
+	case actorID != 1:
+		return nil, booking.ErrNotOwner
+	case slices.Contains(s.cancelled, id):
+		return nil, booking.ErrAlreadyInStatus
+	}
+	s.cancelled = append(s.cancelled, id)
+	return &booking.Cancellation{BookingID: id, Amount: 1000, RefundPercent: 50, Refund: 500, Fee: 500}, nil
+}
+func (s *bookingServiceStub) Ticket(ctx context.Context, id, actorID int64) (string, error) {
+	switch {
+	case id == 404:
+		return "", booking.ErrNotFound
+	case actorID != 1:
+		return "", booking.ErrNotOwner
+	}
+	return fmt.Sprintf("ticket-%d", id), nil
+}
+func (s *bookingServiceStub) CheckIn(ctx context.Context, eventID int64, code string, seats int, staffID int64) (*booking.CheckIn, error) {
+	switch code {
+	case "ticket-1":
+		return &booking.CheckIn{BookingID: 1, EventID: eventID, Seats: 2, Admitted: 2, CheckedInSeats: 2, Status: booking.StatusCheckedIn}, nil
+	case "used":
+		return nil, booking.ErrAlreadyCheckedIn
+	}
+	return nil, booking.ErrInvalidTicket
+}
+func (s *bookingServiceStub) EachAttendee(ctx context.Context, eventID int64, fn func(a *booking.Attendee) error) error {
+	attendees := []booking.Attendee{
+		{BookingID: 1, UserID: 1, Name: "Alice", Email: "alice@example.com", Seats: 2, CheckedInSeats: 1, Status: booking.StatusConfirmed, BookedAt: time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{BookingID: 2, UserID: 2, Name: "Bob, Jr.", Email: "bob@example.com", Seats: 1, Status: booking.StatusConfirmed, BookedAt: time.Date(2026, 1, 11, 12, 0, 0, 0, time.UTC)},
+	}
+	for i := range attendees {
+		if err := fn(&attendees[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *bookingServiceStub) Attendees(ctx context.Context, id, actorID int64) ([]booking.BookingAttendee, error) {
+	return []booking.BookingAttendee{{ID: 1, BookingID: id, Name: "Анна", Email: "anna@example.com"}}, nil
+}
+func (s *bookingServiceStub) ReplaceAttendees(ctx context.Context, id, actorID int64, list []booking.AttendeeInput) ([]booking.BookingAttendee, error) {
+	switch {
+	case id == 404:
+		return nil, booking.ErrNotFound
+	case id == 409:
+		return nil, booking.ErrAttendeesLocked
+	case actorID != 1:
+		return nil, booking.ErrNotOwner
+	}
+	out := make([]booking.BookingAttendee, len(list))
+	for i, a := range list {
+		if a.Name == "" {
+			return nil, fmt.Errorf("%w: attendee %d has no name", booking.ErrInvalidAttendees, i+1)
+		}
+		out[i] = booking.BookingAttendee{ID: int64(i + 1), BookingID: id, Name: a.Name, Email: a.Email}
+	}
+	return out, nil
+}
+func (s *bookingServiceStub) AttendeeTicket(ctx context.Context, id, attendeeID, actorID int64) (string, error) {
+	switch {
+	case attendeeID == 404:
+		return "", booking.ErrAttendeeNotFound
+	case actorID != 1:
+		return "", booking.ErrNotOwner
+	}
+	return fmt.Sprintf("ticket-%d-%d", id, attendeeID), nil
+}
+func (s *bookingServiceStub) ClaimAttendees(ctx context.Context, userID int64) ([]booking.BookingAttendee, error) {
+	return []booking.BookingAttendee{{ID: 1, BookingID: 7, UserID: userID}}, nil
+}
+func (s *bookingServiceStub) ListClaimedAttendees(ctx context.Context, userID int64) ([]booking.BookingAttendee, error) {
+	return []booking.BookingAttendee{{ID: 1, BookingID: 7, UserID: userID}}, nil
+}
+func (s *bookingServiceStub) RequestTransfer(ctx context.Context, id, actorID int64, email string) (*booking.Transfer, error) {
+	switch {
+	case id == 404:
+		return nil, booking.ErrNotFound
+	case id == 409:
+		return nil, booking.ErrTransferClosed
+	case actorID != 1:
+		return nil, booking.ErrNotOwner
+	}
+	return &booking.Transfer{ID: 1, BookingID: id, FromUserID: actorID, ToEmail: email, Status: booking.TransferPending}, nil
+}
+func (s *bookingServiceStub) AcceptTransfer(ctx context.Context, token string, userID int64) (*booking.Booking, error) {
+	switch token {
+	case "valid":
+		return &booking.Booking{ID: 7, EventID: 1, UserID: userID, Seats: 2, Status: booking.StatusConfirmed}, nil
+	case "expired":
+		return nil, booking.ErrTransferExpired
+	case "foreign":
+		return nil, booking.ErrTransferRecipient
+	}
+	return nil, booking.ErrTransferNotFound
+}
+func (s *bookingServiceStub) Transition(ctx context.Context, id int64, to booking.Status, actorID int64, reason string) (*booking.Booking, error) {
+	return &booking.Booking{ID: id, Status: to}, nil
+}
+
+// cacheStub — кэш без Redis: всегда промах, calculate вызывается напрямую.
+// promoServiceStub хранит коды в памяти; коды сравниваются без учёта регистра
+type promoServiceStub struct {
+	codes map[int64]*promo.PromoCode
+}
+
+func (s *promoServiceStub) Create(ctx context.Context, p *promo.PromoCode) (int64, error) {
+	if p.Kind != promo.KindPercent && p.Kind != promo.KindFixed {
+		return 0, errors.New("kind must be percent or fixed")
+	}
+	for _, c := range s.codes {
+		if c.Code == promo.NormalizeCode(p.Code) {
+			return 0, promo.ErrCodeExists
+		}
+	}
+	p.ID, p.Code = int64(len(s.codes)+1), promo.NormalizeCode(p.Code)
+	s.codes[p.ID] = p
+	return p.ID, nil
+}
+func (s *promoServiceStub) Get(ctx context.Context, id int64) (*promo.PromoCode, error) {
+	if p, ok := s.codes[id]; ok {
+		return p, nil
+	}
+	return nil, promo.ErrNotFound
+}
+func (s *promoServiceStub) List(ctx context.Context, limit, offset int) ([]promo.PromoCode, error) {
+	list := []promo.PromoCode{}
+	for _, p := range s.codes {
+		list = append(list, *p)
+	}
+	return list, nil
+}
+func (s *promoServiceStub) Update(ctx context.Context, p *promo.PromoCode) error {
+	if _, ok := s.codes[p.ID]; !ok {
+		return promo.ErrNotFound
+	}
+	s.codes[p.ID] = p
+	return nil
+}
+func (s *promoServiceStub) Delete(ctx context.Context, id int64) error {
+	if _, ok := s.codes[id]; !ok {
+		return promo.ErrNotFound
+	}
+	delete(s.codes, id)
+	return nil
+}
+
+type cacheStub struct{}
+
+func (cacheStub) Get(ctx context.Context, key string, target interface{}) (bool, error) {
+	return false, nil
+}
+func (cacheStub) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+func (cacheStub) Delete(ctx context.Context, key string) error            { return nil }
+func (cacheStub) DeletePattern(ctx context.Context, pattern string) error { return nil }
+func (cacheStub) WithJitter(baseTTL time.Duration) time.Duration          { return baseTTL }
+func (cacheStub) GetProtected(ctx context.Context, key string, calculate func() (interface{}, error), baseTTL time.Duration) ([]byte, error) {
+	v, err := calculate()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+func (cacheStub) GetWithLock(ctx context.Context, key string, calculate func() (interface{}, error), baseTTL time.Duration) (interface{}, error) {
+	return calculate()
+}
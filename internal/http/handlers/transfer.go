@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+// TransferBooking godoc
+// @Summary      Передать бронь
+// @Description  Предлагает свою подтверждённую бронь другому пользователю: на указанный email уходит письмо со ссылкой.
+// @Description  Бронь переходит к получателю, когда он принимает передачу. Новая передача заменяет ожидающую.
+// @Description  Нельзя после прохода на вход и позже transfer_cutoff_hours до начала события
+// @Tags         bookings
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        id        path  int                      true  "ID бронирования"
+// @Param        transfer  body  booking.TransferRequest  true  "Email получателя"
+// @Success      202  {object}  booking.Transfer
+// @Failure      400  {object}  handlers.ErrorResponse  "Некорректный email или передача самому себе"
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Чужая бронь"
+// @Failure      404  {object}  handlers.ErrorResponse  "Бронирование не найдено"
+// @Failure      409  {object}  handlers.ErrorResponse  "Окно передачи закрыто или бронь нельзя передать"
+// @Router       /bookings/{id}/transfer [post]
+func (h *BookingHandler) TransferBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, ok := parseSubpathID(r.URL.Path, "transfer")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req booking.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	t, err := h.bookings.RequestTransfer(r.Context(), id, userID, req.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrNotOwner):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, booking.ErrTransferClosed), errors.Is(err, booking.ErrNotModifiable):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			WriteError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, t)
+}
+
+// AcceptTransfer godoc
+// @Summary      Принять переданную бронь
+// @Description  Переоформляет бронь на текущего пользователя по токену из письма. Email аккаунта должен совпадать
+// @Description  с адресом получателя и быть подтверждён. Билеты прежнего владельца перестают действовать — новый билет
+// @Description  выдаётся через GET /bookings/{id}/ticket.png
+// @Tags         bookings
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        transfer  body  booking.AcceptTransferRequest  true  "Токен из письма"
+// @Success      200  {object}  booking.Booking
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse  "Передача адресована другому email"
+// @Failure      404  {object}  handlers.ErrorResponse  "Передача не найдена или уже принята"
+// @Failure      409  {object}  handlers.ErrorResponse  "Окно передачи закрыто, бронь больше нельзя передать или у получателя превышены ограничения события"
+// @Failure      410  {object}  handlers.ErrorResponse  "Приглашение истекло"
+// @Router       /bookings/transfers/accept [post]
+func (h *BookingHandler) AcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	userID, ok := reqctx.UserID(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req booking.AcceptTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	b, err := h.bookings.AcceptTransfer(r.Context(), req.Token, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, booking.ErrTransferNotFound), errors.Is(err, booking.ErrNotFound):
+			WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, booking.ErrTransferRecipient):
+			WriteError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, booking.ErrTransferExpired):
+			WriteError(w, http.StatusGone, err.Error())
+		case errors.Is(err, booking.ErrTransferClosed), errors.Is(err, booking.ErrNotModifiable),
+			booking.IsLimitError(err):
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "failed to accept transfer")
+		}
+		return
+	}
+
+	h.tasks.Go(r.Context(), 2*time.Second, func(ctx context.Context) {
+		if err := h.cache.DeletePattern(ctx, fmt.Sprintf("event:%d:bookings*", b.EventID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "event_id", b.EventID, "error", err)
+		}
+		if err := h.cache.Delete(ctx, fmt.Sprintf("booking:%d", b.ID)); err != nil {
+			logger.FromContext(ctx).Warn("cache invalidation failed", "booking_id", b.ID, "error", err)
+		}
+	})
+	WriteJSON(w, http.StatusOK, b)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/booking"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+func TestTransferBooking(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		path   string
+		userID int64
+		want   int
+	}{
+		{"/bookings/7/transfer", 1, http.StatusAccepted},
+		{"/bookings/7/transfer", 2, http.StatusForbidden},
+		{"/bookings/404/transfer", 1, http.StatusNotFound},
+		{"/bookings/409/transfer", 1, http.StatusConflict},
+		{"/bookings/abc/transfer", 1, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		body, _ := json.Marshal(booking.TransferRequest{Email: "colleague@example.com"})
+		req := httptest.NewRequest(http.MethodPost, c.path, bytes.NewReader(body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), c.userID))
+		w := httptest.NewRecorder()
+
+		h.TransferBooking(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("%s by user %d: expected status %d, got %d", c.path, c.userID, c.want, w.Code)
+		}
+	}
+}
+
+func TestAcceptTransfer(t *testing.T) {
+	h, _ := newBookingHandlerStub(10)
+	cases := []struct {
+		token string
+		want  int
+	}{
+		{"valid", http.StatusOK},
+		{"expired", http.StatusGone},
+		{"foreign", http.StatusForbidden},
+		{"unknown", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		body, _ := json.Marshal(booking.AcceptTransferRequest{Token: c.token})
+		req := httptest.NewRequest(http.MethodPost, "/bookings/transfers/accept", bytes.NewReader(body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), 3))
+		w := httptest.NewRecorder()
+
+		h.AcceptTransfer(w, req)
+
+		if w.Code != c.want {
+			t.Fatalf("token %q: expected status %d, got %d", c.token, c.want, w.Code)
+		}
+		if w.Code == http.StatusOK {
+			var b booking.Booking
+			if err := json.NewDecoder(w.Body).Decode(&b); err != nil || b.UserID != 3 {
+				t.Fatalf("unexpected body %+v, %v", b, err)
+			}
+		}
+	}
+}
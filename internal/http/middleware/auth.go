@@ -2,35 +2,27 @@
 package middleware
 
 import (
-	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
-	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/http/handlers"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
 	"laschool.ru/event-booking-service/internal/jwtutil"
-	"laschool.ru/event-booking-service/pkg/container"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/session"
 )
 
-type contextKey string
-
-const UserIDKey contextKey = "userID"
-
-// NewAuthMiddleware достаёт cfg из контейнера ОДИН РАЗ и возвращает middleware.
-func NewAuthMiddleware() (func(http.Handler) http.Handler, error) {
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	cfg := ctn.Get(config.DIConfig).(*config.Config)
-	secret := cfg.JWT.Secret
-
+// NewAuthMiddleware возвращает middleware, проверяющий JWT, подписанный secret,
+// и отклоняющий токены, отозванные в sessions (nil — отзыв не проверяется).
+func NewAuthMiddleware(secret string, sessions *session.Store) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				http.Error(w, "missing or invalid Authorization header", http.StatusUnauthorized)
+				handlers.WriteError(w, http.StatusUnauthorized, "missing or invalid Authorization header")
 				return
 			}
 
@@ -39,15 +31,42 @@ func NewAuthMiddleware() (func(http.Handler) http.Handler, error) {
 			claims, err := jwtutil.ValidateJWT(tokenStr, secret)
 			if err != nil {
 				if strings.Contains(err.Error(), "expired") || errors.Is(err, jwt.ErrTokenExpired) {
-					http.Error(w, "token expired", http.StatusForbidden)
+					handlers.WriteError(w, http.StatusForbidden, "token expired")
 				} else {
-					http.Error(w, "invalid token", http.StatusUnauthorized)
+					handlers.WriteError(w, http.StatusUnauthorized, "invalid token")
 				}
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			if claims.IssuedAt != nil {
+				revoked, err := sessions.Revoked(r.Context(), claims.UserID, claims.IssuedAt.Time)
+				if err != nil {
+					// недоступность Redis не должна разлогинивать всех пользователей
+					logger.FromContext(r.Context()).Warn("session check failed", "error", err)
+				}
+				if revoked {
+					handlers.WriteError(w, http.StatusUnauthorized, "token revoked")
+					return
+				}
+			}
+
+			logger.With(r.Context(), "user_id", claims.UserID)
+			ctx := reqctx.WithRole(reqctx.WithUserID(r.Context(), claims.UserID), claims.Role)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
-	}, nil
+	}
+}
+
+// RequireRole пропускает только пользователей с одной из ролей roles.
+// Ставится после NewAuthMiddleware, который кладёт роль в контекст.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(roles, reqctx.Role(r.Context())) {
+				handlers.WriteError(w, http.StatusForbidden, "forbidden")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/jwtutil"
+	"laschool.ru/event-booking-service/internal/session"
+)
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	sessions := session.New(rdb, time.Hour)
+
+	var userID int64
+	h := NewAuthMiddleware("secret", sessions)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = reqctx.UserID(r.Context())
+	}))
+	token, err := jwtutil.GenerateJWT(7, "user", "secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do(); code != http.StatusOK || userID != 7 {
+		t.Fatalf("expected 200 for user 7, got %d (user %d)", code, userID)
+	}
+	time.Sleep(2 * time.Millisecond) // iat хранится с точностью до миллисекунды
+	if err := sessions.RevokeAll(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	if code := do(); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for revoked token, got %d", code)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	h := NewAuthMiddleware("secret", nil)(RequireRole("staff", "admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	for role, want := range map[string]int{"staff": http.StatusOK, "admin": http.StatusOK, "user": http.StatusForbidden, "": http.StatusForbidden} {
+		token, err := jwtutil.GenerateJWT(7, role, "secret", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("role %q: expected %d, got %d", role, want, w.Code)
+		}
+	}
+}
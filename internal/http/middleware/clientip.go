@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+// NewClientIPMiddleware кладёт в контекст IP клиента. X-Forwarded-For учитывается только при
+// trustedHops > 0 — столько доверенных прокси стоит перед сервисом; при 0 берётся адрес соединения.
+func NewClientIPMiddleware(trustedHops int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := reqctx.WithClientIP(r.Context(), clientIP(r, trustedHops))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP берёт адрес, который дописал в X-Forwarded-For самый внешний из trustedHops прокси.
+// Прокси дописывают заголовок, а не заменяют его, поэтому всё левее — то, что прислал клиент,
+// и верить этому нельзя.
+func clientIP(r *http.Request, trustedHops int) string {
+	if trustedHops > 0 {
+		var hops []string
+		for _, v := range r.Header.Values("X-Forwarded-For") {
+			for _, ip := range strings.Split(v, ",") {
+				if ip = strings.TrimSpace(ip); ip != "" {
+					hops = append(hops, ip)
+				}
+			}
+		}
+		if len(hops) > 0 {
+			return hops[max(len(hops)-trustedHops, 0)]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -1,9 +1,14 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/logger"
 )
 
 type loggingResponseWriter struct {
@@ -16,13 +21,42 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
-func LoggingMiddleware(next http.Handler) http.Handler {
+// NewLoggingMiddleware кладёт в контекст логгер запроса (method, path, request_id)
+// и по завершении пишет строку access-лога со статусом и длительностью.
+func NewLoggingMiddleware(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			l := base.With("method", r.Method, "path", r.URL.Path)
+			if id := reqctx.RequestID(r.Context()); id != "" {
+				l = l.With("request_id", id)
+			}
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				l = l.With("trace_id", sc.TraceID().String())
+			}
+			ctx := logger.WithContext(r.Context(), l)
+
+			lrw := &loggingResponseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+			next.ServeHTTP(lrw, r.WithContext(ctx))
+
+			logger.FromContext(ctx).Info("request completed",
+				"status", lrw.statusCode,
+				"duration", time.Since(start),
+			)
+		})
+	}
+}
+
+// WithRoute добавляет шаблон маршрута в логгер запроса и в HTTP-спан.
+func WithRoute(route string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lrw := &loggingResponseWriter{
-			ResponseWriter: w,
-		}
-		next.ServeHTTP(lrw, r)
-		log.Printf("Method: %s, Path: %s, Status: %d, Duration: %s", r.Method, r.URL.Path, lrw.statusCode, time.Since(start))
+		logger.With(r.Context(), "route", route)
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/metrics"
+)
+
+// Instrument считает запросы и их длительность для маршрута route.
+// Метка route — шаблон из роутера, а не сырой путь, чтобы не раздувать кардинальность.
+func Instrument(m *metrics.Metrics, route string, next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lrw := &loggingResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+		next.ServeHTTP(lrw, r)
+		m.ObserveHTTP(r.Method, route, lrw.statusCode, time.Since(start))
+	})
+}
@@ -1,16 +1,17 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
 	"runtime/debug"
+
+	"laschool.ru/event-booking-service/internal/logger"
 )
 
 func PanicMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic recovered: %v\n%s", err, debug.Stack())
+				logger.FromContext(r.Context()).Error("panic recovered", "panic", err, "stack", string(debug.Stack()))
 				w.WriteHeader(http.StatusInternalServerError)
 				w.Write([]byte("500 Internal Server Error"))
 			}
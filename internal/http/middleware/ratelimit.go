@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/http/handlers"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+	"laschool.ru/event-booking-service/internal/jwtutil"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/ratelimit"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// NewRateLimitMiddleware возвращает обёртку для маршрута route. Если для маршрута и метода
+// запроса есть правило в limiter, запрос учитывается в Redis, а ответ получает заголовки RateLimit-*.
+// jwtSecret нужен для ключа "user": лимитер стоит до auth и сам достаёт user_id из токена.
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter, jwtSecret string) func(route string, next http.Handler) http.Handler {
+	return func(route string, next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, ok := limiter.Rule(r.Method, route)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := rateLimitKey(r, limiter, rule.Key, jwtSecret)
+			res, err := limiter.Allow(r.Context(), rule, key)
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("rate limiter unavailable", "route", route, "fail_open", limiter.FailOpen(), "error", err)
+				if limiter.FailOpen() {
+					next.ServeHTTP(w, r)
+					return
+				}
+				handlers.WriteError(w, http.StatusServiceUnavailable, "rate limiter unavailable")
+				return
+			}
+
+			resetSeconds := strconv.Itoa(ceilSeconds(res.Reset))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", resetSeconds)
+			w.Header().Set("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+strconv.Itoa(ceilSeconds(rule.Window)))
+
+			if !res.Allowed {
+				logger.FromContext(r.Context()).Info("rate limit exceeded", "route", route, "key_type", rule.Key)
+				w.Header().Set("Retry-After", resetSeconds)
+				handlers.WriteError(w, http.StatusTooManyRequests, "too many requests")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey выбирает, по чему считать запросы. Если нужного признака нет
+// (анонимный запрос, нет API-ключа или ключ не выдан), считаем по IP — иначе
+// случайный X-API-Key на каждый запрос давал бы новый счётчик.
+func rateLimitKey(r *http.Request, limiter *ratelimit.Limiter, kind, jwtSecret string) string {
+	switch kind {
+	case ratelimit.KeyUser:
+		if tokenStr, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if claims, err := jwtutil.ValidateJWT(tokenStr, jwtSecret); err == nil {
+				return "user:" + strconv.FormatInt(claims.UserID, 10)
+			}
+		}
+	case ratelimit.KeyAPIKey:
+		if apiKey := r.Header.Get(APIKeyHeader); limiter.KnownAPIKey(apiKey) {
+			// сам ключ в Redis не храним
+			sum := sha256.Sum256([]byte(apiKey))
+			return "api_key:" + hex.EncodeToString(sum[:8])
+		}
+	}
+	ip := reqctx.ClientIP(r.Context())
+	if ip == "" {
+		ip = clientIP(r, 0)
+	}
+	return "ip:" + ip
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/ratelimit"
+)
+
+func newRateLimited(t *testing.T, cfg config.RateLimit) (http.Handler, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+
+	limit := NewRateLimitMiddleware(ratelimit.New(rdb, cfg), "secret")
+	return limit("/users/login", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})), mr
+}
+
+func loginRule() config.RateLimitRule {
+	return config.RateLimitRule{Route: "/users/login", Method: http.MethodPost, Limit: 1, Window: time.Minute, Key: ratelimit.KeyIP}
+}
+
+func TestRateLimit_RejectsOverLimit(t *testing.T) {
+	h, _ := newRateLimited(t, config.RateLimit{Enabled: true, Rules: []config.RateLimitRule{loginRule()}})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/login", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected rate limit headers: %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/login", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header")
+	}
+}
+
+func TestRateLimit_RedisDown(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		failOpen bool
+		want     int
+	}{
+		{"fail open", true, http.StatusOK},
+		{"fail closed", false, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, mr := newRateLimited(t, config.RateLimit{Enabled: true, FailOpen: tc.failOpen, Rules: []config.RateLimitRule{loginRule()}})
+			mr.Close()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/login", nil))
+			if w.Code != tc.want {
+				t.Fatalf("expected status %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/bookings", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	// клиент подставил 198.51.100.1, прокси дописал настоящий адрес 203.0.113.7
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+
+	if got := rateLimitKey(req, nil, ratelimit.KeyIP, "secret"); got != "ip:10.0.0.1" {
+		t.Fatalf("unexpected key %q", got)
+	}
+	// IP из NewClientIPMiddleware за доверенным прокси
+	var proxied *http.Request
+	NewClientIPMiddleware(1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	if got := rateLimitKey(proxied, nil, ratelimit.KeyIP, "secret"); got != "ip:203.0.113.7" {
+		t.Fatalf("unexpected key %q", got)
+	}
+	// без токена ключ "user" откатывается на IP
+	if got := rateLimitKey(req, nil, ratelimit.KeyUser, "secret"); got != "ip:10.0.0.1" {
+		t.Fatalf("unexpected key %q", got)
+	}
+}
+
+func TestRateLimit_UnknownAPIKeys(t *testing.T) {
+	rule := loginRule()
+	rule.Key = ratelimit.KeyAPIKey
+	h, _ := newRateLimited(t, config.RateLimit{Enabled: true, Rules: []config.RateLimitRule{rule}, APIKeys: []string{"partner-key"}})
+
+	send := func(apiKey string) int {
+		req := httptest.NewRequest(http.MethodPost, "/users/login", nil)
+		req.Header.Set(APIKeyHeader, apiKey)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	// случайные ключи не дают новый счётчик: все они считаются по IP
+	if code := send("random-1"); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if code := send("random-2"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 for another unknown key, got %d", code)
+	}
+	// выданный ключ считается отдельно
+	if code := send("partner-key"); code != http.StatusOK {
+		t.Fatalf("expected status 200 for known key, got %d", code)
+	}
+	if code := send("partner-key"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429 for known key, got %d", code)
+	}
+}
+
+func TestClientIP_ForwardedFor(t *testing.T) {
+	cases := []struct {
+		hops int
+		xff  []string
+		want string
+	}{
+		{0, []string{"203.0.113.7"}, "10.0.0.1"},
+		{1, nil, "10.0.0.1"},
+		{1, []string{"203.0.113.7"}, "203.0.113.7"},
+		// подделанные клиентом адреса левее дописанного прокси не учитываются
+		{1, []string{"1.1.1.1, 2.2.2.2, 203.0.113.7"}, "203.0.113.7"},
+		{1, []string{"1.1.1.1", "203.0.113.7"}, "203.0.113.7"},
+		// CDN дописал клиента, балансировщик — адрес CDN
+		{2, []string{"1.1.1.1, 203.0.113.7, 192.0.2.10"}, "203.0.113.7"},
+		{2, []string{"203.0.113.7"}, "203.0.113.7"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for _, v := range c.xff {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		if got := clientIP(req, c.hops); got != c.want {
+			t.Fatalf("hops %d, xff %v: expected %s, got %s", c.hops, c.xff, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/http/handlers"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+func TestRequestIDMiddleware_Generates(t *testing.T) {
+	var fromCtx string
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromCtx = reqctx.RequestID(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	if fromCtx == "" {
+		t.Fatal("expected generated request id in context")
+	}
+	if got := w.Header().Get(handlers.RequestIDHeader); got != fromCtx {
+		t.Fatalf("expected response header %q, got %q", fromCtx, got)
+	}
+}
+
+func TestRequestIDMiddleware_AcceptsIncoming(t *testing.T) {
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.WriteError(w, http.StatusBadRequest, "boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(handlers.RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get(handlers.RequestIDHeader); got != "abc-123" {
+		t.Fatalf("expected echoed request id, got %q", got)
+	}
+	var resp handlers.ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.RequestID != "abc-123" {
+		t.Fatalf("expected request id in error body, got %q", resp.RequestID)
+	}
+}
+
+func TestRequestIDMiddleware_RejectsInvalid(t *testing.T) {
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(handlers.RequestIDHeader, "bad id\nwith newline")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if got := w.Header().Get(handlers.RequestIDHeader); got == "" || got == "bad id\nwith newline" {
+		t.Fatalf("expected a freshly generated request id, got %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+// Package reqctx хранит данные запроса, которые кладут middleware и читают хендлеры:
+// ID и роль пользователя, ID запроса, IP клиента.
+package reqctx
+
+import "context"
+
+type contextKey string
+
+const (
+	userIDKey    contextKey = "userID"
+	roleKey      contextKey = "role"
+	requestIDKey contextKey = "requestID"
+	clientIPKey  contextKey = "clientIP"
+)
+
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserID возвращает ID аутентифицированного пользователя.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey).(int64)
+	return id, ok
+}
+
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// Role возвращает роль аутентифицированного пользователя или пустую строку.
+func Role(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey).(string)
+	return role
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID возвращает ID запроса или пустую строку.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIP возвращает IP клиента или пустую строку.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
@@ -5,89 +5,291 @@ import (
 	"strings"
 
 	httpSwagger "github.com/swaggo/http-swagger"
+	"laschool.ru/event-booking-service/internal/bulkimport"
 	"laschool.ru/event-booking-service/internal/http/handlers"
 	"laschool.ru/event-booking-service/internal/http/middleware"
+	"laschool.ru/event-booking-service/internal/metrics"
+	"laschool.ru/event-booking-service/internal/ratelimit"
+	"laschool.ru/event-booking-service/internal/session"
 	"laschool.ru/event-booking-service/internal/user"
 )
 
-func NewRouter() *http.ServeMux {
+// Handlers — зависимости роутера, собираемые один раз при старте.
+type Handlers struct {
+	Events      *handlers.EventHandler
+	Bookings    *handlers.BookingHandler
+	Users       *user.Handler
+	Import      *bulkimport.Handler
+	Payments    *handlers.PaymentHandler
+	Promos      *handlers.PromoHandler
+	Health      *handlers.HealthHandler
+	Metrics     *metrics.Metrics
+	RateLimiter *ratelimit.Limiter
+	Sessions    *session.Store
+	JWTSecret   string
+}
+
+func NewRouter(h Handlers) *http.ServeMux {
 	mux := http.NewServeMux()
-	auth, err := middleware.NewAuthMiddleware()
-	if err != nil {
-		panic("failed to init auth middleware: " + err.Error())
+	auth := middleware.NewAuthMiddleware(h.JWTSecret, h.Sessions)
+	// staff — сотрудники на входе и администраторы
+	staff := func(next http.Handler) http.Handler {
+		return auth(middleware.RequireRole(user.RoleStaff, user.RoleAdmin)(next))
+	}
+	limit := middleware.NewRateLimitMiddleware(h.RateLimiter, h.JWTSecret)
+	// handle регистрирует маршрут, помечает его шаблоном логгер запроса, считает метрики
+	// и применяет лимиты из конфига rate_limit
+	handle := func(pattern string, fn http.HandlerFunc) {
+		mux.Handle(pattern, middleware.WithRoute(pattern, middleware.Instrument(h.Metrics, pattern, limit(pattern, fn))))
 	}
 
-	mux.HandleFunc("/ping", handlers.PingHandler)
-	mux.HandleFunc("/health", handlers.HealthHandler)
+	handle("/ping", handlers.PingHandler)
+	handle("/livez", h.Health.Livez)
+	handle("/readyz", h.Health.Readyz)
+	// /health оставлен для совместимости со старыми проверками
+	handle("/health", h.Health.Readyz)
+	if h.Metrics != nil {
+		mux.Handle("/metrics", h.Metrics.Handler())
+	}
 
 	// Swagger UI
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
-	mux.HandleFunc("/swagger", func(w http.ResponseWriter, r *http.Request) {
+	handle("/swagger", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/swagger/index.html", http.StatusMovedPermanently)
 	})
 
 	// Event CRUD
-	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+	handle("/events", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			handlers.ListEvents(w, r)
+			h.Events.ListEvents(w, r)
 		case http.MethodPost:
-			auth(http.HandlerFunc(handlers.CreateEvent)).ServeHTTP(w, r)
+			auth(http.HandlerFunc(h.Events.CreateEvent)).ServeHTTP(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
-	mux.HandleFunc("/events/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/events/", func(w http.ResponseWriter, r *http.Request) {
 		// подпуть /events/{id}/bookings
 		if strings.HasSuffix(r.URL.Path, "/bookings") && r.Method == http.MethodGet {
-			handlers.ListBookingsByEvent(w, r)
+			h.Bookings.ListBookingsByEvent(w, r)
+			return
+		}
+		// подпути /events/{id}/attendees.csv и .xlsx
+		if strings.HasSuffix(r.URL.Path, "/attendees.csv") {
+			auth(http.HandlerFunc(h.Bookings.ExportAttendeesCSV)).ServeHTTP(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/attendees.xlsx") {
+			auth(http.HandlerFunc(h.Bookings.ExportAttendeesXLSX)).ServeHTTP(w, r)
+			return
+		}
+		// подпуть /events/{id}/refunds
+		if strings.HasSuffix(r.URL.Path, "/refunds") {
+			auth(http.HandlerFunc(h.Bookings.ListEventRefunds)).ServeHTTP(w, r)
+			return
+		}
+		// подпуть /events/{id}/checkin
+		if strings.HasSuffix(r.URL.Path, "/checkin") {
+			staff(http.HandlerFunc(h.Bookings.CheckIn)).ServeHTTP(w, r)
 			return
 		}
 		switch r.Method {
 		case http.MethodGet:
-			handlers.GetEvent(w, r)
+			h.Events.GetEvent(w, r)
 		case http.MethodPut:
-			auth(http.HandlerFunc(handlers.UpdateEvent)).ServeHTTP(w, r)
+			auth(http.HandlerFunc(h.Events.UpdateEvent)).ServeHTTP(w, r)
 		case http.MethodDelete:
-			auth(http.HandlerFunc(handlers.DeleteEvent)).ServeHTTP(w, r)
+			auth(http.HandlerFunc(h.Events.DeleteEvent)).ServeHTTP(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
 
 	// Booking endpoints
-	mux.HandleFunc("/bookings", func(w http.ResponseWriter, r *http.Request) {
+	handle("/bookings", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			auth(http.HandlerFunc(h.Bookings.CreateBooking)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/bookings/transfers/accept", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			auth(http.HandlerFunc(h.Bookings.AcceptTransfer)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/bookings/", func(w http.ResponseWriter, r *http.Request) {
+		// подпуть /bookings/{id}/transfer
+		if strings.HasSuffix(r.URL.Path, "/transfer") {
+			auth(http.HandlerFunc(h.Bookings.TransferBooking)).ServeHTTP(w, r)
+			return
+		}
+		// подпуть /bookings/{id}/attendees/{attendeeID}/ticket.png
+		if strings.Contains(r.URL.Path, "/attendees/") {
+			auth(http.HandlerFunc(h.Bookings.GetAttendeeTicket)).ServeHTTP(w, r)
+			return
+		}
+		// подпуть /bookings/{id}/attendees
+		if strings.HasSuffix(r.URL.Path, "/attendees") {
+			switch r.Method {
+			case http.MethodGet:
+				auth(http.HandlerFunc(h.Bookings.ListAttendees)).ServeHTTP(w, r)
+			case http.MethodPut:
+				auth(http.HandlerFunc(h.Bookings.UpdateAttendees)).ServeHTTP(w, r)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			// подпуть /bookings/{id}/ticket.png
+			if strings.HasSuffix(r.URL.Path, "/ticket.png") {
+				auth(http.HandlerFunc(h.Bookings.GetTicket)).ServeHTTP(w, r)
+				return
+			}
+			h.Bookings.GetBooking(w, r)
+		case http.MethodPatch:
+			auth(http.HandlerFunc(h.Bookings.UpdateBooking)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			auth(http.HandlerFunc(h.Bookings.CancelBooking)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	// уведомления провайдера платежей подписаны его ключом, токена у них нет
+	handle("/webhooks/payments", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.Payments.PaymentWebhook(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Admin endpoints
+	admin := func(next http.Handler) http.Handler {
+		return auth(middleware.RequireRole(user.RoleAdmin)(next))
+	}
+	handle("/admin/bookings/import", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			admin(http.HandlerFunc(h.Import.ImportBookings)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	handle("/admin/promo-codes", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
+		case http.MethodGet:
+			admin(http.HandlerFunc(h.Promos.ListPromoCodes)).ServeHTTP(w, r)
 		case http.MethodPost:
-			auth(http.HandlerFunc(handlers.CreateBooking)).ServeHTTP(w, r)
+			admin(http.HandlerFunc(h.Promos.CreatePromoCode)).ServeHTTP(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
-	mux.HandleFunc("/bookings/", func(w http.ResponseWriter, r *http.Request) {
+	handle("/admin/promo-codes/", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			handlers.GetBooking(w, r)
+			admin(http.HandlerFunc(h.Promos.GetPromoCode)).ServeHTTP(w, r)
+		case http.MethodPut:
+			admin(http.HandlerFunc(h.Promos.UpdatePromoCode)).ServeHTTP(w, r)
 		case http.MethodDelete:
-			auth(http.HandlerFunc(handlers.CancelBooking)).ServeHTTP(w, r)
+			admin(http.HandlerFunc(h.Promos.DeletePromoCode)).ServeHTTP(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
 
 	// User endpoints
-	mux.HandleFunc("/users/register", func(w http.ResponseWriter, r *http.Request) {
+	handle("/users/register", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.Users.RegisterHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/login", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.Users.LoginHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/verify", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.Users.VerifyEmailHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/me", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auth(http.HandlerFunc(h.Users.GetMeHandler)).ServeHTTP(w, r)
+		case http.MethodPatch:
+			auth(http.HandlerFunc(h.Users.UpdateMeHandler)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			auth(http.HandlerFunc(h.Users.DeleteMeHandler)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/me/bookings", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auth(http.HandlerFunc(h.Bookings.ListMyBookings)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/me/attendees", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auth(http.HandlerFunc(h.Bookings.ListMyAttendees)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/me/attendees/claim", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			auth(http.HandlerFunc(h.Bookings.ClaimAttendees)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/me/password", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			auth(http.HandlerFunc(h.Users.ChangePasswordHandler)).ServeHTTP(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	handle("/users/password/forgot", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			user.RegisterHandler(w, r)
+			h.Users.ForgotPasswordHandler(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
-	mux.HandleFunc("/users/login", func(w http.ResponseWriter, r *http.Request) {
+	handle("/users/password/reset", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			user.LoginHandler(w, r)
+			h.Users.ResetPasswordHandler(w, r)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
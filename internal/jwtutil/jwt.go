@@ -8,14 +8,23 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+func init() {
+	// iat с миллисекундами: токен, выданный сразу после отзыва сессий (смена пароля),
+	// должен отличаться по времени от отозванных
+	jwt.TimePrecision = time.Millisecond
+}
+
 type Claims struct {
 	UserID int64 `json:"user_id"`
+	// Role — роль пользователя на момент выдачи токена
+	Role string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID int64, secret string, ttl time.Duration) (string, error) {
+func GenerateJWT(userID int64, role, secret string, ttl time.Duration) (string, error) {
 	claims := Claims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
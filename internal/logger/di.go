@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"log/slog"
+
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DILogger = "logger"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DILogger,
+			Build: func(ctn container.Container) (interface{}, error) {
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				l := New(cfg.Log)
+				slog.SetDefault(l)
+				return l, nil
+			},
+		})
+	})
+}
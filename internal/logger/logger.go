@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// New создаёт логгер по настройкам из config.Log.
+// Формат "text" — человекочитаемый вывод для локальной разработки, всё остальное — JSON.
+func New(cfg config.Log) *slog.Logger {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg config.Log) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(cfg.Level)}
+	if strings.EqualFold(cfg.Format, "text") {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type ctxKey struct{}
+
+// scope хранит логгер запроса. Он изменяемый, чтобы атрибуты, добавленные
+// глубже по цепочке middleware (например, user_id), попали и в итоговую строку access-лога.
+type scope struct {
+	mu     sync.RWMutex
+	logger *slog.Logger
+}
+
+// WithContext кладёт логгер в контекст.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &scope{logger: l})
+}
+
+// FromContext возвращает логгер из контекста или slog.Default(), если его там нет.
+func FromContext(ctx context.Context) *slog.Logger {
+	if s, ok := ctx.Value(ctxKey{}).(*scope); ok {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.logger
+	}
+	return slog.Default()
+}
+
+// With добавляет атрибуты к логгеру, лежащему в контексте.
+// Если логгера в контексте нет, вызов ничего не делает.
+func With(ctx context.Context, args ...any) {
+	if s, ok := ctx.Value(ctxKey{}).(*scope); ok {
+		s.mu.Lock()
+		s.logger = s.logger.With(args...)
+		s.mu.Unlock()
+	}
+}
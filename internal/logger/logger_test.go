@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+func TestWith_EnrichesContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithContext(context.Background(), newLogger(&buf, config.Log{Level: "info"}))
+
+	With(ctx, "user_id", int64(7))
+	FromContext(ctx).Info("hello")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected json output, got %q: %v", buf.String(), err)
+	}
+	if entry["user_id"] != float64(7) {
+		t.Fatalf("expected user_id=7, got %v", entry["user_id"])
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, config.Log{Level: "warn", Format: "text"})
+
+	l.Info("skipped")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be filtered, got %q", buf.String())
+	}
+	l.Warn("kept")
+	if buf.Len() == 0 {
+		t.Fatal("expected warn to be written")
+	}
+}
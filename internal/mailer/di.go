@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIMailer = "mailer"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIMailer,
+			Build: func(ctn container.Container) (interface{}, error) {
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				return New(cfg.Mail)
+			},
+		})
+	})
+}
@@ -0,0 +1,60 @@
+// Package mailer отправляет письма пользователям. Реализация выбирается в конфиге:
+// smtp — настоящий сервер, file — письма складываются в каталог (локальная разработка),
+// memory — письма остаются в памяти (тесты).
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// Драйверы почты
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+// Message — простое текстовое письмо.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создаёт Mailer по драйверу из конфига; по умолчанию — memory.
+func New(cfg config.Mail) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SMTP, cfg.From), nil
+	case DriverFile:
+		return NewFileMailer(cfg.Dir, cfg.From)
+	case DriverMemory, "":
+		return NewMemoryMailer(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// encode собирает письмо в формате RFC 5322.
+func (m Message) encode(from string, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(m.Body)
+	return buf.Bytes()
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(config.Mail{Driver: DriverFile, Dir: dir, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := Message{To: "alice@example.com", Subject: "Подтвердите email", Body: "ссылка"}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: noreply@example.com", "To: alice@example.com", "=?utf-8?q?", "\r\n\r\nссылка"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("mail does not contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestMemoryMailer(t *testing.T) {
+	m, err := New(config.Mail{Driver: DriverMemory})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mem := m.(*MemoryMailer)
+	mem.Send(context.Background(), Message{To: "a@example.com"})
+	mem.Send(context.Background(), Message{To: "b@example.com"})
+	if got := mem.Messages(); len(got) != 2 || got[1].To != "b@example.com" {
+		t.Fatalf("unexpected messages %+v", got)
+	}
+
+	if _, err := New(config.Mail{Driver: "pigeon"}); err == nil {
+		t.Fatal("expected error for unknown driver")
+	}
+}
@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIMetrics = "metrics"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIMetrics,
+			Build: func(ctn container.Container) (interface{}, error) {
+				return New(), nil
+			},
+		})
+	})
+}
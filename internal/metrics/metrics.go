@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "booking_service"
+
+// Исходы бронирований
+const (
+	BookingCreated          = "created"
+	BookingRejectedCapacity = "rejected_capacity"
+	BookingRejectedLimit    = "rejected_limit"
+	BookingRejectedPromo    = "rejected_promo"
+	BookingCancelled        = "cancelled"
+	BookingExpired          = "expired"
+)
+
+// Результаты обращений к кэшу
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheLockWait    = "lock_wait"
+	CacheLockTimeout = "lock_timeout"
+)
+
+// Metrics — набор метрик сервиса со своим реестром.
+// Все методы безопасно вызывать на nil: так сервисы работают в тестах без метрик.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	bookings     *prometheus.CounterVec
+	cache        *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Количество HTTP-запросов по маршруту и статусу.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Длительность обработки HTTP-запросов.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		bookings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bookings_total",
+			Help:      "Исходы операций с бронированиями.",
+		}, []string{"outcome"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_operations_total",
+			Help:      "Попадания, промахи и ожидания блокировки в кэше.",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.bookings,
+		m.cache,
+	)
+	return m
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) BookingOutcome(outcome string) {
+	if m == nil {
+		return
+	}
+	m.bookings.WithLabelValues(outcome).Inc()
+}
+
+func (m *Metrics) CacheResult(result string) {
+	if m == nil {
+		return
+	}
+	m.cache.WithLabelValues(result).Inc()
+}
+
+// RegisterDBStats экспортирует статистику пула соединений БД (sql.DB.Stats).
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedisStats экспортирует статистику пула соединений Redis (redis.Client.PoolStats).
+func (m *Metrics) RegisterRedisStats(client *redis.Client) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(newRedisPoolCollector(client))
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Counters(t *testing.T) {
+	m := New()
+	m.ObserveHTTP("POST", "/bookings", 201, 10*time.Millisecond)
+	m.BookingOutcome(BookingCreated)
+	m.BookingOutcome(BookingRejectedCapacity)
+	m.CacheResult(CacheHit)
+
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("POST", "/bookings", "201")); got != 1 {
+		t.Fatalf("expected 1 http request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.bookings.WithLabelValues(BookingRejectedCapacity)); got != 1 {
+		t.Fatalf("expected 1 rejected booking, got %v", got)
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	if !strings.Contains(string(body), `booking_service_cache_operations_total{result="hit"} 1`) {
+		t.Fatalf("expected cache hit counter in output, got:\n%s", body)
+	}
+}
+
+func TestMetrics_NilSafe(t *testing.T) {
+	var m *Metrics
+	m.ObserveHTTP("GET", "/ping", 200, time.Millisecond)
+	m.BookingOutcome(BookingCreated)
+	m.CacheResult(CacheMiss)
+	if err := m.RegisterDBStats(nil, "db"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package payment
+
+import (
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIProvider = "payment-provider"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIProvider,
+			Build: func(ctn container.Container) (interface{}, error) {
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				return New(cfg.Payment)
+			},
+		})
+	})
+}
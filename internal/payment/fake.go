@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeSignatureHeader — заголовок подписи уведомлений Fake
+const FakeSignatureHeader = "Payment-Signature"
+
+// Fake — провайдер в памяти: платежи не уходят в сеть, уведомления подписываются тем же
+// способом, что и у Stripe. Подходит для тестов и локальной разработки.
+type Fake struct {
+	secret string
+
+	mu       sync.Mutex
+	intents  map[string]*fakeIntent
+	byKey    map[string]string
+	refunds  []Refund
+	captured map[string]bool
+	// cancelled — платежи, отменённые через Cancel
+	cancelled map[string]bool
+	// refundFailures — сколько следующих возвратов завершится ошибкой refundErr
+	refundFailures int
+	refundErr      error
+}
+
+type fakeIntent struct {
+	Intent
+	refunded int64
+}
+
+// fakeEvent — тело уведомления Fake
+type fakeEvent struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+	Amount   int64  `json:"amount"`
+}
+
+func NewFake(webhookSecret string) *Fake {
+	return &Fake{
+		secret:    webhookSecret,
+		intents:   make(map[string]*fakeIntent),
+		byKey:     make(map[string]string),
+		captured:  make(map[string]bool),
+		cancelled: make(map[string]bool),
+	}
+}
+
+func (f *Fake) Name() string { return DriverFake }
+
+func (f *Fake) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id, ok := f.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		in := f.intents[id].Intent
+		return &in, nil
+	}
+	id := "pi_fake_" + randomID()
+	in := &fakeIntent{Intent: Intent{ID: id, ClientSecret: id + "_secret_" + randomID(), Amount: req.Amount, Currency: req.Currency}}
+	f.intents[id] = in
+	if req.IdempotencyKey != "" {
+		f.byKey[req.IdempotencyKey] = id
+	}
+	out := in.Intent
+	return &out, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, intentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.intents[intentID]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownIntent, intentID)
+	}
+	if f.cancelled[intentID] {
+		return fmt.Errorf("%w: %s", ErrIntentCancelled, intentID)
+	}
+	f.captured[intentID] = true
+	return nil
+}
+
+func (f *Fake) Cancel(ctx context.Context, intentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.intents[intentID]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownIntent, intentID)
+	}
+	if f.captured[intentID] {
+		return fmt.Errorf("%w: %s", ErrIntentCaptured, intentID)
+	}
+	f.cancelled[intentID] = true
+	return nil
+}
+
+func (f *Fake) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.refundFailures > 0 {
+		f.refundFailures--
+		return nil, f.refundErr
+	}
+	if id, ok := f.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		for _, r := range f.refunds {
+			if r.ID == id {
+				return &r, nil
+			}
+		}
+	}
+	in, ok := f.intents[req.IntentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownIntent, req.IntentID)
+	}
+	if in.refunded+req.Amount > in.Amount {
+		return nil, fmt.Errorf("%w: %d of remaining %d", ErrRefundExceeded, req.Amount, in.Amount-in.refunded)
+	}
+	in.refunded += req.Amount
+	r := Refund{ID: "re_fake_" + randomID(), Amount: req.Amount}
+	f.refunds = append(f.refunds, r)
+	if req.IdempotencyKey != "" {
+		f.byKey[req.IdempotencyKey] = r.ID
+	}
+	return &r, nil
+}
+
+// FailRefunds заставляет следующие n возвратов завершиться ошибкой err — так проверяются повторы.
+func (f *Fake) FailRefunds(n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refundFailures, f.refundErr = n, err
+}
+
+func (f *Fake) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	if err := verifySignature(f.secret, header.Get(FakeSignatureHeader), payload, time.Now()); err != nil {
+		return nil, err
+	}
+	var e fakeEvent
+	if err := json.Unmarshal(payload, &e); err != nil || e.IntentID == "" {
+		return nil, ErrInvalidWebhook
+	}
+	return &WebhookEvent{ID: e.ID, Type: e.Type, IntentID: e.IntentID, Amount: e.Amount}, nil
+}
+
+// Webhook собирает подписанное уведомление типа typ о платеже intentID — так провайдер сообщил бы об оплате.
+func (f *Fake) Webhook(typ, intentID string) ([]byte, http.Header) {
+	f.mu.Lock()
+	var amount int64
+	if in, ok := f.intents[intentID]; ok {
+		amount = in.Amount
+	}
+	f.mu.Unlock()
+	payload, _ := json.Marshal(fakeEvent{ID: "evt_fake_" + randomID(), Type: typ, IntentID: intentID, Amount: amount})
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, signPayload(f.secret, payload, time.Now()))
+	return payload, header
+}
+
+// Captured сообщает, списаны ли деньги по платежу.
+func (f *Fake) Captured(intentID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.captured[intentID]
+}
+
+// Cancelled сообщает, отменён ли платёж.
+func (f *Fake) Cancelled(intentID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cancelled[intentID]
+}
+
+// Refunds возвращает копию сделанных возвратов.
+func (f *Fake) Refunds() []Refund {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Refund(nil), f.refunds...)
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
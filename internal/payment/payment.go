@@ -0,0 +1,134 @@
+// Package payment принимает оплату броней через внешнего провайдера. Реализация выбирается в конфиге:
+// stripe — Stripe-совместимое API, fake — провайдер в памяти без сети (тесты и локальная разработка).
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// Драйверы платежей
+const (
+	DriverStripe = "stripe"
+	DriverFake   = "fake"
+)
+
+// Типы уведомлений, к которым провайдеры приводят свои собственные
+const (
+	// EventAuthorized — деньги заблокированы на карте и ждут списания через Capture
+	EventAuthorized = "payment.authorized"
+	// EventSucceeded — деньги списаны
+	EventSucceeded = "payment.succeeded"
+	// EventFailed — попытка оплаты не прошла; клиент может повторить её с тем же платежом
+	EventFailed = "payment.failed"
+	// EventCancelled — платёж отменён и больше не может быть оплачен
+	EventCancelled = "payment.cancelled"
+)
+
+var (
+	// ErrInvalidSignature — подпись уведомления не сходится или устарела.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidWebhook — тело уведомления не разбирается.
+	ErrInvalidWebhook = errors.New("invalid webhook payload")
+	// ErrUnknownIntent — у провайдера нет такого платежа.
+	ErrUnknownIntent = errors.New("unknown payment intent")
+	// ErrRefundExceeded — сумма возвратов больше оплаченной.
+	ErrRefundExceeded = errors.New("refund exceeds paid amount")
+	// ErrIntentCaptured — деньги уже списаны, отменить платёж нельзя, только вернуть.
+	ErrIntentCaptured = errors.New("payment intent already captured")
+	// ErrIntentCancelled — платёж отменён, списать его нельзя.
+	ErrIntentCancelled = errors.New("payment intent cancelled")
+)
+
+// Retryable сообщает, есть ли смысл повторить запрос: провайдер не ответил или ответил временной ошибкой.
+// Отказы по существу (нет платежа, неверная сумма) повтор не исправит.
+func Retryable(err error) bool {
+	if errors.Is(err, ErrUnknownIntent) || errors.Is(err, ErrRefundExceeded) ||
+		errors.Is(err, ErrIntentCaptured) || errors.Is(err, ErrIntentCancelled) {
+		return false
+	}
+	var se *StripeError
+	if errors.As(err, &se) {
+		return se.Temporary()
+	}
+	return true
+}
+
+// IntentRequest — запрос на создание платежа за бронь.
+type IntentRequest struct {
+	BookingID int64
+	// Amount — сумма в минимальных единицах валюты (копейках)
+	Amount   int64
+	Currency string
+	// IdempotencyKey — повтор запроса с тем же ключом не создаёт второй платёж
+	IdempotencyKey string
+}
+
+// Intent — платёж у провайдера.
+type Intent struct {
+	ID string
+	// ClientSecret передаётся клиенту, чтобы он ввёл данные карты на стороне провайдера
+	ClientSecret string
+	Amount       int64
+	Currency     string
+}
+
+// RefundRequest — запрос на возврат по платежу.
+type RefundRequest struct {
+	IntentID string
+	Amount   int64
+	// IdempotencyKey — повтор с тем же ключом не создаёт второй возврат
+	IdempotencyKey string
+}
+
+// Refund — возврат у провайдера.
+type Refund struct {
+	ID     string
+	Amount int64
+}
+
+// WebhookEvent — проверенное уведомление провайдера.
+type WebhookEvent struct {
+	// ID — идентификатор уведомления у провайдера
+	ID string
+	// Type — один из Event*; пусто — уведомление, которое сервису не нужно
+	Type     string
+	IntentID string
+	Amount   int64
+}
+
+type Provider interface {
+	// Name — имя провайдера, под которым сохраняются его платежи
+	Name() string
+	// CreateIntent создаёт платёж с отложенным списанием: деньги только блокируются до Capture
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture списывает заблокированные деньги
+	Capture(ctx context.Context, intentID string) error
+	// Cancel отменяет платёж без списания: блокировка на карте снимается, оплатить его больше нельзя
+	Cancel(ctx context.Context, intentID string) error
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+	// VerifyWebhook проверяет подпись уведомления по заголовкам запроса и разбирает его
+	VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+// New создаёт Provider по драйверу из конфига. Драйвер обязателен: пропущенная настройка
+// не должна молча включать fake, который подтверждает любую оплату.
+func New(cfg config.Payment) (Provider, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, errors.New("payment.driver is required (stripe or fake)")
+	case DriverStripe:
+		if cfg.Stripe.APIKey == "" {
+			return nil, errors.New("payment.stripe.api_key is required")
+		}
+		return NewStripe(cfg.Stripe, cfg.WebhookSecret), nil
+	case DriverFake:
+		return NewFake(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment driver %q", cfg.Driver)
+	}
+}
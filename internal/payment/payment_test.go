@@ -0,0 +1,190 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+	header := signPayload("secret", payload, now)
+
+	if err := verifySignature("secret", header, payload, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// подпись старым ключом рядом с новой тоже принимается
+	if err := verifySignature("secret", header+",v1=00ff", payload, now); err != nil {
+		t.Fatalf("unexpected error with extra signature: %v", err)
+	}
+	cases := map[string]struct {
+		secret, header string
+		payload        []byte
+		now            time.Time
+	}{
+		"wrong secret":     {"other", header, payload, now},
+		"tampered payload": {"secret", header, []byte(`{"id":"evt_2"}`), now},
+		"stale":            {"secret", header, payload, now.Add(10 * time.Minute)},
+		"malformed":        {"secret", "v1=abc", payload, now},
+		"no secret":        {"", header, payload, now},
+	}
+	for name, c := range cases {
+		if err := verifySignature(c.secret, c.header, c.payload, c.now); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+}
+
+func TestFake(t *testing.T) {
+	p, err := New(config.Payment{Driver: DriverFake, WebhookSecret: "whsec"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := p.(*Fake)
+	ctx := context.Background()
+
+	in, err := f.CreateIntent(ctx, IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub", IdempotencyKey: "booking-1"})
+	if err != nil || in.ID == "" || in.ClientSecret == "" {
+		t.Fatalf("unexpected intent %+v, err %v", in, err)
+	}
+	again, _ := f.CreateIntent(ctx, IntentRequest{BookingID: 1, Amount: 3000, Currency: "rub", IdempotencyKey: "booking-1"})
+	if again.ID != in.ID {
+		t.Fatalf("idempotent request created a new intent %s", again.ID)
+	}
+
+	payload, header := f.Webhook(EventAuthorized, in.ID)
+	e, err := f.VerifyWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if e.Type != EventAuthorized || e.IntentID != in.ID || e.Amount != 3000 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	header.Set(FakeSignatureHeader, "t=1,v1=00")
+	if _, err := f.VerifyWebhook(payload, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if err := f.Capture(ctx, in.ID); err != nil || !f.Captured(in.ID) {
+		t.Fatalf("capture failed: %v", err)
+	}
+	r, err := f.Refund(ctx, RefundRequest{IntentID: in.ID, Amount: 2000, IdempotencyKey: "refund-1"})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if again, err := f.Refund(ctx, RefundRequest{IntentID: in.ID, Amount: 2000, IdempotencyKey: "refund-1"}); err != nil || again.ID != r.ID {
+		t.Fatalf("idempotent refund returned %+v, %v", again, err)
+	}
+	if _, err := f.Refund(ctx, RefundRequest{IntentID: in.ID, Amount: 2000}); !errors.Is(err, ErrRefundExceeded) || Retryable(err) {
+		t.Fatalf("expected permanent ErrRefundExceeded, got %v", err)
+	}
+	f.FailRefunds(1, errors.New("connection reset"))
+	if _, err := f.Refund(ctx, RefundRequest{IntentID: in.ID, Amount: 500}); err == nil || !Retryable(err) {
+		t.Fatalf("expected injected retryable error, got %v", err)
+	}
+	if _, err := f.Refund(ctx, RefundRequest{IntentID: in.ID, Amount: 500}); err != nil {
+		t.Fatalf("refund after injected failure: %v", err)
+	}
+	if _, err := f.Refund(ctx, RefundRequest{IntentID: "pi_missing", Amount: 1}); !errors.Is(err, ErrUnknownIntent) {
+		t.Fatalf("expected ErrUnknownIntent, got %v", err)
+	}
+	if err := f.Cancel(ctx, in.ID); !errors.Is(err, ErrIntentCaptured) || Retryable(err) {
+		t.Fatalf("expected permanent ErrIntentCaptured, got %v", err)
+	}
+
+	// несписанный платёж отменяется, и списать его после этого нельзя
+	held, _ := f.CreateIntent(ctx, IntentRequest{BookingID: 2, Amount: 1000, Currency: "rub"})
+	if err := f.Cancel(ctx, held.ID); err != nil || !f.Cancelled(held.ID) {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if err := f.Capture(ctx, held.ID); !errors.Is(err, ErrIntentCancelled) || f.Captured(held.ID) {
+		t.Fatalf("expected ErrIntentCancelled, got %v", err)
+	}
+
+	if _, err := New(config.Payment{Driver: "barter"}); err == nil {
+		t.Fatal("expected error for unknown driver")
+	}
+	if _, err := New(config.Payment{}); err == nil {
+		t.Fatal("expected error for missing driver")
+	}
+}
+
+func TestStripe(t *testing.T) {
+	var got []*http.Request
+	var forms []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		got, forms = append(got, r), append(forms, form)
+		switch r.URL.Path {
+		case "/v1/payment_intents":
+			w.Write([]byte(`{"id":"pi_1","client_secret":"pi_1_secret","amount":3000,"currency":"rub"}`))
+		case "/v1/payment_intents/pi_1/capture", "/v1/payment_intents/pi_1/cancel":
+			w.Write([]byte(`{"id":"pi_1"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"type":"api_error","message":"try later"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	s := NewStripe(config.Stripe{APIKey: "sk_test", BaseURL: srv.URL}, "whsec")
+	ctx := context.Background()
+	in, err := s.CreateIntent(ctx, IntentRequest{BookingID: 7, Amount: 3000, Currency: "rub", IdempotencyKey: "booking-7"})
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	if in.ID != "pi_1" || in.ClientSecret != "pi_1_secret" {
+		t.Fatalf("unexpected intent %+v", in)
+	}
+	if got[0].Header.Get("Authorization") != "Bearer sk_test" || got[0].Header.Get("Idempotency-Key") != "booking-7" {
+		t.Fatalf("unexpected headers %v", got[0].Header)
+	}
+	if f := forms[0]; f.Get("capture_method") != "manual" || f.Get("metadata[booking_id]") != "7" || f.Get("amount") != "3000" {
+		t.Fatalf("unexpected form %v", f)
+	}
+	if err := s.Capture(ctx, "pi_1"); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if err := s.Cancel(ctx, "pi_1"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if last := got[len(got)-1]; last.URL.Path != "/v1/payment_intents/pi_1/cancel" || last.Header.Get("Idempotency-Key") != "cancel-pi_1" {
+		t.Fatalf("unexpected cancel request %s %v", last.URL.Path, last.Header)
+	}
+
+	_, err = s.Refund(ctx, RefundRequest{IntentID: "pi_1", Amount: 100})
+	var se *StripeError
+	if !errors.As(err, &se) || !se.Temporary() || se.Message != "try later" || !Retryable(err) {
+		t.Fatalf("expected temporary StripeError, got %v", err)
+	}
+	if Retryable(&StripeError{StatusCode: http.StatusBadRequest}) {
+		t.Fatal("client errors must not be retried")
+	}
+
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.amount_capturable_updated",
+		"data":{"object":{"id":"pi_1","amount":3000,"amount_capturable":3000}}}`)
+	header := http.Header{}
+	header.Set(StripeSignatureHeader, signPayload("whsec", payload, time.Now()))
+	e, err := s.VerifyWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if e.Type != EventAuthorized || e.IntentID != "pi_1" || e.Amount != 3000 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	other := []byte(`{"id":"evt_2","type":"charge.updated","data":{"object":{"id":"ch_1"}}}`)
+	header.Set(StripeSignatureHeader, signPayload("whsec", other, time.Now()))
+	if e, err := s.VerifyWebhook(other, header); err != nil || e.Type != "" {
+		t.Fatalf("expected ignored event, got %+v, %v", e, err)
+	}
+}
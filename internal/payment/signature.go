@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureTolerance — насколько старое уведомление ещё принимается; защищает от повтора перехваченных запросов
+const signatureTolerance = 5 * time.Minute
+
+// signPayload подписывает уведомление в формате Stripe: "t=<unix>,v1=<hex hmac-sha256 от "<unix>.<тело>">".
+func signPayload(secret string, payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(payloadMAC(secret, ts, payload))
+}
+
+// verifySignature проверяет заголовок подписи signPayload. Подписей v1 может быть несколько —
+// так провайдер меняет ключ без простоя.
+func verifySignature(secret, header string, payload []byte, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("%w: webhook secret is not configured", ErrInvalidSignature)
+	}
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > signatureTolerance || d < -signatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	want := payloadMAC(secret, ts, payload)
+	for _, sig := range sigs {
+		if hmac.Equal(sig, want) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func payloadMAC(secret, ts string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,181 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// StripeSignatureHeader — заголовок подписи уведомлений Stripe
+const StripeSignatureHeader = "Stripe-Signature"
+
+const defaultStripeURL = "https://api.stripe.com"
+
+// stripeEventTypes — уведомления Stripe о PaymentIntent и их типы в сервисе
+var stripeEventTypes = map[string]string{
+	"payment_intent.amount_capturable_updated": EventAuthorized,
+	"payment_intent.succeeded":                 EventSucceeded,
+	"payment_intent.payment_failed":            EventFailed,
+	"payment_intent.canceled":                  EventCancelled,
+}
+
+// Stripe работает с API PaymentIntents Stripe и совместимыми с ним сервисами.
+type Stripe struct {
+	apiKey  string
+	baseURL string
+	secret  string
+	client  *http.Client
+}
+
+func NewStripe(cfg config.Stripe, webhookSecret string) *Stripe {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultStripeURL
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Stripe{
+		apiKey:  cfg.APIKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  webhookSecret,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *Stripe) Name() string { return DriverStripe }
+
+// stripeIntent — поля PaymentIntent из ответов API и уведомлений
+type stripeIntent struct {
+	ID               string `json:"id"`
+	ClientSecret     string `json:"client_secret"`
+	Amount           int64  `json:"amount"`
+	AmountCapturable int64  `json:"amount_capturable"`
+	Currency         string `json:"currency"`
+}
+
+func (s *Stripe) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("currency", req.Currency)
+	form.Set("capture_method", "manual")
+	form.Set("metadata[booking_id]", strconv.FormatInt(req.BookingID, 10))
+	var in stripeIntent
+	if err := s.post(ctx, "/v1/payment_intents", form, req.IdempotencyKey, &in); err != nil {
+		return nil, err
+	}
+	return &Intent{ID: in.ID, ClientSecret: in.ClientSecret, Amount: in.Amount, Currency: in.Currency}, nil
+}
+
+func (s *Stripe) Capture(ctx context.Context, intentID string) error {
+	// ключ идемпотентности по платежу: повторное списание после сбоя сети не выполняется дважды
+	return s.post(ctx, "/v1/payment_intents/"+url.PathEscape(intentID)+"/capture", url.Values{}, "capture-"+intentID, nil)
+}
+
+func (s *Stripe) Cancel(ctx context.Context, intentID string) error {
+	return s.post(ctx, "/v1/payment_intents/"+url.PathEscape(intentID)+"/cancel", url.Values{}, "cancel-"+intentID, nil)
+}
+
+func (s *Stripe) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.IntentID)
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	var out struct {
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+	}
+	if err := s.post(ctx, "/v1/refunds", form, req.IdempotencyKey, &out); err != nil {
+		return nil, err
+	}
+	return &Refund{ID: out.ID, Amount: out.Amount}, nil
+}
+
+func (s *Stripe) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	if err := verifySignature(s.secret, header.Get(StripeSignatureHeader), payload, time.Now()); err != nil {
+		return nil, err
+	}
+	var e struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripeIntent `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	typ, ok := stripeEventTypes[e.Type]
+	if !ok {
+		return &WebhookEvent{ID: e.ID}, nil
+	}
+	if e.Data.Object.ID == "" {
+		return nil, ErrInvalidWebhook
+	}
+	amount := e.Data.Object.Amount
+	if typ == EventAuthorized {
+		amount = e.Data.Object.AmountCapturable
+	}
+	return &WebhookEvent{ID: e.ID, Type: typ, IntentID: e.Data.Object.ID, Amount: amount}, nil
+}
+
+// StripeError — ошибка, которую вернул API.
+type StripeError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *StripeError) Error() string {
+	return fmt.Sprintf("stripe: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// Temporary сообщает, что запрос можно повторить: сбой на стороне провайдера или превышен лимит запросов.
+func (e *StripeError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("stripe %s: read response: %w", path, err)
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error StripeError `json:"error"`
+		}
+		_ = json.Unmarshal(body, &e)
+		e.Error.StatusCode = resp.StatusCode
+		return &e.Error
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("stripe %s: decode response: %w", path, err)
+	}
+	return nil
+}
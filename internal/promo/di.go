@@ -0,0 +1,33 @@
+package promo
+
+import (
+	"github.com/jmoiron/sqlx"
+	"laschool.ru/event-booking-service/internal/db"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const (
+	DIPromoRepo    = "promo-repository"
+	DIPromoService = "promo-service"
+)
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		if err := builder.Add(container.Def{
+			Name: DIPromoRepo,
+			Build: func(ctn container.Container) (interface{}, error) {
+				database := ctn.Get(db.DIDatabase).(*sqlx.DB)
+				return NewRepository(database), nil
+			},
+		}); err != nil {
+			return err
+		}
+		return builder.Add(container.Def{
+			Name: DIPromoService,
+			Build: func(ctn container.Container) (interface{}, error) {
+				repo := ctn.Get(DIPromoRepo).(Repository)
+				return NewService(repo), nil
+			},
+		})
+	})
+}
@@ -0,0 +1,163 @@
+package promo
+
+import (
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kind — вид скидки.
+type Kind string
+
+const (
+	// KindPercent — процент от суммы брони, от 1 до 100
+	KindPercent Kind = "percent"
+	// KindFixed — сумма в копейках; больше суммы брони не скидывается
+	KindFixed Kind = "fixed"
+)
+
+var (
+	ErrNotFound   = errors.New("promo code not found")
+	ErrCodeExists = errors.New("promo code already exists")
+	// ErrNotStarted — окно действия кода ещё не открылось
+	ErrNotStarted = errors.New("promo code is not active yet")
+	ErrExpired    = errors.New("promo code has expired")
+	// ErrExhausted — по коду оформлено максимальное число броней
+	ErrExhausted = errors.New("promo code usage limit reached")
+	// ErrNotApplicable — код не действует на это событие (или событие бесплатное)
+	ErrNotApplicable = errors.New("promo code does not apply to this event")
+)
+
+// IsRedeemError сообщает, что код нельзя применить к брони. Ошибки различаются текстом — его видит клиент.
+func IsRedeemError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotStarted) || errors.Is(err, ErrExpired) ||
+		errors.Is(err, ErrExhausted) || errors.Is(err, ErrNotApplicable)
+}
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// PromoCode — промокод на скидку при бронировании.
+type PromoCode struct {
+	ID   int64  `db:"id" json:"id" example:"1"`
+	Code string `db:"code" json:"code" example:"SPRING25"`
+	Kind Kind   `db:"kind" json:"kind" example:"percent"`
+	// Value — процент или сумма в копейках, в зависимости от Kind
+	Value int64 `db:"value" json:"value" example:"25"`
+	// MaxUses — сколько броней можно оформить по коду; 0 — без ограничения
+	MaxUses int `db:"max_uses" json:"max_uses" example:"100"`
+	// Uses — сколько броней оформлено; брони, отменённые до оплаты, не считаются
+	Uses int `db:"uses" json:"uses" example:"12"`
+	// ValidFrom и ValidUntil — окно действия; пусто — без ограничения с этой стороны
+	ValidFrom  *time.Time `db:"valid_from" json:"valid_from,omitempty"`
+	ValidUntil *time.Time `db:"valid_until" json:"valid_until,omitempty"`
+	// EventIDs — события, на которые действует код; пусто — на все. Типов билетов в сервисе нет,
+	// поэтому других ограничений применимости у кода нет
+	EventIDs  EventIDs  `db:"event_ids" json:"event_ids"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PromoCodeRequest модель запроса на создание и изменение промокода
+type PromoCodeRequest struct {
+	// Code — латиница, цифры, - и _; регистр не важен
+	Code       string     `json:"code" example:"SPRING25"`
+	Kind       Kind       `json:"kind" example:"percent"`
+	Value      int64      `json:"value" example:"25"`
+	MaxUses    int        `json:"max_uses" example:"100"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty" example:"2026-03-01T00:00:00Z"`
+	ValidUntil *time.Time `json:"valid_until,omitempty" example:"2026-04-01T00:00:00Z"`
+	EventIDs   []int64    `json:"event_ids,omitempty"`
+}
+
+// EventIDs — список ID событий в колонке BIGINT[].
+type EventIDs []int64
+
+func (ids *EventIDs) Scan(src any) error {
+	return pgtype.NewMap().SQLScanner((*[]int64)(ids)).Scan(src)
+}
+
+func (ids EventIDs) Value() (driver.Value, error) {
+	// литерал массива Postgres; пустой список — '{}', а не NULL: колонка NOT NULL
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return "{" + strings.Join(parts, ",") + "}", nil
+}
+
+// NormalizeCode приводит код к виду, в котором он хранится.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// normalize проверяет промокод и приводит код и список событий к хранимому виду.
+func (p *PromoCode) normalize() error {
+	p.Code = NormalizeCode(p.Code)
+	if !codePattern.MatchString(p.Code) {
+		return errors.New("code must be 3-32 latin letters, digits, - or _")
+	}
+	switch p.Kind {
+	case KindPercent:
+		if p.Value < 1 || p.Value > 100 {
+			return errors.New("percent value must be between 1 and 100")
+		}
+	case KindFixed:
+		if p.Value <= 0 {
+			return errors.New("fixed value must be positive")
+		}
+	default:
+		return errors.New("kind must be percent or fixed")
+	}
+	if p.MaxUses < 0 {
+		return errors.New("max_uses must not be negative")
+	}
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	for _, id := range p.EventIDs {
+		if id <= 0 {
+			return errors.New("event_ids must be positive")
+		}
+	}
+	slices.Sort(p.EventIDs)
+	p.EventIDs = slices.Compact(p.EventIDs)
+	if p.EventIDs == nil {
+		p.EventIDs = EventIDs{}
+	}
+	return nil
+}
+
+// check проверяет, что код можно применить к брони события eventID в момент now.
+func (p *PromoCode) check(eventID int64, now time.Time) error {
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return ErrNotStarted
+	}
+	if p.ValidUntil != nil && !now.Before(*p.ValidUntil) {
+		return ErrExpired
+	}
+	if len(p.EventIDs) > 0 && !slices.Contains(p.EventIDs, eventID) {
+		return ErrNotApplicable
+	}
+	if p.MaxUses > 0 && p.Uses >= p.MaxUses {
+		return ErrExhausted
+	}
+	return nil
+}
+
+// Discount — скидка с суммы amount в копейках; не больше самой суммы.
+func (p *PromoCode) Discount(amount int64) int64 {
+	var d int64
+	switch p.Kind {
+	case KindPercent:
+		d = amount * p.Value / 100
+	case KindFixed:
+		d = p.Value
+	}
+	return min(d, amount)
+}
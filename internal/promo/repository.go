@@ -0,0 +1,126 @@
+package promo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	Create(ctx context.Context, p *PromoCode) (int64, error)
+	GetByID(ctx context.Context, id int64) (*PromoCode, error)
+	List(ctx context.Context, limit, offset int) ([]PromoCode, error)
+	// Update меняет условия кода; счётчик использований не трогает
+	Update(ctx context.Context, p *PromoCode) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: db}
+}
+
+const promoColumns = `id, code, kind, value, max_uses, uses, valid_from, valid_until, event_ids, created_at, updated_at`
+
+func (r *repository) Create(ctx context.Context, p *PromoCode) (int64, error) {
+	const q = `INSERT INTO promo_codes (code, kind, value, max_uses, valid_from, valid_until, event_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	var id int64
+	if err := r.db.QueryRowxContext(ctx, q, p.Code, p.Kind, p.Value, p.MaxUses, p.ValidFrom, p.ValidUntil, p.EventIDs).Scan(&id); err != nil {
+		return 0, codeError(err)
+	}
+	return id, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*PromoCode, error) {
+	const q = `SELECT ` + promoColumns + ` FROM promo_codes WHERE id = $1`
+	var p PromoCode
+	if err := r.db.GetContext(ctx, &p, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *repository) List(ctx context.Context, limit, offset int) ([]PromoCode, error) {
+	const q = `SELECT ` + promoColumns + ` FROM promo_codes ORDER BY id DESC LIMIT $1 OFFSET $2`
+	list := []PromoCode{}
+	if err := r.db.SelectContext(ctx, &list, q, limit, offset); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *repository) Update(ctx context.Context, p *PromoCode) error {
+	const q = `UPDATE promo_codes
+		SET code = $1, kind = $2, value = $3, max_uses = $4, valid_from = $5, valid_until = $6, event_ids = $7, updated_at = NOW()
+		WHERE id = $8`
+	res, err := r.db.ExecContext(ctx, q, p.Code, p.Kind, p.Value, p.MaxUses, p.ValidFrom, p.ValidUntil, p.EventIDs, p.ID)
+	if err != nil {
+		return codeError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	// брони с кодом остаются: promo_code_id обнуляется, скидка сохраняется в брони
+	res, err := r.db.ExecContext(ctx, `DELETE FROM promo_codes WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Redeem применяет код к брони события eventID на сумму amount внутри транзакции бронирования tx:
+// блокирует код, проверяет его и засчитывает использование. Вызывается под блокировкой события,
+// поэтому порядок блокировок везде один: событие, затем код. Параллельные брони ждут друг друга
+// на строке кода и не превышают MaxUses. Возвращает код и скидку в копейках.
+func Redeem(ctx context.Context, tx *sqlx.Tx, code string, eventID, amount int64, now time.Time) (*PromoCode, int64, error) {
+	var p PromoCode
+	const sel = `SELECT ` + promoColumns + ` FROM promo_codes WHERE code = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &p, sel, NormalizeCode(code)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, err
+	}
+	if err := p.check(eventID, now); err != nil {
+		return nil, 0, err
+	}
+	// на бесплатное событие скидки нет, а использование сгорело бы зря
+	if amount <= 0 {
+		return nil, 0, ErrNotApplicable
+	}
+	const upd = `UPDATE promo_codes SET uses = uses + 1, updated_at = NOW() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, upd, p.ID); err != nil {
+		return nil, 0, err
+	}
+	p.Uses++
+	return &p, p.Discount(amount), nil
+}
+
+// codeError переводит нарушение уникальности кода в ErrCodeExists.
+func codeError(err error) error {
+	var pgErr *pgconn.PgError
+	// 23505 — unique_violation: код уже занят
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrCodeExists
+	}
+	return err
+}
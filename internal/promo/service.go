@@ -0,0 +1,81 @@
+package promo
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"laschool.ru/event-booking-service/internal/logger"
+)
+
+var tracer = otel.Tracer("laschool.ru/event-booking-service/internal/promo")
+
+// Service управляет промокодами. Применяются коды при бронировании — см. Redeem.
+type Service interface {
+	Create(ctx context.Context, p *PromoCode) (int64, error)
+	Get(ctx context.Context, id int64) (*PromoCode, error)
+	List(ctx context.Context, limit, offset int) ([]PromoCode, error)
+	Update(ctx context.Context, p *PromoCode) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, p *PromoCode) (int64, error) {
+	if err := p.normalize(); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.Create(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	logger.FromContext(ctx).Info("promo code created", "promo_code_id", id, "code", p.Code)
+	return id, nil
+}
+
+func (s *service) Get(ctx context.Context, id int64) (*PromoCode, error) {
+	ctx, span := tracer.Start(ctx, "promo.Get")
+	defer span.End()
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, limit, offset int) ([]PromoCode, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+func (s *service) Update(ctx context.Context, p *PromoCode) error {
+	if p.ID == 0 {
+		return errors.New("id is required")
+	}
+	if err := p.normalize(); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, p); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("promo code updated", "promo_code_id", p.ID, "code", p.Code)
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, id int64) error {
+	if id == 0 {
+		return errors.New("id is required")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("promo code deleted", "promo_code_id", id)
+	return nil
+}
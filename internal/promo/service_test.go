@@ -0,0 +1,119 @@
+package promo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type repoStub struct {
+	created *PromoCode
+}
+
+func (r *repoStub) Create(ctx context.Context, p *PromoCode) (int64, error) {
+	if r.created != nil && r.created.Code == p.Code {
+		return 0, ErrCodeExists
+	}
+	r.created = p
+	return 1, nil
+}
+func (r *repoStub) GetByID(ctx context.Context, id int64) (*PromoCode, error) {
+	return nil, ErrNotFound
+}
+func (r *repoStub) List(ctx context.Context, limit, offset int) ([]PromoCode, error) {
+	return nil, nil
+}
+func (r *repoStub) Update(ctx context.Context, p *PromoCode) error { return nil }
+func (r *repoStub) Delete(ctx context.Context, id int64) error     { return nil }
+
+func TestService_Create_Validation(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	cases := []PromoCode{
+		{Code: "A", Kind: KindPercent, Value: 10},
+		{Code: "SPRING 25", Kind: KindPercent, Value: 10},
+		{Code: "SPRING25", Kind: "bogo", Value: 10},
+		{Code: "SPRING25", Kind: KindPercent, Value: 0},
+		{Code: "SPRING25", Kind: KindPercent, Value: 101},
+		{Code: "SPRING25", Kind: KindFixed, Value: -100},
+		{Code: "SPRING25", Kind: KindFixed, Value: 100, MaxUses: -1},
+		{Code: "SPRING25", Kind: KindFixed, Value: 100, ValidFrom: &later, ValidUntil: &now},
+		{Code: "SPRING25", Kind: KindFixed, Value: 100, EventIDs: EventIDs{0}},
+	}
+	svc := NewService(&repoStub{})
+	for _, c := range cases {
+		if _, err := svc.Create(context.Background(), &c); err == nil {
+			t.Fatalf("expected validation error for %+v", c)
+		}
+	}
+}
+
+func TestService_Create_Normalizes(t *testing.T) {
+	repo := &repoStub{}
+	svc := NewService(repo)
+	p := &PromoCode{Code: " spring-25 ", Kind: KindPercent, Value: 25, EventIDs: EventIDs{3, 1, 3}}
+	if _, err := svc.Create(context.Background(), p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.created.Code != "SPRING-25" || !slices.Equal(repo.created.EventIDs, EventIDs{1, 3}) {
+		t.Fatalf("unexpected stored code %+v", repo.created)
+	}
+	if _, err := svc.Create(context.Background(), &PromoCode{Code: "Spring-25", Kind: KindFixed, Value: 100}); !errors.Is(err, ErrCodeExists) {
+		t.Fatalf("expected ErrCodeExists, got %v", err)
+	}
+}
+
+func TestPromoCode_Check(t *testing.T) {
+	now := time.Now()
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+	cases := []struct {
+		p    PromoCode
+		want error
+	}{
+		{PromoCode{}, nil},
+		{PromoCode{ValidFrom: &before, ValidUntil: &after, EventIDs: EventIDs{1, 2}, MaxUses: 5, Uses: 4}, nil},
+		{PromoCode{ValidFrom: &after}, ErrNotStarted},
+		{PromoCode{ValidUntil: &before}, ErrExpired},
+		{PromoCode{ValidUntil: &now}, ErrExpired},
+		{PromoCode{EventIDs: EventIDs{2}}, ErrNotApplicable},
+		{PromoCode{MaxUses: 5, Uses: 5}, ErrExhausted},
+	}
+	for i, c := range cases {
+		if err := c.p.check(1, now); !errors.Is(err, c.want) {
+			t.Fatalf("case %d: expected %v, got %v", i, c.want, err)
+		}
+	}
+}
+
+func TestPromoCode_Discount(t *testing.T) {
+	cases := []struct {
+		p      PromoCode
+		amount int64
+		want   int64
+	}{
+		{PromoCode{Kind: KindPercent, Value: 25}, 300000, 75000},
+		{PromoCode{Kind: KindPercent, Value: 100}, 300000, 300000},
+		{PromoCode{Kind: KindFixed, Value: 50000}, 300000, 50000},
+		{PromoCode{Kind: KindFixed, Value: 500000}, 300000, 300000},
+	}
+	for _, c := range cases {
+		if got := c.p.Discount(c.amount); got != c.want {
+			t.Fatalf("%s %d of %d: expected %d, got %d", c.p.Kind, c.p.Value, c.amount, c.want, got)
+		}
+	}
+}
+
+func TestEventIDs_ScanValue(t *testing.T) {
+	var ids EventIDs
+	if err := ids.Scan("{3,7}"); err != nil || !slices.Equal(ids, EventIDs{3, 7}) {
+		t.Fatalf("unexpected scan %v: %v", ids, err)
+	}
+	if v, _ := (EventIDs{3, 7}).Value(); v != "{3,7}" {
+		t.Fatalf("unexpected value %v", v)
+	}
+	if v, _ := (EventIDs{}).Value(); v != "{}" {
+		t.Fatalf("unexpected empty value %v", v)
+	}
+}
@@ -0,0 +1,23 @@
+package ratelimit
+
+import (
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/cache"
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DIRateLimiter = "rate-limiter"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DIRateLimiter,
+			Build: func(ctn container.Container) (interface{}, error) {
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				rdb := ctn.Get(cache.DIRedis).(*redis.Client)
+				return New(rdb, cfg.RateLimit), nil
+			},
+		})
+	})
+}
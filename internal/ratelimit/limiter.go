@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// Способы выбрать ключ лимита
+const (
+	KeyIP     = "ip"
+	KeyUser   = "user"
+	KeyAPIKey = "api_key"
+)
+
+// slidingWindow — скользящее окно на sorted set: каждый запрос — элемент со временем в score.
+// Возвращает {allowed, count, reset_ms}.
+var slidingWindow = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// Result — решение лимитера для одного запроса.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset — через сколько освободится место в окне
+	Reset time.Duration
+}
+
+type Limiter struct {
+	redis *redis.Client
+	cfg   config.RateLimit
+	now   func() time.Time
+	// apiKeys — sha256 выданных API-ключей
+	apiKeys map[[sha256.Size]byte]struct{}
+}
+
+func New(rdb *redis.Client, cfg config.RateLimit) *Limiter {
+	keys := make(map[[sha256.Size]byte]struct{}, len(cfg.APIKeys))
+	for _, k := range cfg.APIKeys {
+		if k != "" {
+			keys[sha256.Sum256([]byte(k))] = struct{}{}
+		}
+	}
+	return &Limiter{redis: rdb, cfg: cfg, now: time.Now, apiKeys: keys}
+}
+
+// KnownAPIKey сообщает, выдан ли ключ key. Сравниваем хэши, чтобы время ответа
+// не зависело от совпадающего префикса ключа.
+func (l *Limiter) KnownAPIKey(key string) bool {
+	if l == nil || key == "" {
+		return false
+	}
+	_, ok := l.apiKeys[sha256.Sum256([]byte(key))]
+	return ok
+}
+
+// Rule ищет правило для маршрута route и метода method.
+func (l *Limiter) Rule(method, route string) (config.RateLimitRule, bool) {
+	if l == nil || !l.cfg.Enabled {
+		return config.RateLimitRule{}, false
+	}
+	for _, rule := range l.cfg.Rules {
+		if rule.Route == route && (rule.Method == "" || strings.EqualFold(rule.Method, method)) {
+			return rule, true
+		}
+	}
+	return config.RateLimitRule{}, false
+}
+
+func (l *Limiter) FailOpen() bool { return l.cfg.FailOpen }
+
+// Allow учитывает запрос с ключом key по правилу rule.
+func (l *Limiter) Allow(ctx context.Context, rule config.RateLimitRule, key string) (Result, error) {
+	now := l.now().UnixMilli()
+	redisKey := fmt.Sprintf("ratelimit:%s:%s:%s", rule.Method, rule.Route, key)
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+
+	res, err := slidingWindow.Run(ctx, l.redis, []string{redisKey},
+		now, rule.Window.Milliseconds(), rule.Limit, member).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	remaining := rule.Limit - int(res[1])
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   res[0] == 1,
+		Limit:     rule.Limit,
+		Remaining: remaining,
+		Reset:     time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+func newTestLimiter(t *testing.T, rules ...config.RateLimitRule) (*Limiter, *time.Time) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	now := time.Unix(1_700_000_000, 0)
+	l := New(rdb, config.RateLimit{Enabled: true, Rules: rules})
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_SlidingWindow(t *testing.T) {
+	rule := config.RateLimitRule{Route: "/users/login", Method: "POST", Limit: 2, Window: time.Minute, Key: KeyIP}
+	l, now := newTestLimiter(t, rule)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		res, err := l.Allow(ctx, rule, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !res.Allowed {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+
+	res, err := l.Allow(ctx, rule, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("third request should be rejected, got %+v", res)
+	}
+	if res.Reset <= 0 || res.Reset > time.Minute {
+		t.Fatalf("unexpected reset %v", res.Reset)
+	}
+
+	// другой ключ считается отдельно
+	if res, _ := l.Allow(ctx, rule, "10.0.0.2"); !res.Allowed {
+		t.Fatal("other key should be allowed")
+	}
+
+	// окно сдвинулось — снова можно
+	*now = now.Add(time.Minute + time.Second)
+	if res, _ := l.Allow(ctx, rule, "10.0.0.1"); !res.Allowed {
+		t.Fatal("request after window should be allowed")
+	}
+}
+
+func TestLimiter_Rule(t *testing.T) {
+	l, _ := newTestLimiter(t, config.RateLimitRule{Route: "/bookings", Method: "POST", Limit: 1, Window: time.Second})
+
+	if _, ok := l.Rule("POST", "/bookings"); !ok {
+		t.Fatal("expected rule for POST /bookings")
+	}
+	if _, ok := l.Rule("GET", "/bookings"); ok {
+		t.Fatal("did not expect rule for GET /bookings")
+	}
+
+	var disabled *Limiter
+	if _, ok := disabled.Rule("POST", "/bookings"); ok {
+		t.Fatal("nil limiter should have no rules")
+	}
+}
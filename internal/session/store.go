@@ -0,0 +1,61 @@
+// Package session отзывает выданные JWT. Токены stateless, поэтому отзыв хранится
+// как момент времени: все токены пользователя, выданные не позже него, недействительны.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Store struct {
+	redis *redis.Client
+	// ttl — время жизни JWT: после него отозванные токены истекают сами и метку можно забыть
+	ttl time.Duration
+	now func() time.Time
+}
+
+func New(rdb *redis.Client, tokenTTL time.Duration) *Store {
+	return &Store{redis: rdb, ttl: tokenTTL, now: time.Now}
+}
+
+// RevokeAll отзывает все выданные пользователю токены.
+func (s *Store) RevokeAll(ctx context.Context, userID int64) error {
+	if s == nil {
+		return nil
+	}
+	// +1 минута на leeway при проверке exp
+	err := s.redis.Set(ctx, key(userID), s.now().UnixMilli(), s.ttl+time.Minute).Err()
+	if err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// Revoked сообщает, отозван ли токен, выданный в issuedAt. Сравнение идёт с точностью до
+// миллисекунды — так же хранится iat; токен, выданный в момент отзыва, остаётся действительным.
+func (s *Store) Revoked(ctx context.Context, userID int64, issuedAt time.Time) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	v, err := s.redis.Get(ctx, key(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check sessions: %w", err)
+	}
+	revokedAt, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("check sessions: %w", err)
+	}
+	return issuedAt.UnixMilli() < revokedAt, nil
+}
+
+func key(userID int64) string {
+	return "session:revoked:" + strconv.FormatInt(userID, 10)
+}
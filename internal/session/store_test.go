@@ -0,0 +1,43 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStore_RevokeAll(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	now := time.Unix(1_700_000_000, 0)
+	s := New(rdb, time.Hour)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if revoked, err := s.Revoked(ctx, 1, now.Add(-time.Minute)); err != nil || revoked {
+		t.Fatalf("nothing revoked yet, got %v, %v", revoked, err)
+	}
+	if err := s.RevokeAll(ctx, 1); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	if revoked, _ := s.Revoked(ctx, 1, now.Add(-time.Minute)); !revoked {
+		t.Fatal("token issued before revocation must be revoked")
+	}
+	if revoked, _ := s.Revoked(ctx, 1, now.Add(time.Millisecond)); revoked {
+		t.Fatal("token issued after revocation must stay valid")
+	}
+	if revoked, _ := s.Revoked(ctx, 2, now.Add(-time.Minute)); revoked {
+		t.Fatal("other users are not affected")
+	}
+
+	var disabled *Store
+	if revoked, err := disabled.Revoked(ctx, 1, now); err != nil || revoked {
+		t.Fatal("nil store never revokes")
+	}
+}
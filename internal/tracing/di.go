@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/pkg/container"
+)
+
+const DITracing = "tracing"
+
+func init() {
+	container.Register(func(builder *container.Builder, _ map[string]interface{}) error {
+		return builder.Add(container.Def{
+			Name: DITracing,
+			Build: func(ctn container.Container) (interface{}, error) {
+				cfg := ctn.Get(config.DIConfig).(*config.Config)
+				t, err := New(context.Background(), cfg.Tracing)
+				if err != nil {
+					return nil, err
+				}
+				t.Install()
+				return t, nil
+			},
+			Close: func(obj interface{}) error {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				return obj.(*Tracing).Shutdown(ctx)
+			},
+		})
+	})
+}
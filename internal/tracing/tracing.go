@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// Экспортёры, которые можно выбрать в config.Tracing.Exporter
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterMemory = "memory"
+)
+
+const defaultServiceName = "event-booking-service"
+
+// Tracing владеет TracerProvider'ом сервиса.
+// При выключенной трассировке отдаёт noop-провайдер.
+type Tracing struct {
+	sdk    *sdktrace.TracerProvider
+	memory *tracetest.InMemoryExporter
+}
+
+// New создаёт провайдер по конфигу. Глобальный провайдер не трогает — это делает Install.
+func New(ctx context.Context, cfg config.Tracing) (*Tracing, error) {
+	if !cfg.Enabled {
+		return &Tracing{}, nil
+	}
+
+	t := &Tracing{}
+	var exporter sdktrace.SpanExporter
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+		exporter = exp
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("stdout exporter: %w", err)
+		}
+		exporter = exp
+	case ExporterMemory:
+		t.memory = tracetest.NewInMemoryExporter()
+		exporter = t.memory
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	sampler := sdktrace.ParentBased(sdktrace.AlwaysSample())
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sampler),
+	}
+	if t.memory != nil {
+		// в памяти спаны нужны сразу, без батчинга
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	} else {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	t.sdk = sdktrace.NewTracerProvider(opts...)
+	return t, nil
+}
+
+// Install делает провайдер глобальным и включает W3C propagation.
+func (t *Tracing) Install() {
+	otel.SetTracerProvider(t.TracerProvider())
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+func (t *Tracing) TracerProvider() trace.TracerProvider {
+	if t.sdk == nil {
+		return noop.NewTracerProvider()
+	}
+	return t.sdk
+}
+
+// Spans возвращает записанные спаны (только для экспортёра memory).
+func (t *Tracing) Spans() tracetest.SpanStubs {
+	if t.memory == nil {
+		return nil
+	}
+	return t.memory.GetSpans()
+}
+
+// Shutdown дописывает оставшиеся спаны и останавливает экспортёр.
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.sdk == nil {
+		return nil
+	}
+	return t.sdk.Shutdown(ctx)
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+func TestNew_MemoryExporter(t *testing.T) {
+	tr, err := New(context.Background(), config.Tracing{Enabled: true, Exporter: ExporterMemory})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tr.Shutdown(context.Background())
+
+	_, span := tr.TracerProvider().Tracer("test").Start(context.Background(), "booking.Create")
+	span.End()
+
+	spans := tr.Spans()
+	if len(spans) != 1 || spans[0].Name != "booking.Create" {
+		t.Fatalf("expected one booking.Create span, got %+v", spans)
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	tr, err := New(context.Background(), config.Tracing{Enabled: false, Exporter: "bogus"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, span := tr.TracerProvider().Tracer("test").Start(context.Background(), "noop")
+	if span.SpanContext().IsValid() {
+		t.Fatal("expected noop span when tracing is disabled")
+	}
+}
+
+func TestNew_UnknownExporter(t *testing.T) {
+	if _, err := New(context.Background(), config.Tracing{Enabled: true, Exporter: "bogus"}); err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+}
@@ -1,11 +1,14 @@
 package user
 
 import (
-	"fmt"
-
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/background"
+	"laschool.ru/event-booking-service/internal/cache"
 	"laschool.ru/event-booking-service/internal/config"
 	"laschool.ru/event-booking-service/internal/db"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/session"
 	"laschool.ru/event-booking-service/pkg/container"
 )
 
@@ -29,10 +32,26 @@ func init() {
 		return builder.Add(container.Def{
 			Name: DIUserService,
 			Build: func(ctn container.Container) (interface{}, error) {
-				fmt.Println("Building user service")
 				repo := ctn.Get(DIUserRepo).(Repository)
 				cfg := ctn.Get(config.DIConfig).(*config.Config)
-				return NewService(repo, cfg.JWT.Secret, cfg.JWT.TTL), nil
+				var guard *LoginGuard
+				if cfg.LoginProtection.Enabled {
+					guard = NewLoginGuard(ctn.Get(cache.DIRedis).(*redis.Client), cfg.LoginProtection)
+				}
+				m := ctn.Get(mailer.DIMailer).(mailer.Mailer)
+				return NewService(repo, Options{
+					Guard:           guard,
+					Notifier:        NewMailNotifier(m),
+					Mailer:          m,
+					Sessions:        ctn.Get(session.DISessions).(*session.Store),
+					Tasks:           ctn.Get(background.DIBackground).(*background.Group),
+					Secret:          cfg.JWT.Secret,
+					TokenTTL:        cfg.JWT.TTL,
+					BaseURL:         cfg.Account.BaseURL,
+					VerificationTTL: cfg.Account.VerificationTTL,
+					ResetURL:        cfg.Account.ResetURL,
+					ResetTTL:        cfg.Account.ResetTTL,
+				}), nil
 			},
 		})
 	})
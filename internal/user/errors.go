@@ -0,0 +1,26 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUserNotFound — пользователя с таким email нет. Наружу не отдаётся, см. ErrInvalidCredentials.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidCredentials — неверный email или пароль; одинаковая ошибка не даёт перебирать email.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailTaken         = errors.New("email already exists")
+	// ErrInvalidToken — токен из письма подделан, просрочен или уже использован.
+	ErrInvalidToken = errors.New("invalid or expired token")
+)
+
+// LockedError — вход временно заблокирован после серии неудачных попыток.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again after %s", e.Until.UTC().Format(time.RFC3339))
+}
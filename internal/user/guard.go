@@ -0,0 +1,184 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"laschool.ru/event-booking-service/internal/config"
+)
+
+// Области блокировки входа
+const (
+	LockScopeAccount = "account"
+	LockScopeIP      = "ip"
+)
+
+// lockoutHistoryTTL — сколько помнить прошлые блокировки для экспоненциального роста
+const lockoutHistoryTTL = 24 * time.Hour
+
+// Lockout — блокировка, выставленная после серии неудачных попыток.
+type Lockout struct {
+	Scope    string
+	Email    string
+	UserID   *int64
+	IP       string
+	Failures int
+	Until    time.Time
+}
+
+// LoginGuard считает неудачные попытки входа в Redis отдельно по email и по IP
+// и блокирует вход, когда счётчик превышает порог.
+type LoginGuard struct {
+	redis *redis.Client
+	cfg   config.LoginProtection
+	now   func() time.Time
+}
+
+func NewLoginGuard(rdb *redis.Client, cfg config.LoginProtection) *LoginGuard {
+	if cfg.MaxAccountFailures <= 0 {
+		cfg.MaxAccountFailures = 5
+	}
+	if cfg.MaxIPFailures <= 0 {
+		cfg.MaxIPFailures = 20
+	}
+	if cfg.FailureWindow <= 0 {
+		cfg.FailureWindow = 15 * time.Minute
+	}
+	if cfg.BaseLockout <= 0 {
+		cfg.BaseLockout = time.Minute
+	}
+	if cfg.MaxLockout < cfg.BaseLockout {
+		cfg.MaxLockout = cfg.BaseLockout
+	}
+	return &LoginGuard{redis: rdb, cfg: cfg, now: time.Now}
+}
+
+// LockedUntil возвращает время окончания блокировки email или IP (нулевое, если вход разрешён).
+func (g *LoginGuard) LockedUntil(ctx context.Context, email, ip string) (time.Time, error) {
+	var until time.Time
+	for _, key := range g.lockKeys(email, ip) {
+		ttl, err := g.redis.PTTL(ctx, key).Result()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("login guard: %w", err)
+		}
+		if ttl > 0 {
+			if t := g.now().Add(ttl); t.After(until) {
+				until = t
+			}
+		}
+	}
+	return until, nil
+}
+
+// Fail учитывает неудачную попытку и возвращает выставленные ею блокировки.
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string) ([]Lockout, error) {
+	var lockouts []Lockout
+	for _, c := range g.counters(email, ip) {
+		failures, err := g.incr(ctx, "login:fail:"+c.id)
+		if err != nil {
+			return lockouts, err
+		}
+		if failures < c.max {
+			continue
+		}
+		until, err := g.lock(ctx, c.id)
+		if err != nil {
+			return lockouts, err
+		}
+		l := Lockout{Scope: c.scope, IP: ip, Failures: failures, Until: until}
+		if c.scope == LockScopeAccount {
+			l.Email = email
+		}
+		lockouts = append(lockouts, l)
+	}
+	return lockouts, nil
+}
+
+// Reset сбрасывает счётчик email после успешного входа. Счётчик IP не сбрасывается:
+// иначе перебор по многим аккаунтам маскировался бы входом в свой.
+func (g *LoginGuard) Reset(ctx context.Context, email string) error {
+	if email == "" {
+		return nil
+	}
+	id := LockScopeAccount + ":" + email
+	if err := g.redis.Del(ctx, "login:fail:"+id, "login:lockouts:"+id).Err(); err != nil {
+		return fmt.Errorf("login guard: %w", err)
+	}
+	return nil
+}
+
+type failCounter struct {
+	scope string
+	id    string
+	max   int
+}
+
+func (g *LoginGuard) counters(email, ip string) []failCounter {
+	var cs []failCounter
+	if email != "" {
+		cs = append(cs, failCounter{scope: LockScopeAccount, id: LockScopeAccount + ":" + email, max: g.cfg.MaxAccountFailures})
+	}
+	if ip != "" {
+		cs = append(cs, failCounter{scope: LockScopeIP, id: LockScopeIP + ":" + ip, max: g.cfg.MaxIPFailures})
+	}
+	return cs
+}
+
+func (g *LoginGuard) lockKeys(email, ip string) []string {
+	var keys []string
+	for _, c := range g.counters(email, ip) {
+		keys = append(keys, "login:lock:"+c.id)
+	}
+	return keys
+}
+
+func (g *LoginGuard) incr(ctx context.Context, key string) (int, error) {
+	n, err := g.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("login guard: %w", err)
+	}
+	if n == 1 {
+		if err := g.redis.PExpire(ctx, key, g.cfg.FailureWindow).Err(); err != nil {
+			return 0, fmt.Errorf("login guard: %w", err)
+		}
+	}
+	return int(n), nil
+}
+
+// lock выставляет блокировку длительностью BaseLockout * 2^(n-1), где n — номер блокировки за сутки.
+func (g *LoginGuard) lock(ctx context.Context, id string) (time.Time, error) {
+	n, err := g.redis.Incr(ctx, "login:lockouts:"+id).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("login guard: %w", err)
+	}
+	d := g.lockoutDuration(int(n))
+
+	pipe := g.redis.TxPipeline()
+	pipe.PExpire(ctx, "login:lockouts:"+id, lockoutHistoryTTL)
+	pipe.Set(ctx, "login:lock:"+id, 1, d)
+	pipe.Del(ctx, "login:fail:"+id)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return time.Time{}, fmt.Errorf("login guard: %w", err)
+	}
+	return g.now().Add(d), nil
+}
+
+func (g *LoginGuard) lockoutDuration(n int) time.Duration {
+	d := g.cfg.BaseLockout
+	for i := 1; i < n && d < g.cfg.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > g.cfg.MaxLockout {
+		d = g.cfg.MaxLockout
+	}
+	return d
+}
+
+// normalizeEmail приводит email к виду, в котором он хранится и считается в счётчиках.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -2,15 +2,30 @@ package user
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
 
 	"laschool.ru/event-booking-service/internal/http/handlers"
-	"laschool.ru/event-booking-service/pkg/container"
+	"laschool.ru/event-booking-service/internal/http/reqctx"
 )
 
+// Handler обслуживает эндпоинты /users.
+type Handler struct {
+	users Service
+}
+
+func NewHandler(users Service) *Handler {
+	return &Handler{users: users}
+}
+
 // RegisterHandler godoc
 // @Summary      Регистрация пользователя
-// @Description  Регистрирует нового пользователя
+// @Description  Регистрирует нового пользователя и отправляет письмо для подтверждения email
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -19,19 +34,11 @@ import (
 // @Failure      400  {object}  handlers.ErrorResponse
 // @Failure      409  {object}  handlers.ErrorResponse
 // @Router       /users/register [post]
-func RegisterHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		handlers.WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-
-	userv := ctn.Get(DIUserService).(Service)
-
 	var req RegisterRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -43,8 +50,12 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		handlers.WriteError(w, http.StatusBadRequest, "password cannot be empty")
 		return
 	}
+	if !validEmail(req.Email) {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid email")
+		return
+	}
 
-	id, err := userv.Register(r.Context(), &User{Email: req.Email, Name: req.Name, Password: req.Password})
+	id, err := h.users.Register(r.Context(), &User{Email: req.Email, Name: req.Name, Password: req.Password})
 	if err != nil {
 		handlers.WriteError(w, http.StatusConflict, err.Error())
 		return
@@ -67,20 +78,14 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 // @Success      200  {object}  map[string]interface{} "id и token"
 // @Failure      400  {object}  handlers.ErrorResponse
 // @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      429  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
 // @Router       /users/login [post]
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
-	ctn, err := container.Instance(nil, nil)
-	if err != nil {
-		handlers.WriteError(w, http.StatusInternalServerError, "container init failed")
-		return
-	}
-
-	userv := ctn.Get(DIUserService).(Service)
-
 	var req LoginRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -97,9 +102,17 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := userv.Login(r.Context(), req.Email, req.Password)
+	token, err := h.users.Login(r.Context(), req.Email, req.Password, reqctx.ClientIP(r.Context()))
 	if err != nil {
-		handlers.WriteError(w, http.StatusUnauthorized, err.Error())
+		var locked *LockedError
+		switch {
+		case errors.As(err, &locked):
+			writeLocked(w, locked)
+		case errors.Is(err, ErrInvalidCredentials):
+			handlers.WriteError(w, http.StatusUnauthorized, err.Error())
+		default:
+			handlers.WriteError(w, http.StatusInternalServerError, "failed to login")
+		}
 		return
 	}
 
@@ -108,3 +121,281 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 }
+
+// VerifyEmailHandler godoc
+// @Summary      Подтверждение email
+// @Description  Подтверждает email по одноразовой ссылке из письма
+// @Tags         users
+// @Produce      json
+// @Param        token  query  string  true  "Токен из письма"
+// @Success      200  {object}  map[string]interface{} "message"
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
+// @Router       /users/verify [get]
+func (h *Handler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		handlers.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.users.VerifyEmail(r.Context(), token); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			handlers.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		handlers.WriteError(w, http.StatusInternalServerError, "failed to verify email")
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "email verified",
+	})
+}
+
+// ForgotPasswordHandler godoc
+// @Summary      Запрос сброса пароля
+// @Description  Отправляет письмо со ссылкой для сброса пароля. Всегда отвечает 202, чтобы не выдавать, зарегистрирован ли email
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body  user.ForgotPasswordRequest  true  "Email"
+// @Success      202  {object}  map[string]interface{} "message"
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Router       /users/password/forgot [post]
+func (h *Handler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	if req.Email != "" {
+		h.users.ForgotPassword(r.Context(), req.Email)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "if the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPasswordHandler godoc
+// @Summary      Сброс пароля
+// @Description  Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body  user.ResetPasswordRequest  true  "Токен и новый пароль"
+// @Success      200  {object}  map[string]interface{} "message"
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      500  {object}  handlers.ErrorResponse
+// @Router       /users/password/reset [post]
+func (h *Handler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handlers.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Token == "" {
+		handlers.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	if req.Password == "" {
+		handlers.WriteError(w, http.StatusBadRequest, "password cannot be empty")
+		return
+	}
+
+	if err := h.users.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			handlers.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		handlers.WriteError(w, http.StatusInternalServerError, "failed to reset password")
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "password has been reset",
+	})
+}
+
+// GetMeHandler godoc
+// @Summary      Профиль текущего пользователя
+// @Tags         users
+// @Security     Bearer
+// @Produce      json
+// @Success      200  {object}  user.Profile
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      404  {object}  handlers.ErrorResponse
+// @Router       /users/me [get]
+func (h *Handler) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.users.Profile(r.Context(), id)
+	if err != nil {
+		writeUserError(w, err, "failed to get profile")
+		return
+	}
+	handlers.WriteJSON(w, http.StatusOK, u.Profile())
+}
+
+// UpdateMeHandler godoc
+// @Summary      Обновить профиль
+// @Description  Меняет имя и/или email. После смены email его нужно подтвердить заново
+// @Tags         users
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        profile  body  user.UpdateProfileRequest  true  "Новые значения"
+// @Success      200  {object}  user.Profile
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      409  {object}  handlers.ErrorResponse
+// @Router       /users/me [patch]
+func (h *Handler) UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		handlers.WriteError(w, http.StatusBadRequest, "name cannot be empty")
+		return
+	}
+	if req.Email != nil && !validEmail(*req.Email) {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid email")
+		return
+	}
+
+	u, err := h.users.UpdateProfile(r.Context(), id, req)
+	if err != nil {
+		writeUserError(w, err, "failed to update profile")
+		return
+	}
+	handlers.WriteJSON(w, http.StatusOK, u.Profile())
+}
+
+// ChangePasswordHandler godoc
+// @Summary      Сменить пароль
+// @Description  Проверяет текущий пароль, завершает все сессии и возвращает новый JWT
+// @Tags         users
+// @Security     Bearer
+// @Accept       json
+// @Produce      json
+// @Param        request  body  user.ChangePasswordRequest  true  "Текущий и новый пароль"
+// @Success      200  {object}  user.AuthResponse
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse
+// @Failure      429  {object}  handlers.ErrorResponse
+// @Router       /users/me/password [post]
+func (h *Handler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.NewPassword == "" {
+		handlers.WriteError(w, http.StatusBadRequest, "password cannot be empty")
+		return
+	}
+
+	token, err := h.users.ChangePassword(r.Context(), id, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		writeUserError(w, err, "failed to change password")
+		return
+	}
+	handlers.WriteJSON(w, http.StatusOK, AuthResponse{Token: token})
+}
+
+// DeleteMeHandler godoc
+// @Summary      Удалить аккаунт
+// @Description  Удаляет аккаунт после подтверждения паролем. Бронирования сохраняются обезличенными
+// @Tags         users
+// @Security     Bearer
+// @Accept       json
+// @Param        request  body  user.DeleteAccountRequest  true  "Пароль"
+// @Success      204
+// @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse
+// @Failure      429  {object}  handlers.ErrorResponse
+// @Router       /users/me [delete]
+func (h *Handler) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	if err := h.users.DeleteAccount(r.Context(), id, req.Password); err != nil {
+		writeUserError(w, err, "failed to delete account")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentUserID достаёт пользователя, положенного middleware аутентификации.
+func currentUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, ok := reqctx.UserID(r.Context())
+	if !ok {
+		handlers.WriteError(w, http.StatusUnauthorized, "unauthorized")
+	}
+	return id, ok
+}
+
+// writeUserError переводит ошибки сервиса в HTTP-статусы эндпоинтов /users/me.
+func writeUserError(w http.ResponseWriter, err error, fallback string) {
+	var locked *LockedError
+	switch {
+	case errors.As(err, &locked):
+		writeLocked(w, locked)
+	case errors.Is(err, ErrUserNotFound):
+		handlers.WriteError(w, http.StatusNotFound, "user not found")
+	case errors.Is(err, ErrInvalidCredentials):
+		handlers.WriteError(w, http.StatusForbidden, "invalid current password")
+	case errors.Is(err, ErrEmailTaken):
+		handlers.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		handlers.WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// writeLocked отвечает 429 с Retry-After до конца блокировки.
+func writeLocked(w http.ResponseWriter, locked *LockedError) {
+	retry := math.Ceil(time.Until(locked.Until).Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(int(max(retry, 1))))
+	handlers.WriteError(w, http.StatusTooManyRequests, locked.Error())
+}
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
@@ -0,0 +1,210 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/http/reqctx"
+)
+
+type serviceStub struct{}
+
+func (serviceStub) Register(ctx context.Context, u *User) (int64, error) { return 1, nil }
+func (serviceStub) Login(ctx context.Context, email, password, clientIP string) (string, error) {
+	switch password {
+	case "secret":
+		return "token", nil
+	case "locked":
+		return "", &LockedError{Until: time.Now().Add(90 * time.Second)}
+	}
+	return "", ErrInvalidCredentials
+}
+func (serviceStub) Profile(ctx context.Context, id int64) (*User, error) {
+	return &User{ID: id, Email: "alice@example.com", Name: "Alice", Password: "hash"}, nil
+}
+func (serviceStub) UpdateProfile(ctx context.Context, id int64, req UpdateProfileRequest) (*User, error) {
+	if req.Email != nil && *req.Email == "taken@example.com" {
+		return nil, ErrEmailTaken
+	}
+	return &User{ID: id}, nil
+}
+func (serviceStub) ChangePassword(ctx context.Context, id int64, current, next string) (string, error) {
+	switch current {
+	case "secret":
+		return "new-token", nil
+	case "locked":
+		return "", &LockedError{Until: time.Now().Add(time.Minute)}
+	}
+	return "", ErrInvalidCredentials
+}
+func (serviceStub) DeleteAccount(ctx context.Context, id int64, password string) error { return nil }
+func (serviceStub) ForgotPassword(ctx context.Context, email string)                   {}
+func (serviceStub) EnsureByEmail(ctx context.Context, email string) (*User, bool, error) {
+	return &User{ID: 1, Email: email}, false, nil
+}
+func (serviceStub) ResetPassword(ctx context.Context, token, password string) error {
+	if token != "good" {
+		return ErrInvalidToken
+	}
+	return nil
+}
+func (serviceStub) VerifyEmail(ctx context.Context, token string) error {
+	if token != "good" {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func TestLoginHandler(t *testing.T) {
+	h := NewHandler(serviceStub{})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(`{"email":"alice@example.com","password":"secret"}`))
+	w := httptest.NewRecorder()
+	h.LoginHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(`{"email":"alice@example.com","password":"wrong"}`))
+	w = httptest.NewRecorder()
+	h.LoginHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(`{"email":"alice@example.com","password":"locked"}`))
+	w = httptest.NewRecorder()
+	h.LoginHandler(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "90" {
+		t.Fatalf("expected Retry-After 90, got %q", got)
+	}
+}
+
+func TestRegisterHandler_EmptyPassword(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	req := httptest.NewRequest(http.MethodPost, "/users/register", bytes.NewBufferString(`{"email":"alice@example.com"}`))
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestRegisterHandler_InvalidEmail(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	req := httptest.NewRequest(http.MethodPost, "/users/register", bytes.NewBufferString(`{"email":"not-an-email","password":"secret"}`))
+	w := httptest.NewRecorder()
+	h.RegisterHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestVerifyEmailHandler(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	for token, want := range map[string]int{"good": http.StatusOK, "bad": http.StatusBadRequest, "": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodGet, "/users/verify?token="+token, nil)
+		w := httptest.NewRecorder()
+		h.VerifyEmailHandler(w, req)
+		if w.Code != want {
+			t.Fatalf("token %q: expected status %d, got %d", token, want, w.Code)
+		}
+	}
+}
+
+func TestForgotPasswordHandler_AlwaysAccepted(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	for _, body := range []string{`{"email":"alice@example.com"}`, `{"email":"nobody@example.com"}`, `{}`} {
+		req := httptest.NewRequest(http.MethodPost, "/users/password/forgot", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		h.ForgotPasswordHandler(w, req)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("%s: expected status 202, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestResetPasswordHandler(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	cases := map[string]int{
+		`{"token":"good","password":"new"}`: http.StatusOK,
+		`{"token":"bad","password":"new"}`:  http.StatusBadRequest,
+		`{"token":"good"}`:                  http.StatusBadRequest,
+	}
+	for body, want := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/users/password/reset", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		h.ResetPasswordHandler(w, req)
+		if w.Code != want {
+			t.Fatalf("%s: expected status %d, got %d", body, want, w.Code)
+		}
+	}
+}
+
+func TestGetMeHandler(t *testing.T) {
+	h := NewHandler(serviceStub{})
+
+	w := httptest.NewRecorder()
+	h.GetMeHandler(w, httptest.NewRequest(http.MethodGet, "/users/me", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without user, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req = req.WithContext(reqctx.WithUserID(req.Context(), 7))
+	w = httptest.NewRecorder()
+	h.GetMeHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"email":"alice@example.com"`) || strings.Contains(body, "hash") {
+		t.Fatalf("unexpected profile body %s", body)
+	}
+}
+
+func TestUpdateMeAndChangePasswordHandlers(t *testing.T) {
+	h := NewHandler(serviceStub{})
+	cases := []struct {
+		handler http.HandlerFunc
+		body    string
+		want    int
+	}{
+		{h.UpdateMeHandler, `{"email":"not-an-email"}`, http.StatusBadRequest},
+		{h.UpdateMeHandler, `{"name":"  "}`, http.StatusBadRequest},
+		{h.UpdateMeHandler, `{"email":"taken@example.com"}`, http.StatusConflict},
+		{h.UpdateMeHandler, `{"name":"Alice"}`, http.StatusOK},
+		{h.ChangePasswordHandler, `{"current_password":"wrong","new_password":"x"}`, http.StatusForbidden},
+		{h.ChangePasswordHandler, `{"current_password":"locked","new_password":"x"}`, http.StatusTooManyRequests},
+		{h.ChangePasswordHandler, `{"current_password":"secret","new_password":""}`, http.StatusBadRequest},
+		{h.ChangePasswordHandler, `{"current_password":"secret","new_password":"x"}`, http.StatusOK},
+		{h.DeleteMeHandler, `{"password":"secret"}`, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/users/me", bytes.NewBufferString(c.body))
+		req = req.WithContext(reqctx.WithUserID(req.Context(), 7))
+		w := httptest.NewRecorder()
+		c.handler(w, req)
+		if w.Code != c.want {
+			t.Fatalf("%s: expected status %d, got %d", c.body, c.want, w.Code)
+		}
+	}
+}
+
+func TestUser_PasswordNotSerialized(t *testing.T) {
+	data, err := json.Marshal(User{ID: 7, Email: "alice@example.com", Password: "$2a$10$hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "password") || strings.Contains(string(data), "hash") {
+		t.Fatalf("password hash leaked: %s", data)
+	}
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/mailer"
+)
+
+func verificationMessage(u *User, baseURL, token string) mailer.Message {
+	link := strings.TrimRight(baseURL, "/") + "/users/verify?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      u.Email,
+		Subject: "Подтвердите email",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Чтобы подтвердить адрес и получить возможность бронировать события, перейдите по ссылке:\n%s\n\n"+
+			"Если вы не регистрировались, просто проигнорируйте это письмо.\n", u.Name, link),
+	}
+}
+
+func passwordResetMessage(u *User, resetURL, token string, ttl time.Duration) mailer.Message {
+	link := resetURL + "?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      u.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Чтобы задать новый пароль, перейдите по ссылке (действует %s):\n%s\n\n"+
+			"После сброса все активные сессии будут завершены.\n"+
+			"Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n", u.Name, ttl, link),
+	}
+}
+
+func accountLockedMessage(u *User, until time.Time) mailer.Message {
+	return mailer.Message{
+		To:      u.Email,
+		Subject: "Вход в аккаунт временно заблокирован",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Мы зафиксировали несколько неудачных попыток входа в ваш аккаунт и заблокировали вход до %s (UTC).\n"+
+			"Если это были не вы, рекомендуем сменить пароль.\n", u.Name, until.UTC().Format("2006-01-02 15:04")),
+	}
+}
@@ -3,11 +3,36 @@ package user
 import "time"
 
 type User struct {
-	ID        int64     `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Name      string    `db:"name" json:"name"`
-	Password  string    `db:"password_hash" json:"password_hash"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID       int64  `db:"id" json:"id"`
+	Email    string `db:"email" json:"email"`
+	Name     string `db:"name" json:"name"`
+	Password string `db:"password_hash" json:"-"`
+	// EmailVerified — email подтверждён по ссылке из письма; без этого нельзя бронировать
+	EmailVerified bool      `db:"email_verified" json:"email_verified"`
+	Role          string    `db:"role" json:"role"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// Роли пользователей. Роль попадает в JWT, поэтому после её смены нужно отозвать сессии.
+const (
+	RoleUser = "user"
+	// RoleStaff — сотрудник на входе: отмечает посетителей по билетам
+	RoleStaff = "staff"
+	RoleAdmin = "admin"
+)
+
+// Profile — данные пользователя, которые можно отдавать клиенту
+type Profile struct {
+	ID            int64     `json:"id" example:"1"`
+	Email         string    `json:"email" example:"alice@example.com"`
+	Name          string    `json:"name" example:"Alice"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role" example:"user"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (u *User) Profile() Profile {
+	return Profile{ID: u.ID, Email: u.Email, Name: u.Name, EmailVerified: u.EmailVerified, Role: u.Role, CreatedAt: u.CreatedAt}
 }
 
 // LoginRequest represents payload for login endpoint
@@ -33,3 +58,31 @@ type RegisterResponse struct {
 	ID      int64  `json:"id" example:"1"`
 	Message string `json:"message" example:"user registered successfully"`
 }
+
+// ForgotPasswordRequest модель запроса на сброс пароля
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"alice@example.com"`
+}
+
+// ResetPasswordRequest модель запроса установки нового пароля по токену из письма
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password" example:"newpassword123"`
+}
+
+// UpdateProfileRequest модель частичного обновления профиля; смена email требует повторного подтверждения
+type UpdateProfileRequest struct {
+	Name  *string `json:"name,omitempty" example:"Alice"`
+	Email *string `json:"email,omitempty" example:"alice@example.com"`
+}
+
+// ChangePasswordRequest модель запроса смены пароля
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"password123"`
+	NewPassword     string `json:"new_password" example:"newpassword123"`
+}
+
+// DeleteAccountRequest модель запроса удаления аккаунта: пароль подтверждает намерение
+type DeleteAccountRequest struct {
+	Password string `json:"password" example:"password123"`
+}
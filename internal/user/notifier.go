@@ -0,0 +1,35 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/mailer"
+)
+
+// Notifier сообщает пользователю о событиях безопасности аккаунта.
+type Notifier interface {
+	AccountLocked(ctx context.Context, u *User, until time.Time) error
+}
+
+// LogNotifier только пишет уведомления в лог — для окружений без почты.
+type LogNotifier struct{}
+
+func (LogNotifier) AccountLocked(ctx context.Context, u *User, until time.Time) error {
+	logger.FromContext(ctx).Info("account locked notification", "user_id", u.ID, "locked_until", until)
+	return nil
+}
+
+// MailNotifier отправляет уведомления письмом.
+type MailNotifier struct {
+	mailer mailer.Mailer
+}
+
+func NewMailNotifier(m mailer.Mailer) *MailNotifier {
+	return &MailNotifier{mailer: m}
+}
+
+func (n *MailNotifier) AccountLocked(ctx context.Context, u *User, until time.Time) error {
+	return n.mailer.Send(ctx, accountLockedMessage(u, until))
+}
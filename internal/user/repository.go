@@ -6,15 +6,31 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Repository interface {
+	// Create создаёт пользователя; email, занятый без учёта регистра, — ErrEmailTaken
 	Create(ctx context.Context, u *User) (int64, error)
-	IsEmailUnique(ctx context.Context, u *User) (bool, error)
-	//GetByID(ctx context.Context, id int64) (*User, error)
+	GetByID(ctx context.Context, id int64) (*User, error)
+	// GetUserByEmail ищет пользователя по email без учёта регистра
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	// FindOrCreate ищет пользователя по email без учёта регистра и создаёт его с паролем password,
+	// если не нашёл; created сообщает, был ли пользователь создан
+	FindOrCreate(ctx context.Context, email, name, password string) (u *User, created bool, err error)
+	// Update сохраняет имя, email и признак его подтверждения; email, занятый другим пользователем, — ErrEmailTaken
+	Update(ctx context.Context, u *User) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	// Delete удаляет пользователя; его бронирования остаются без владельца
+	Delete(ctx context.Context, id int64) error
+	RecordLockout(ctx context.Context, l *Lockout) error
+	CreateToken(ctx context.Context, t *Token) error
+	// VerifyEmail гасит токен подтверждения и отмечает email пользователя подтверждённым
+	VerifyEmail(ctx context.Context, tokenHash string) (int64, error)
+	// ResetPassword гасит токен сброса, ставит новый хэш пароля и гасит остальные токены сброса
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*User, error)
 }
 
 type repository struct {
@@ -25,45 +41,221 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(ctx context.Context, u *User) (int64, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+// emailTaken — нарушена уникальность email (users_email_lower_key сравнивает без учёта регистра).
+func emailTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	// 23505 — unique_violation
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// hashPassword — единственное место, где пароль превращается в bcrypt-хэш.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return 0, fmt.Errorf("hash password error: %w", err)
+		return "", fmt.Errorf("hash password error: %w", err)
 	}
-	isUnique, err := r.IsEmailUnique(ctx, u)
+	return string(hash), nil
+}
+
+func (r *repository) Create(ctx context.Context, u *User) (int64, error) {
+	hashedPassword, err := hashPassword(u.Password)
 	if err != nil {
-		return 0, fmt.Errorf("email uniqueness error: %w", err)
-	}
-	if !isUnique {
-		return 0, fmt.Errorf("email already exists")
+		return 0, err
 	}
 	const q = `INSERT INTO users (email, name, password_hash) VALUES ($1,$2,$3) RETURNING id`
 	var id int64
 	if err := r.db.QueryRowxContext(ctx, q, u.Email, u.Name, hashedPassword).Scan(&id); err != nil {
+		if emailTaken(err) {
+			return 0, ErrEmailTaken
+		}
 		return 0, err
 	}
 	return id, nil
 }
 
-func (r *repository) IsEmailUnique(ctx context.Context, u *User) (bool, error) {
-	var exist bool
-	const q = "SELECT EXISTS(SELECT 1 FROM users WHERE email=$1)"
-	if err := r.db.QueryRowContext(ctx, q, u.Email).Scan(&exist); err != nil {
-		return false, fmt.Errorf("checking email uniqueness: %w", err)
+func (r *repository) GetByID(ctx context.Context, id int64) (*User, error) {
+	var user User
+	const q = "SELECT id, email, name, password_hash, email_verified, role, created_at FROM users WHERE id = $1"
+	if err := r.db.GetContext(ctx, &user, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("query user by id error: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *repository) FindOrCreate(ctx context.Context, email, name, password string) (*User, bool, error) {
+	const sel = `SELECT id, email, name, password_hash, email_verified, role, created_at
+		FROM users WHERE lower(email) = lower($1) ORDER BY id LIMIT 1`
+	find := func() (*User, error) {
+		var u User
+		if err := r.db.GetContext(ctx, &u, sel, email); err != nil {
+			return nil, err
+		}
+		return &u, nil
+	}
+	u, err := find()
+	if err == nil {
+		return u, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("query user by email error: %w", err)
 	}
-	return !exist, nil
 
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return nil, false, err
+	}
+	// параллельный импорт мог создать того же пользователя — тогда просто перечитываем
+	const ins = `INSERT INTO users (email, name, password_hash) VALUES ($1, $2, $3)
+		ON CONFLICT (lower(email)) DO NOTHING RETURNING id`
+	var id int64
+	err = r.db.QueryRowxContext(ctx, ins, email, name, passwordHash).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		u, err := find()
+		if err != nil {
+			return nil, false, fmt.Errorf("query user by email error: %w", err)
+		}
+		return u, false, nil
+	case emailTaken(err):
+		return nil, false, ErrEmailTaken
+	case err != nil:
+		return nil, false, fmt.Errorf("insert user error: %w", err)
+	}
+	u, err = find()
+	if err != nil {
+		return nil, false, fmt.Errorf("query user by email error: %w", err)
+	}
+	return u, true, nil
 }
 
 func (r *repository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	const q = "SELECT id, email, name, password_hash FROM users WHERE email = $1"
+	// адреса, сохранённые до приведения к нижнему регистру, тоже находятся; из дублей берём самый старый
+	const q = `SELECT id, email, name, password_hash, email_verified, role, created_at
+		FROM users WHERE lower(email) = lower($1) ORDER BY id LIMIT 1`
 	err := r.db.GetContext(ctx, &user, q, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user with email %s not exist", email)
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("query user by email error: %w", err)
 	}
 	return &user, nil
 }
+
+func (r *repository) Update(ctx context.Context, u *User) error {
+	const q = `UPDATE users SET name = $2, email = $3, email_verified = $4 WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, q, u.ID, u.Name, u.Email, u.EmailVerified)
+	if err != nil {
+		if emailTaken(err) {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("update user error: %w", err)
+	}
+	return checkAffected(res)
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	const q = `UPDATE users SET password_hash = $2 WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, q, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("update password error: %w", err)
+	}
+	return checkAffected(res)
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete user error: %w", err)
+	}
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *repository) RecordLockout(ctx context.Context, l *Lockout) error {
+	const q = `INSERT INTO auth_lockouts (scope, email, user_id, ip, failures, locked_until)
+		VALUES ($1,$2,$3,$4,$5,$6)`
+	if _, err := r.db.ExecContext(ctx, q, l.Scope, l.Email, l.UserID, l.IP, l.Failures, l.Until); err != nil {
+		return fmt.Errorf("insert lockout error: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) CreateToken(ctx context.Context, t *Token) error {
+	const q = `INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+		VALUES (:user_id, :purpose, :token_hash, :email, :expires_at)`
+	if _, err := r.db.NamedExecContext(ctx, q, t); err != nil {
+		return fmt.Errorf("insert token error: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) VerifyEmail(ctx context.Context, tokenHash string) (int64, error) {
+	// токен гасится и email подтверждается одним запросом; если email сменили
+	// после отправки письма, токен сгорает без подтверждения
+	const q = `
+		WITH t AS (
+			UPDATE user_tokens SET used_at = NOW()
+			WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+			RETURNING user_id, email
+		)
+		UPDATE users u SET email_verified = TRUE
+		FROM t WHERE u.id = t.user_id AND u.email = t.email
+		RETURNING u.id`
+	var id int64
+	if err := r.db.QueryRowxContext(ctx, q, tokenHash, TokenVerifyEmail).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidToken
+		}
+		return 0, fmt.Errorf("verify email error: %w", err)
+	}
+	return id, nil
+}
+
+func (r *repository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*User, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const q = `
+		WITH t AS (
+			UPDATE user_tokens SET used_at = NOW()
+			WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+			RETURNING user_id, email
+		)
+		UPDATE users u SET password_hash = $3
+		FROM t WHERE u.id = t.user_id AND u.email = t.email
+		RETURNING u.id, u.email, u.name`
+	var u User
+	if err := tx.GetContext(ctx, &u, q, tokenHash, TokenResetPassword, passwordHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("reset password error: %w", err)
+	}
+
+	const burn = `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, burn, u.ID, TokenResetPassword); err != nil {
+		return nil, fmt.Errorf("revoke reset tokens error: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -2,47 +2,406 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"laschool.ru/event-booking-service/internal/background"
 	"laschool.ru/event-booking-service/internal/jwtutil"
+	"laschool.ru/event-booking-service/internal/logger"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/session"
 )
 
 type Service interface {
 	Register(ctx context.Context, u *User) (int64, error)
-	Login(ctx context.Context, email, password string) (string, error)
+	Login(ctx context.Context, email, password, clientIP string) (string, error)
+	VerifyEmail(ctx context.Context, token string) error
+	// ForgotPassword отправляет письмо со ссылкой сброса, если email зарегистрирован.
+	// Ничего не возвращает: ответ не должен выдавать, существует ли аккаунт.
+	ForgotPassword(ctx context.Context, email string)
+	ResetPassword(ctx context.Context, token, password string) error
+	Profile(ctx context.Context, id int64) (*User, error)
+	// UpdateProfile меняет имя и email; новый email нужно подтвердить заново
+	UpdateProfile(ctx context.Context, id int64, req UpdateProfileRequest) (*User, error)
+	// ChangePassword проверяет текущий пароль, отзывает все сессии и выдаёт новый JWT
+	ChangePassword(ctx context.Context, id int64, current, next string) (string, error)
+	DeleteAccount(ctx context.Context, id int64, password string) error
+	// EnsureByEmail находит пользователя по email или создаёт аккаунт без известного пароля
+	// (его можно получить через сброс пароля); используется при импорте броней
+	EnsureByEmail(ctx context.Context, email string) (u *User, created bool, err error)
 }
 
-type service struct {
-	repo   Repository
-	secret string
-	ttl    time.Duration
+// Options — зависимости и настройки сервиса пользователей.
+type Options struct {
+	// Guard — защита от перебора паролей; nil отключает её
+	Guard *LoginGuard
+	// Notifier — уведомления о событиях безопасности; nil — только в лог
+	Notifier Notifier
+	// Mailer — отправка писем; nil — письма не отправляются
+	Mailer mailer.Mailer
+	// Sessions — отзыв выданных JWT после сброса пароля; nil — не отзываются
+	Sessions *session.Store
+	// Tasks — фоновые задачи; nil — всё выполняется синхронно
+	Tasks *background.Group
+	// Secret подписывает JWT и токены из писем
+	Secret   string
+	TokenTTL time.Duration
+	// BaseURL — публичный адрес сервиса для ссылок в письмах
+	BaseURL         string
+	VerificationTTL time.Duration
+	// ResetURL — страница сброса пароля для ссылки из письма
+	ResetURL string
+	ResetTTL time.Duration
 }
 
-func NewService(repo Repository, secret string, ttl time.Duration) Service {
+type service struct {
+	repo Repository
+	opts Options
+}
 
-	return &service{repo: repo, secret: secret, ttl: ttl}
+func NewService(repo Repository, opts Options) Service {
+	if opts.Notifier == nil {
+		opts.Notifier = LogNotifier{}
+	}
+	if opts.ResetURL == "" {
+		opts.ResetURL = strings.TrimRight(opts.BaseURL, "/") + "/users/password/reset"
+	}
+	return &service{repo: repo, opts: opts}
 }
 
 func (s *service) Register(ctx context.Context, user *User) (int64, error) {
-	return s.repo.Create(ctx, user)
+	user.Email = normalizeEmail(user.Email)
+	id, err := s.repo.Create(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	user.ID = id
+	logger.FromContext(ctx).Info("user registered", "user_id", id)
+
+	// аккаунт уже создан: письмо можно будет запросить повторно, поэтому ошибку только логируем
+	if err := s.sendVerification(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("send verification email failed", "user_id", id, "error", err)
+	}
+	return id, nil
+}
+
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	hash, ok := parseToken(s.opts.Secret, token)
+	if !ok {
+		return ErrInvalidToken
+	}
+	id, err := s.repo.VerifyEmail(ctx, hash)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("email verified", "user_id", id)
+	return nil
+}
+
+func (s *service) ForgotPassword(ctx context.Context, email string) {
+	// поиск и отправка в фоне: время ответа не зависит от того, есть ли такой email
+	send := func(ctx context.Context) {
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			logger.FromContext(ctx).Error("send password reset email failed", "error", err)
+		}
+	}
+	if s.opts.Tasks == nil {
+		send(ctx)
+		return
+	}
+	s.opts.Tasks.Go(ctx, 10*time.Second, send)
+}
+
+func (s *service) sendPasswordReset(ctx context.Context, email string) error {
+	if s.opts.Mailer == nil {
+		return nil
+	}
+	user, err := s.repo.GetUserByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			logger.FromContext(ctx).Info("password reset requested for unknown email")
+			return nil
+		}
+		return err
+	}
+	token, hash, err := newToken(s.opts.Secret)
+	if err != nil {
+		return fmt.Errorf("generate token: %w", err)
+	}
+	t := &Token{
+		UserID:    user.ID,
+		Purpose:   TokenResetPassword,
+		Hash:      hash,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(s.opts.ResetTTL),
+	}
+	if err := s.repo.CreateToken(ctx, t); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("password reset requested", "user_id", user.ID)
+	return s.opts.Mailer.Send(ctx, passwordResetMessage(user, s.opts.ResetURL, token, s.opts.ResetTTL))
+}
+
+func (s *service) ResetPassword(ctx context.Context, token, password string) error {
+	tokenHash, ok := parseToken(s.opts.Secret, token)
+	if !ok {
+		return ErrInvalidToken
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.ResetPassword(ctx, tokenHash, passwordHash)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("password reset", "user_id", user.ID)
+
+	// пароль уже сменён: ошибки отзыва не откатывают сброс, но должны быть видны
+	if err := s.opts.Sessions.RevokeAll(ctx, user.ID); err != nil {
+		logger.FromContext(ctx).Error("revoke sessions failed", "user_id", user.ID, "error", err)
+	}
+	if s.opts.Guard != nil {
+		if err := s.opts.Guard.Reset(ctx, normalizeEmail(user.Email)); err != nil {
+			logger.FromContext(ctx).Warn("login guard reset failed", "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *service) Profile(ctx context.Context, id int64) (*User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) UpdateProfile(ctx context.Context, id int64, req UpdateProfileRequest) (*User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		user.Name = strings.TrimSpace(*req.Name)
+	}
+	emailChanged := req.Email != nil && normalizeEmail(*req.Email) != normalizeEmail(user.Email)
+	if emailChanged {
+		user.Email = normalizeEmail(*req.Email)
+		user.EmailVerified = false
+	}
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("profile updated", "user_id", id, "email_changed", emailChanged)
+
+	if emailChanged {
+		if err := s.sendVerification(ctx, user); err != nil {
+			logger.FromContext(ctx).Error("send verification email failed", "user_id", id, "error", err)
+		}
+	}
+	return user, nil
 }
 
-func (s *service) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := s.repo.GetUserByEmail(ctx, email)
+func (s *service) ChangePassword(ctx context.Context, id int64, current, next string) (string, error) {
+	user, err := s.checkPassword(ctx, id, current)
+	if err != nil {
+		return "", err
+	}
+	passwordHash, err := hashPassword(next)
 	if err != nil {
 		return "", err
 	}
+	if err := s.repo.UpdatePassword(ctx, id, passwordHash); err != nil {
+		return "", err
+	}
+	logger.FromContext(ctx).Info("password changed", "user_id", id)
 
+	if err := s.opts.Sessions.RevokeAll(ctx, id); err != nil {
+		logger.FromContext(ctx).Error("revoke sessions failed", "user_id", id, "error", err)
+	}
+	token, err := jwtutil.GenerateJWT(id, user.Role, s.opts.Secret, s.opts.TokenTTL)
+	if err != nil {
+		return "", fmt.Errorf("generate token error: %w", err)
+	}
+	return token, nil
+}
+
+func (s *service) DeleteAccount(ctx context.Context, id int64, password string) error {
+	if _, err := s.checkPassword(ctx, id, password); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("account deleted", "user_id", id)
+
+	if err := s.opts.Sessions.RevokeAll(ctx, id); err != nil {
+		logger.FromContext(ctx).Error("revoke sessions failed", "user_id", id, "error", err)
+	}
+	return nil
+}
+
+// checkPassword подтверждает опасное действие текущим паролем пользователя.
+// Ошибки учитываются тем же счётчиком аккаунта, что и при входе: украденный токен
+// не должен давать неограниченный перебор пароля.
+func (s *service) checkPassword(ctx context.Context, id int64, password string) (*User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	key := normalizeEmail(user.Email)
+	if err := s.checkLocked(ctx, key, ""); err != nil {
+		return nil, err
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", fmt.Errorf("invalid password")
+		logger.FromContext(ctx).Warn("password confirmation failed", "user_id", id)
+		return nil, s.fail(ctx, key, "", user)
+	}
+	if s.opts.Guard != nil {
+		if err := s.opts.Guard.Reset(ctx, key); err != nil {
+			logger.FromContext(ctx).Warn("login guard reset failed", "error", err)
+		}
 	}
+	return user, nil
+}
+
+// sendVerification выдаёт токен подтверждения текущего email пользователя и отправляет его письмом.
+func (s *service) sendVerification(ctx context.Context, u *User) error {
+	if s.opts.Mailer == nil {
+		return nil
+	}
+	token, hash, err := newToken(s.opts.Secret)
+	if err != nil {
+		return fmt.Errorf("generate token: %w", err)
+	}
+	t := &Token{
+		UserID:    u.ID,
+		Purpose:   TokenVerifyEmail,
+		Hash:      hash,
+		Email:     u.Email,
+		ExpiresAt: time.Now().Add(s.opts.VerificationTTL),
+	}
+	if err := s.repo.CreateToken(ctx, t); err != nil {
+		return err
+	}
+	return s.opts.Mailer.Send(ctx, verificationMessage(u, s.opts.BaseURL, token))
+}
+
+// dummyHash сравнивается с паролем для несуществующего email, чтобы время ответа
+// не выдавало, зарегистрирован ли адрес.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
 
-	token, err := jwtutil.GenerateJWT(user.ID, s.secret, s.ttl)
+func (s *service) Login(ctx context.Context, email, password, clientIP string) (string, error) {
+	key := normalizeEmail(email)
+	if err := s.checkLocked(ctx, key, clientIP); err != nil {
+		return "", err
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			return "", err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		logger.FromContext(ctx).Warn("login failed: unknown email")
+		return "", s.fail(ctx, key, clientIP, nil)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		logger.FromContext(ctx).Warn("login failed: invalid password", "user_id", user.ID)
+		return "", s.fail(ctx, key, clientIP, user)
+	}
+
+	if s.opts.Guard != nil {
+		if err := s.opts.Guard.Reset(ctx, key); err != nil {
+			logger.FromContext(ctx).Warn("login guard reset failed", "error", err)
+		}
+	}
+
+	token, err := jwtutil.GenerateJWT(user.ID, user.Role, s.opts.Secret, s.opts.TokenTTL)
 	if err != nil {
 		return "", fmt.Errorf("generate token error: %w", err)
 	}
 
 	return token, nil
 }
+
+// checkLocked возвращает LockedError, если вход по email или с IP заблокирован.
+// Недоступность Redis не должна закрывать вход, поэтому ошибка только логируется.
+func (s *service) checkLocked(ctx context.Context, email, clientIP string) error {
+	if s.opts.Guard == nil {
+		return nil
+	}
+	until, err := s.opts.Guard.LockedUntil(ctx, email, clientIP)
+	if err != nil {
+		logger.FromContext(ctx).Warn("login guard check failed", "error", err)
+		return nil
+	}
+	if !until.IsZero() {
+		return &LockedError{Until: until}
+	}
+	return nil
+}
+
+// fail учитывает неудачную попытку. Блокируется и несуществующий email — иначе по
+// разнице в ответах можно было бы отличить зарегистрированные адреса.
+func (s *service) fail(ctx context.Context, email, clientIP string, user *User) error {
+	if s.opts.Guard == nil {
+		return ErrInvalidCredentials
+	}
+	lockouts, err := s.opts.Guard.Fail(ctx, email, clientIP)
+	if err != nil {
+		logger.FromContext(ctx).Warn("login guard update failed", "error", err)
+	}
+	if len(lockouts) == 0 {
+		return ErrInvalidCredentials
+	}
+
+	var until time.Time
+	for _, l := range lockouts {
+		if l.Scope == LockScopeAccount && user != nil {
+			l.UserID = &user.ID
+		}
+		logger.FromContext(ctx).Warn("login locked",
+			"scope", l.Scope, "ip", l.IP, "failures", l.Failures, "locked_until", l.Until)
+		if err := s.repo.RecordLockout(ctx, &l); err != nil {
+			logger.FromContext(ctx).Error("record lockout failed", "error", err)
+		}
+		if l.Scope == LockScopeAccount && user != nil {
+			if err := s.opts.Notifier.AccountLocked(ctx, user, l.Until); err != nil {
+				logger.FromContext(ctx).Error("account locked notification failed", "user_id", user.ID, "error", err)
+			}
+		}
+		if l.Until.After(until) {
+			until = l.Until
+		}
+	}
+	return &LockedError{Until: until}
+}
+
+func (s *service) EnsureByEmail(ctx context.Context, email string) (*User, bool, error) {
+	email = normalizeEmail(email)
+	name, _, ok := strings.Cut(email, "@")
+	if !ok || name == "" {
+		return nil, false, fmt.Errorf("invalid email %q", email)
+	}
+	// случайный пароль никому не известен: войти можно только после сброса пароля
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	u, created, err := s.repo.FindOrCreate(ctx, email, name, hex.EncodeToString(buf))
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		logger.FromContext(ctx).Info("user created by import", "user_id", u.ID)
+	}
+	return u, created, nil
+}
@@ -0,0 +1,429 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"laschool.ru/event-booking-service/internal/config"
+	"laschool.ru/event-booking-service/internal/mailer"
+	"laschool.ru/event-booking-service/internal/session"
+)
+
+type repoStub struct {
+	users    map[string]*User
+	lockouts []Lockout
+	tokens   map[string]*Token
+}
+
+func (r *repoStub) Create(ctx context.Context, u *User) (int64, error) {
+	for email := range r.users {
+		if strings.EqualFold(email, u.Email) {
+			return 0, ErrEmailTaken
+		}
+	}
+	u.ID = int64(len(r.users) + 100)
+	r.users[u.Email] = u
+	return u.ID, nil
+}
+func (r *repoStub) FindOrCreate(ctx context.Context, email, name, password string) (*User, bool, error) {
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, false, nil
+		}
+	}
+	u := &User{Email: email, Name: name, Password: password}
+	_, err := r.Create(ctx, u)
+	return u, err == nil, err
+}
+func (r *repoStub) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	if u, ok := r.users[email]; ok {
+		return u, nil
+	}
+	return nil, ErrUserNotFound
+}
+func (r *repoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+func (r *repoStub) Update(ctx context.Context, u *User) error {
+	for email, other := range r.users {
+		if other.ID == u.ID {
+			delete(r.users, email)
+		} else if email == u.Email {
+			return ErrEmailTaken
+		}
+	}
+	r.users[u.Email] = u
+	return nil
+}
+func (r *repoStub) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.Password = passwordHash
+	return nil
+}
+func (r *repoStub) Delete(ctx context.Context, id int64) error {
+	u, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	delete(r.users, u.Email)
+	return nil
+}
+func (r *repoStub) RecordLockout(ctx context.Context, l *Lockout) error {
+	r.lockouts = append(r.lockouts, *l)
+	return nil
+}
+func (r *repoStub) CreateToken(ctx context.Context, t *Token) error {
+	r.tokens[t.Hash] = t
+	return nil
+}
+func (r *repoStub) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (*User, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || t.Purpose != TokenResetPassword || time.Now().After(t.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	for hash, other := range r.tokens {
+		if other.UserID == t.UserID && other.Purpose == TokenResetPassword {
+			delete(r.tokens, hash)
+		}
+	}
+	u := r.users[t.Email]
+	u.Password = passwordHash
+	return u, nil
+}
+func (r *repoStub) VerifyEmail(ctx context.Context, tokenHash string) (int64, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok || time.Now().After(t.ExpiresAt) {
+		return 0, ErrInvalidToken
+	}
+	delete(r.tokens, tokenHash)
+	r.users[t.Email].EmailVerified = true
+	return t.UserID, nil
+}
+
+type notifierStub struct{ locked []int64 }
+
+func (n *notifierStub) AccountLocked(ctx context.Context, u *User, until time.Time) error {
+	n.locked = append(n.locked, u.ID)
+	return nil
+}
+
+func newTestService(t *testing.T, cfg config.LoginProtection) (Service, *repoStub, *notifierStub) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &repoStub{
+		users: map[string]*User{
+			"alice@example.com": {ID: 7, Email: "alice@example.com", Password: string(hash)},
+		},
+		tokens: map[string]*Token{},
+	}
+	notifier := &notifierStub{}
+	svc := NewService(repo, Options{
+		Guard:           NewLoginGuard(rdb, cfg),
+		Notifier:        notifier,
+		Mailer:          mailer.NewMemoryMailer("noreply@example.com"),
+		Secret:          "secret",
+		TokenTTL:        time.Hour,
+		BaseURL:         "http://localhost:8080",
+		VerificationTTL: time.Hour,
+	})
+	return svc, repo, notifier
+}
+
+func TestLogin_UniformError(t *testing.T) {
+	svc, _, _ := newTestService(t, config.LoginProtection{MaxAccountFailures: 5, MaxIPFailures: 20})
+	ctx := context.Background()
+
+	_, errUnknown := svc.Login(ctx, "bob@example.com", "secret", "10.0.0.1")
+	_, errWrong := svc.Login(ctx, "alice@example.com", "wrong", "10.0.0.1")
+	if !errors.Is(errUnknown, ErrInvalidCredentials) || !errors.Is(errWrong, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v and %v", errUnknown, errWrong)
+	}
+	if errUnknown.Error() != errWrong.Error() {
+		t.Fatalf("errors differ: %q vs %q", errUnknown, errWrong)
+	}
+}
+
+func TestLogin_AccountLockout(t *testing.T) {
+	svc, repo, notifier := newTestService(t, config.LoginProtection{
+		MaxAccountFailures: 3, MaxIPFailures: 100, BaseLockout: time.Minute, MaxLockout: time.Hour,
+	})
+	ctx := context.Background()
+
+	// регистр email не влияет на счётчик
+	for _, email := range []string{"alice@example.com", "Alice@Example.com"} {
+		if _, err := svc.Login(ctx, email, "wrong", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("%s: unexpected error %v", email, err)
+		}
+	}
+	var locked *LockedError
+	if _, err := svc.Login(ctx, "alice@example.com", "wrong", "10.0.0.2"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	// правильный пароль во время блокировки не помогает
+	if _, err := svc.Login(ctx, "alice@example.com", "secret", "10.0.0.3"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+
+	if len(repo.lockouts) != 1 || repo.lockouts[0].Scope != LockScopeAccount || repo.lockouts[0].UserID == nil {
+		t.Fatalf("unexpected audit records %+v", repo.lockouts)
+	}
+	if len(notifier.locked) != 1 || notifier.locked[0] != 7 {
+		t.Fatalf("expected notification for user 7, got %v", notifier.locked)
+	}
+}
+
+func TestLogin_IPLockout(t *testing.T) {
+	svc, repo, notifier := newTestService(t, config.LoginProtection{MaxAccountFailures: 100, MaxIPFailures: 2})
+	ctx := context.Background()
+
+	svc.Login(ctx, "a@example.com", "x", "10.0.0.1")
+	var locked *LockedError
+	if _, err := svc.Login(ctx, "b@example.com", "x", "10.0.0.1"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	// другой IP не заблокирован
+	if _, err := svc.Login(ctx, "alice@example.com", "secret", "10.0.0.2"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(repo.lockouts) != 1 || repo.lockouts[0].Scope != LockScopeIP {
+		t.Fatalf("unexpected audit records %+v", repo.lockouts)
+	}
+	if len(notifier.locked) != 0 {
+		t.Fatalf("ip lockout must not notify, got %v", notifier.locked)
+	}
+}
+
+func TestRegister_VerifyEmail(t *testing.T) {
+	repo := &repoStub{users: map[string]*User{}, tokens: map[string]*Token{}}
+	mail := mailer.NewMemoryMailer("noreply@example.com")
+	svc := NewService(repo, Options{Mailer: mail, Secret: "secret", BaseURL: "http://localhost:8080/", VerificationTTL: time.Hour})
+	ctx := context.Background()
+
+	// email хранится в нижнем регистре, чтобы вход и сброс пароля находили его в любом написании
+	if _, err := svc.Register(ctx, &User{Email: "Bob@Example.com", Name: "Bob", Password: "pw"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	msgs := mail.Messages()
+	if len(msgs) != 1 || msgs[0].To != "bob@example.com" {
+		t.Fatalf("expected verification email, got %+v", msgs)
+	}
+	_, link, _ := strings.Cut(msgs[0].Body, "http://localhost:8080/users/verify?token=")
+	token, _, _ := strings.Cut(link, "\n")
+	token, _ = url.QueryUnescape(token)
+
+	// подделанная подпись отсекается
+	if err := svc.VerifyEmail(ctx, token+"x"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for forged token, got %v", err)
+	}
+	if err := svc.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !repo.users["bob@example.com"].EmailVerified {
+		t.Fatal("email should be verified")
+	}
+	// токен одноразовый
+	if err := svc.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken on reuse, got %v", err)
+	}
+	if _, err := svc.Register(ctx, &User{Email: "BOB@example.com", Name: "Bob", Password: "pw"}); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken for the same email in another case, got %v", err)
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old"), bcrypt.MinCost)
+	repo := &repoStub{
+		users:  map[string]*User{"alice@example.com": {ID: 7, Email: "alice@example.com", Password: string(hash)}},
+		tokens: map[string]*Token{},
+	}
+	mail := mailer.NewMemoryMailer("noreply@example.com")
+	sessions := session.New(rdb, time.Hour)
+	svc := NewService(repo, Options{
+		Mailer: mail, Sessions: sessions, Secret: "secret", TokenTTL: time.Hour,
+		BaseURL: "http://localhost:8080", ResetTTL: 30 * time.Minute,
+	})
+	ctx := context.Background()
+
+	// неизвестный email — письма нет, ошибки тоже
+	svc.ForgotPassword(ctx, "nobody@example.com")
+	if len(mail.Messages()) != 0 {
+		t.Fatal("no email expected for unknown address")
+	}
+
+	svc.ForgotPassword(ctx, " Alice@Example.COM ")
+	msgs := mail.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected reset email, got %d", len(msgs))
+	}
+	_, link, _ := strings.Cut(msgs[0].Body, "http://localhost:8080/users/password/reset?token=")
+	token, _, _ := strings.Cut(link, "\n")
+	token, _ = url.QueryUnescape(token)
+
+	issuedAt := time.Now().Add(-time.Minute)
+	if err := svc.ResetPassword(ctx, token, "new"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(repo.users["alice@example.com"].Password), []byte("new")) != nil {
+		t.Fatal("password was not changed")
+	}
+	if _, err := svc.Login(ctx, "ALICE@example.com", "new", "10.0.0.1"); err != nil {
+		t.Fatalf("login with different email case: %v", err)
+	}
+	if revoked, _ := sessions.Revoked(ctx, 7, issuedAt); !revoked {
+		t.Fatal("existing sessions must be revoked")
+	}
+	if err := svc.ResetPassword(ctx, token, "again"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken on reuse, got %v", err)
+	}
+}
+
+func TestUpdateProfile_EmailChangeRequiresVerification(t *testing.T) {
+	repo := &repoStub{
+		users:  map[string]*User{"alice@example.com": {ID: 7, Email: "alice@example.com", Name: "Alice", EmailVerified: true}},
+		tokens: map[string]*Token{},
+	}
+	mail := mailer.NewMemoryMailer("noreply@example.com")
+	svc := NewService(repo, Options{Mailer: mail, Secret: "secret", VerificationTTL: time.Hour})
+	ctx := context.Background()
+
+	name := "  Alice Smith "
+	u, err := svc.UpdateProfile(ctx, 7, UpdateProfileRequest{Name: &name})
+	if err != nil {
+		t.Fatalf("update name: %v", err)
+	}
+	if u.Name != "Alice Smith" || !u.EmailVerified || len(mail.Messages()) != 0 {
+		t.Fatalf("name change must not touch email: %+v", u)
+	}
+
+	email := "alice@new.example.com"
+	if u, err = svc.UpdateProfile(ctx, 7, UpdateProfileRequest{Email: &email}); err != nil {
+		t.Fatalf("update email: %v", err)
+	}
+	if u.Email != email || u.EmailVerified {
+		t.Fatalf("new email must be unverified: %+v", u)
+	}
+	if msgs := mail.Messages(); len(msgs) != 1 || msgs[0].To != email {
+		t.Fatalf("expected verification email to new address, got %+v", msgs)
+	}
+}
+
+func TestChangePasswordAndDeleteAccount(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old"), bcrypt.MinCost)
+	repo := &repoStub{
+		users:  map[string]*User{"alice@example.com": {ID: 7, Email: "alice@example.com", Password: string(hash)}},
+		tokens: map[string]*Token{},
+	}
+	sessions := session.New(rdb, time.Hour)
+	svc := NewService(repo, Options{Sessions: sessions, Secret: "secret", TokenTTL: time.Hour})
+	ctx := context.Background()
+
+	if _, err := svc.ChangePassword(ctx, 7, "wrong", "new"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	issuedAt := time.Now().Add(-time.Second)
+	token, err := svc.ChangePassword(ctx, 7, "old", "new")
+	if err != nil || token == "" {
+		t.Fatalf("change password: %q, %v", token, err)
+	}
+	if revoked, _ := sessions.Revoked(ctx, 7, issuedAt); !revoked {
+		t.Fatal("old sessions must be revoked")
+	}
+	if revoked, _ := sessions.Revoked(ctx, 7, time.Now()); revoked {
+		t.Fatal("new token must stay valid")
+	}
+
+	if err := svc.DeleteAccount(ctx, 7, "old"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := svc.DeleteAccount(ctx, 7, "new"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.Profile(ctx, 7); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound after delete, got %v", err)
+	}
+}
+
+func TestChangePassword_Lockout(t *testing.T) {
+	svc, repo, notifier := newTestService(t, config.LoginProtection{
+		MaxAccountFailures: 2, MaxIPFailures: 100, BaseLockout: time.Minute, MaxLockout: time.Hour,
+	})
+	ctx := context.Background()
+
+	if _, err := svc.ChangePassword(ctx, 7, "wrong", "new"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	var locked *LockedError
+	if err := svc.DeleteAccount(ctx, 7, "wrong"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	// верный пароль во время блокировки не принимается ни здесь, ни при входе
+	if _, err := svc.ChangePassword(ctx, 7, "secret", "new"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	if _, err := svc.Login(ctx, "alice@example.com", "secret", "10.0.0.1"); !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError on login, got %v", err)
+	}
+	if len(repo.lockouts) != 1 || repo.lockouts[0].UserID == nil || *repo.lockouts[0].UserID != 7 {
+		t.Fatalf("unexpected audit records %+v", repo.lockouts)
+	}
+	if len(notifier.locked) != 1 {
+		t.Fatalf("expected lock notification, got %v", notifier.locked)
+	}
+}
+
+func TestLoginGuard_ExponentialLockout(t *testing.T) {
+	g := NewLoginGuard(nil, config.LoginProtection{BaseLockout: time.Minute, MaxLockout: 10 * time.Minute})
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, w := range want {
+		if got := g.lockoutDuration(i + 1); got != w {
+			t.Fatalf("lockout %d: expected %v, got %v", i+1, w, got)
+		}
+	}
+}
+
+func TestService_EnsureByEmail(t *testing.T) {
+	repo := &repoStub{users: map[string]*User{"Alice@example.com": {ID: 7, Email: "Alice@example.com"}}, tokens: map[string]*Token{}}
+	svc := NewService(repo, Options{Secret: "secret"})
+	ctx := context.Background()
+
+	u, created, err := svc.EnsureByEmail(ctx, " alice@EXAMPLE.com ")
+	if err != nil || created || u.ID != 7 {
+		t.Fatalf("expected existing user 7, got %+v created=%v err=%v", u, created, err)
+	}
+	u, created, err = svc.EnsureByEmail(ctx, "Bob@Example.com")
+	if err != nil || !created || u.Email != "bob@example.com" || u.Name != "bob" {
+		t.Fatalf("expected new user bob, got %+v created=%v err=%v", u, created, err)
+	}
+	if _, _, err := svc.EnsureByEmail(ctx, "not-an-email"); err == nil {
+		t.Fatal("expected error for invalid email")
+	}
+}
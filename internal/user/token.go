@@ -0,0 +1,60 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Назначения одноразовых токенов
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+// Token — одноразовый токен из письма. В базе хранится только хэш.
+type Token struct {
+	UserID    int64     `db:"user_id"`
+	Purpose   string    `db:"purpose"`
+	Hash      string    `db:"token_hash"`
+	Email     string    `db:"email"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// newToken генерирует токен для письма и хэш для хранения.
+// Токен подписан секретом, чтобы подделки отсекались без обращения к базе.
+func newToken(secret string) (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	return raw + "." + signToken(secret, raw), hashToken(raw), nil
+}
+
+// parseToken проверяет подпись и возвращает хэш для поиска в базе.
+func parseToken(secret, token string) (string, bool) {
+	raw, sig, ok := strings.Cut(token, ".")
+	if !ok || raw == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(signToken(secret, raw))) {
+		return "", false
+	}
+	return hashToken(raw), true
+}
+
+func signToken(secret, raw string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(raw))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}